@@ -0,0 +1,225 @@
+// Package client is a Go client of the news api, serving as its SDK for the
+// services consuming it.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the routes of the first version of the api. It is safe for
+// concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      func(context.Context) (string, error)
+	timeout    time.Duration
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures the client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with hc instead of a default client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithToken authenticates the requests with a static bearer token, such as
+// an api key
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// WithTokenSource authenticates each request with the bearer token returned
+// by source, such as a JWT refreshed before it expires
+func WithTokenSource(source func(context.Context) (string, error)) Option {
+	return func(c *Client) {
+		c.token = source
+	}
+}
+
+// WithTimeout bounds each attempt of a request, 30s by default; 0 leaves
+// them bound by the context only
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries retries a failed request up to retries times, 2 by default,
+// waiting backoff and doubling it after each attempt up to maxBackoff, unless
+// the server answered with Retry-After
+func WithRetries(retries int, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New returns a client of the api served at baseURL, such as
+// https://news.example.com
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse base url: %w", err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("base url %q must be absolute", baseURL)
+	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(u.String(), "/") + "/v1",
+		httpClient: &http.Client{},
+		timeout:    30 * time.Second,
+		retries:    2,
+		backoff:    200 * time.Millisecond,
+		maxBackoff: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request is a call to the api
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        any
+	contentType string
+}
+
+// do sends the request, retrying it when it failed before being processed
+// or when it is idempotent, and decodes the response body into out
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		if req.contentType == "" {
+			req.contentType = "application/json"
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		status, header, body, err := c.send(ctx, req, payload)
+		if err == nil && status < http.StatusMultipleChoices {
+			if out == nil || len(body) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("decode response: %w", err)
+			}
+			return nil
+		}
+		if err != nil && ctx.Err() != nil {
+			return err
+		}
+		if err == nil {
+			err = newError(status, header, body)
+		}
+		if attempt >= c.retries || !retryable(req.method, status) {
+			return err
+		}
+		delay := c.backoff << attempt
+		if delay <= 0 || delay > c.maxBackoff {
+			delay = c.maxBackoff
+		}
+		if after, ok := retryAfter(header); ok {
+			delay = after
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send makes one attempt of the request, returning the status, headers and
+// body of the response, or the error preventing it
+func (c *Client) send(ctx context.Context, req request, payload []byte) (int, http.Header, []byte, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	target := c.baseURL + req.path
+	if len(req.query) != 0 {
+		target += "?" + req.query.Encode()
+	}
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	r, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	r.Header.Set("Accept", "application/json")
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+	if c.token != nil {
+		token, err := c.token(ctx)
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("get token: %w", err)
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("read response: %w", err)
+	}
+	return resp.StatusCode, resp.Header, b, nil
+}
+
+// retryable reports whether a request may be sent again after failing with
+// status, 0 when no response was received. Rate limited and unavailable
+// requests were not processed; others are only retried when idempotent.
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case 0, http.StatusBadGateway, http.StatusGatewayTimeout:
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter returns the delay of a Retry-After header given in seconds
+func retryAfter(header http.Header) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
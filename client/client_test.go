@@ -0,0 +1,349 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/client"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/logeshwarann-dev/news-api-rest/internal/router"
+	"github.com/logeshwarann-dev/news-api-rest/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokens are the api keys the test server accepts
+var tokens = map[string][]auth.Scope{
+	"admin-token":  {auth.ScopeAdmin},
+	"reader-token": {auth.ScopeNewsRead},
+}
+
+// newServer serves the api over an empty memory store, wrapping it with
+// wrap when given, and returns its url
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) string {
+	t.Helper()
+	authenticator := auth.AuthenticatorFunc(func(_ context.Context, token string) (auth.Principal, error) {
+		scopes, ok := tokens[token]
+		if !ok {
+			return auth.Principal{}, auth.ErrInvalidCredentials
+		}
+		return auth.Principal{Subject: token, Scopes: scopes}, nil
+	})
+	var h http.Handler = middleware.Authenticate(authenticator, router.New(store.New(), router.WithScopes()))
+	if wrap != nil {
+		h = wrap(h)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newClient(t *testing.T, url string, opts ...client.Option) *client.Client {
+	t.Helper()
+	c, err := client.New(url, append([]client.Option{client.WithToken("admin-token")}, opts...)...)
+	require.NoError(t, err)
+	return c
+}
+
+func input(title string, createdAt time.Time, tags ...string) client.NewsInput {
+	return client.NewsInput{
+		Author:    "Batman",
+		Title:     title,
+		Summary:   "A night in Gotham",
+		Content:   "The Joker escaped from Arkham",
+		Source:    "https://gotham.example.com",
+		CreatedAt: createdAt,
+		Tags:      tags,
+	}
+}
+
+var epoch = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+func Test_ClientNews(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, newServer(t, nil))
+
+	created, err := c.Create(ctx, input("Gotham", epoch, "dc"))
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	assert.Equal(t, "Gotham", created.Title)
+	assert.Equal(t, "admin-token", created.Owner)
+	assert.Equal(t, "admin-token", created.Editor)
+
+	got, err := c.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, got.ID)
+	assert.True(t, epoch.Equal(got.CreatedAt))
+
+	updated := input("Metropolis", epoch, "dc", "superman")
+	require.NoError(t, c.Update(ctx, created.ID, updated))
+	got, err = c.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Metropolis", got.Title)
+	assert.Equal(t, []string{"dc", "superman"}, got.Tags)
+
+	title := "Smallville"
+	require.NoError(t, c.Patch(ctx, created.ID, client.NewsPatch{Title: &title}))
+	got, err = c.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Smallville", got.Title)
+	assert.Equal(t, "A night in Gotham", got.Summary, "fields left out of the patch are kept")
+	assert.Equal(t, []string{"dc", "superman"}, got.Tags)
+
+	require.NoError(t, c.Delete(ctx, created.ID))
+	_, err = c.Get(ctx, created.ID)
+	assert.ErrorIs(t, err, client.ErrNotFound)
+}
+
+func Test_ClientList(t *testing.T) {
+	ctx := context.Background()
+	var requests atomic.Int32
+	c := newClient(t, newServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}))
+	for i := range 5 {
+		tag := "even"
+		if i%2 == 1 {
+			tag = "odd"
+		}
+		_, err := c.Create(ctx, input(fmt.Sprintf("news-%d", i), epoch.Add(time.Duration(i)*time.Hour), tag))
+		require.NoError(t, err)
+	}
+	titles := func(tb testing.TB, seq func(func(client.News, error) bool)) (titles []string) {
+		tb.Helper()
+		for n, err := range seq {
+			require.NoError(tb, err)
+			titles = append(titles, n.Title)
+		}
+		return titles
+	}
+
+	t.Run("list_single_page", func(t *testing.T) {
+		list, err := c.List(ctx, client.ListOptions{Tag: "even", Sort: client.Newest, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, "news-4", list[0].Title)
+		assert.Equal(t, "news-2", list[1].Title)
+	})
+
+	t.Run("iterate_every_page", func(t *testing.T) {
+		requests.Store(0)
+		got := titles(t, c.All(ctx, client.ListOptions{Limit: 2}))
+		assert.Equal(t, []string{"news-0", "news-1", "news-2", "news-3", "news-4"}, got)
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("stop_iterating_early", func(t *testing.T) {
+		requests.Store(0)
+		for n, err := range c.All(ctx, client.ListOptions{Limit: 2}) {
+			require.NoError(t, err)
+			if n.Title == "news-1" {
+				break
+			}
+		}
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("search", func(t *testing.T) {
+		_, err := c.Create(ctx, input("The JOKER strikes back", epoch.Add(time.Minute), "odd"))
+		require.NoError(t, err)
+		got := titles(t, c.Search(ctx, "joker strikes", client.ListOptions{Tag: "odd"}))
+		assert.Equal(t, []string{"The JOKER strikes back"}, got)
+	})
+
+	t.Run("end_sequence_on_error", func(t *testing.T) {
+		var errs []error
+		for _, err := range c.All(ctx, client.ListOptions{Sort: "random"}) {
+			errs = append(errs, err)
+		}
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], client.ErrBadRequest)
+	})
+}
+
+func Test_ClientErrors(t *testing.T) {
+	url := newServer(t, nil)
+	testcases := []struct {
+		name           string
+		opts           []client.Option
+		call           func(c *client.Client) error
+		expectedErr    error
+		expectedDetail string
+	}{
+		{
+			name: "invalid_news",
+			call: func(c *client.Client) error {
+				_, err := c.Create(context.Background(), input("", epoch))
+				return err
+			},
+			expectedErr:    client.ErrBadRequest,
+			expectedDetail: "title is empty",
+		},
+		{
+			name:        "missing_token",
+			opts:        []client.Option{client.WithTokenSource(nil)},
+			call:        func(c *client.Client) error { return c.Delete(context.Background(), uuid.New()) },
+			expectedErr: client.ErrUnauthorized,
+		},
+		{
+			name:        "invalid_token",
+			opts:        []client.Option{client.WithToken("joker-token")},
+			call:        func(c *client.Client) error { return c.Delete(context.Background(), uuid.New()) },
+			expectedErr: client.ErrUnauthorized,
+		},
+		{
+			name:        "missing_scope",
+			opts:        []client.Option{client.WithToken("reader-token")},
+			call:        func(c *client.Client) error { return c.Delete(context.Background(), uuid.New()) },
+			expectedErr: client.ErrForbidden,
+		},
+		{
+			name:        "patch_unknown_news",
+			call:        func(c *client.Client) error { return c.Patch(context.Background(), uuid.New(), client.NewsPatch{}) },
+			expectedErr: client.ErrNotFound,
+		},
+		{
+			name: "token_source_failure",
+			opts: []client.Option{client.WithTokenSource(func(context.Context) (string, error) {
+				return "", errors.New("vault sealed")
+			}), client.WithRetries(0, 0, 0)},
+			call:           func(c *client.Client) error { return c.Delete(context.Background(), uuid.New()) },
+			expectedDetail: "vault sealed",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call(newClient(t, url, tc.opts...))
+
+			require.Error(t, err)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				var apiErr *client.Error
+				require.ErrorAs(t, err, &apiErr)
+			}
+			assert.Contains(t, err.Error(), tc.expectedDetail)
+		})
+	}
+}
+
+func Test_ClientDecodesProblems(t *testing.T) {
+	c := newClient(t, newServer(t, nil))
+
+	_, err := c.List(context.Background(), client.ListOptions{Limit: 5000})
+
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	assert.Equal(t, "Bad Request", apiErr.Title)
+	assert.Contains(t, apiErr.Detail, "limit must be between 1 and 1000")
+}
+
+func Test_ClientRetries(t *testing.T) {
+	testcases := []struct {
+		name             string
+		method           string
+		failures         int32
+		failStatus       int
+		retries          int
+		expectedRequests int32
+	}{
+		{
+			name:             "retry_rate_limited_write",
+			method:           http.MethodPost,
+			failures:         2,
+			failStatus:       http.StatusTooManyRequests,
+			retries:          2,
+			expectedRequests: 3,
+		},
+		{
+			name:             "give_up_after_retries",
+			method:           http.MethodGet,
+			failures:         5,
+			failStatus:       http.StatusServiceUnavailable,
+			retries:          1,
+			expectedRequests: 2,
+		},
+		{
+			name:             "retry_idempotent_read_on_bad_gateway",
+			method:           http.MethodGet,
+			failures:         1,
+			failStatus:       http.StatusBadGateway,
+			retries:          2,
+			expectedRequests: 2,
+		},
+		{
+			name:             "keep_write_on_bad_gateway",
+			method:           http.MethodPost,
+			failures:         1,
+			failStatus:       http.StatusBadGateway,
+			retries:          2,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int32
+			url := newServer(t, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if requests.Add(1) <= tc.failures {
+						w.Header().Set("Retry-After", "0")
+						w.WriteHeader(tc.failStatus)
+						return
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+			c := newClient(t, url, client.WithRetries(tc.retries, time.Millisecond, 10*time.Millisecond))
+
+			var err error
+			if tc.method == http.MethodPost {
+				_, err = c.Create(context.Background(), input("Gotham", epoch, "dc"))
+			} else {
+				_, err = c.List(context.Background(), client.ListOptions{})
+			}
+
+			assert.Equal(t, tc.expectedRequests, requests.Load())
+			if tc.expectedRequests <= tc.failures {
+				var apiErr *client.Error
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tc.failStatus, apiErr.Status)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_ClientTimeout(t *testing.T) {
+	url := newServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		})
+	})
+	c := newClient(t, url, client.WithTimeout(10*time.Millisecond), client.WithRetries(0, 0, 0))
+
+	_, err := c.List(context.Background(), client.ListOptions{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_New(t *testing.T) {
+	_, err := client.New("news.example.com")
+	assert.ErrorContains(t, err, "must be absolute")
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Errors matched by errors.Is against the *Error of a response
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// Error is an error response of the api, decoded from its problem details
+// (RFC 9457) when it has some
+type Error struct {
+	Status   int          `json:"status"`
+	Type     string       `json:"type,omitempty"`
+	Title    string       `json:"title"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError points at an invalid part of a rejected request: a parameter
+// by its name, or a body member by its JSON path such as $.tags[0]
+type FieldError struct {
+	In     string `json:"in"`
+	Path   string `json:"path"`
+	Detail string `json:"detail"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("news api: %d %s", e.Status, e.Title)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	for _, f := range e.Errors {
+		msg += fmt.Sprintf("; %s %s %s", f.In, f.Path, f.Detail)
+	}
+	return msg
+}
+
+// Is matches the sentinel error of the status
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.Status == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.Status == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Status == http.StatusForbidden
+	case ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case ErrRateLimited:
+		return e.Status == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// newError decodes an error response, whose body is either problem details
+// or a plain text detail
+func newError(status int, header http.Header, body []byte) *Error {
+	e := &Error{Status: status, Title: http.StatusText(status)}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType == "application/problem+json" {
+		var p Error
+		if err := json.Unmarshal(body, &p); err == nil {
+			p.Status = status
+			if p.Title == "" {
+				p.Title = e.Title
+			}
+			return &p
+		}
+	}
+	e.Detail = strings.TrimSpace(string(body))
+	return e
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// News is a news as served by the api
+type News struct {
+	ID      uuid.UUID `json:"id"`
+	Author  string    `json:"author"`
+	Title   string    `json:"title"`
+	Summary string    `json:"summary"`
+	Content string    `json:"content"`
+	Source  string    `json:"source"`
+	Tags    []string  `json:"tags"`
+	// Owner is the subject that created the news
+	Owner string `json:"owner,omitempty"`
+	// Editor is the subject that last wrote the news
+	Editor    string    `json:"editor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewsInput is a news as written by Create and Update, every field is required
+type NewsInput struct {
+	Author    string    `json:"author"`
+	Title     string    `json:"title"`
+	Summary   string    `json:"summary"`
+	Content   string    `json:"content"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	Tags      []string  `json:"tags"`
+}
+
+// NewsPatch lists the fields changed by Patch, those left nil are kept
+type NewsPatch struct {
+	Author    *string    `json:"author,omitempty"`
+	Title     *string    `json:"title,omitempty"`
+	Summary   *string    `json:"summary,omitempty"`
+	Content   *string    `json:"content,omitempty"`
+	Source    *string    `json:"source,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+}
+
+// Sort orders listed news by creation time
+type Sort string
+
+const (
+	Oldest Sort = "oldest"
+	Newest Sort = "newest"
+)
+
+// ListOptions narrows and pages the listed news, zero values match everything
+type ListOptions struct {
+	Tag    string
+	Author string
+	// Query matches the news whose title, summary or content contain it, ignoring case
+	Query string
+	Sort  Sort
+	// Limit caps the number of news of List, all of them by default, and
+	// sets the page size of All, 100 by default
+	Limit  int
+	Offset int
+}
+
+func (o ListOptions) values() url.Values {
+	v := url.Values{}
+	for name, value := range map[string]string{"tag": o.Tag, "author": o.Author, "q": o.Query, "sort": string(o.Sort)} {
+		if value != "" {
+			v.Set(name, value)
+		}
+	}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		v.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return v
+}
+
+// Create adds a news and returns it as stored
+func (c *Client) Create(ctx context.Context, in NewsInput) (News, error) {
+	var created News
+	err := c.do(ctx, request{method: http.MethodPost, path: "/news", body: in}, &created)
+	return created, err
+}
+
+// Get returns a news by id
+func (c *Client) Get(ctx context.Context, id uuid.UUID) (News, error) {
+	var found News
+	err := c.do(ctx, request{method: http.MethodGet, path: "/news/" + id.String()}, &found)
+	return found, err
+}
+
+// Update replaces every field of a news
+func (c *Client) Update(ctx context.Context, id uuid.UUID, in NewsInput) error {
+	return c.do(ctx, request{method: http.MethodPut, path: "/news/" + id.String(), body: in}, nil)
+}
+
+// Patch changes the fields of a news set in patch
+func (c *Client) Patch(ctx context.Context, id uuid.UUID, patch NewsPatch) error {
+	return c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        "/news/" + id.String(),
+		body:        patch,
+		contentType: "application/merge-patch+json",
+	}, nil)
+}
+
+// Delete deletes a news by id
+func (c *Client) Delete(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: "/news/" + id.String()}, nil)
+}
+
+// List returns the news matching the options in a single request
+func (c *Client) List(ctx context.Context, opts ListOptions) ([]News, error) {
+	var list struct {
+		News []News `json:"news"`
+	}
+	err := c.do(ctx, request{method: http.MethodGet, path: "/news", query: opts.values()}, &list)
+	return list.News, err
+}
+
+// All yields the news matching the options, fetching them a page of
+// opts.Limit at a time from opts.Offset on, until a page comes back short or
+// the caller stops. An error ends the sequence.
+func (c *Client) All(ctx context.Context, opts ListOptions) iter.Seq2[News, error] {
+	if opts.Limit <= 0 {
+		opts.Limit = 100
+	}
+	return func(yield func(News, error) bool) {
+		for {
+			page, err := c.List(ctx, opts)
+			if err != nil {
+				yield(News{}, err)
+				return
+			}
+			for _, n := range page {
+				if !yield(n, nil) {
+					return
+				}
+			}
+			if len(page) < opts.Limit {
+				return
+			}
+			opts.Offset += len(page)
+		}
+	}
+}
+
+// Search yields the news whose title, summary or content contain query,
+// ignoring case, narrowed and ordered by the other options
+func (c *Client) Search(ctx context.Context, query string, opts ListOptions) iter.Seq2[News, error] {
+	opts.Query = query
+	return c.All(ctx, opts)
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -10,41 +14,219 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/logeshwarann-dev/news-api-rest/internal/apikey"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/cache"
+	"github.com/logeshwarann-dev/news-api-rest/internal/config"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
 	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
 	"github.com/logeshwarann-dev/news-api-rest/internal/news"
-	"github.com/logeshwarann-dev/news-api-rest/internal/postgres"
+	"github.com/logeshwarann-dev/news-api-rest/internal/oidc"
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/logeshwarann-dev/news-api-rest/internal/policy"
+	"github.com/logeshwarann-dev/news-api-rest/internal/ratelimit"
 	"github.com/logeshwarann-dev/news-api-rest/internal/router"
+	"github.com/logeshwarann-dev/news-api-rest/internal/store"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
+	"github.com/uptrace/bun"
 	"golang.org/x/sync/errgroup"
 )
 
 func main() {
+	cfg, _, err := config.Load("api-server", os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	level, _ := cfg.Log.SlogLevel()
 
-	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true}))
-	dbConn, err := postgres.NewDB(&postgres.Config{
-		DbHost:   os.Getenv("DATABASE_HOST"),
-		DbPort:   os.Getenv("DATABASE_PORT"),
-		DbName:   os.Getenv("DATABASE_NAME"),
-		UserName: os.Getenv("DATABASE_USER"),
-		Password: os.Getenv("DATABASE_PASSWORD"),
-		SSLMode:  "disable",
-	})
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: true, Level: level}))
+	var (
+		dbConn  *bun.DB
+		cluster *db.Cluster
+		ns      handler.NewsStorer
+	)
+	switch cfg.Store {
+	case "memory":
+		log.Warn("news are kept in memory and lost on exit")
+		ns = store.New()
+	default:
+		cluster, err = db.OpenCluster(cfg.DB())
+		if err != nil {
+			panic(fmt.Errorf("db connection failed: %v", err))
+		}
+		dbConn = cluster.Primary()
+		ns = news.NewStore(dbConn, news.WithReader(cluster))
+	}
+	// on postgres the changes made through any api-server are notified to all
+	// of them, the others only see their own
+	var (
+		bus        *events.Bus
+		listenOpts []events.Option
+	)
+	if cfg.Stream.Enabled {
+		bus = events.NewBus(cfg.Stream.Replay)
+		if cfg.Store == "postgres" {
+			listenOpts = append(listenOpts, events.WithBus(bus))
+		} else {
+			ns = events.NewStore(ns, bus)
+		}
+	}
+	if cfg.Cache.Enabled {
+		cacheOpts := []cache.Option{cache.WithSize(cfg.Cache.Size), cache.WithTTL(cfg.Cache.TTL)}
+		if len(cfg.Database.Replicas) != 0 {
+			// an invalidation may reach this api-server before the change reaches its replicas
+			cacheOpts = append(cacheOpts, cache.WithPrimaryWindow(cfg.Database.ReadYourWrites))
+		}
+		cached := cache.NewStore(ns, cacheOpts...)
+		expvar.Publish("news_cache", expvar.Func(func() any { return cached.Metrics() }))
+		ns = cached
+		listenOpts = append(listenOpts, events.WithInvalidate(cached.Invalidate))
+	}
 
-	if err != nil {
-		panic(fmt.Errorf("db connection failed: %v", err))
+	var authenticators []auth.Authenticator
+	for _, method := range cfg.Auth.Methods() {
+		switch method {
+		case "apikey":
+			authenticators = append(authenticators, apikey.NewStore(dbConn))
+		case "jwt":
+			jwtCfg := cfg.Auth.JWT
+			verifier, err := oidc.New(context.Background(), jwtCfg.JWKS, oidc.Config{
+				Issuer:     jwtCfg.Issuer,
+				Audience:   jwtCfg.Audience,
+				RolesClaim: jwtCfg.RolesClaim,
+				Leeway:     jwtCfg.Leeway,
+			})
+			if err != nil {
+				panic(fmt.Errorf("jwt verifier setup failed: %v", err))
+			}
+			authenticators = append(authenticators, verifier)
+		}
 	}
-	ns := news.NewStore(dbConn)
-	r := router.New(ns)
-	wrappedRouter := middleware.AddLogger(log, middleware.LogRequest(r))
-	log.Info("server running on port 8080")
+
+	var routerOpts []router.Option
+	if len(authenticators) != 0 {
+		routerOpts = append(routerOpts, router.WithScopes(), router.WithPolicy(policy.New(policy.DefaultRules...)), router.WithDebugVars())
+	} else {
+		log.Warn("authentication is disabled")
+	}
+	var webhooks *webhook.Store
+	if dbConn != nil && cfg.Webhooks.Enabled {
+		webhooks = webhook.NewStore(dbConn)
+		routerOpts = append(routerOpts, router.WithWebhooks(webhooks))
+	}
+	if bus != nil {
+		routerOpts = append(routerOpts, router.WithStream(bus, cfg.Stream.Heartbeat))
+	}
+	if cfg.Legacy.Enabled {
+		routerOpts = append(routerOpts, router.WithLegacyAliases(cfg.Legacy.Deprecation, cfg.Legacy.Sunset))
+	}
+	if cfg.HTTP.ValidateRequests {
+		validator, err := openapi.NewValidator(openapi.Spec())
+		if err != nil {
+			panic(fmt.Errorf("request validator setup failed: %v", err))
+		}
+		routerOpts = append(routerOpts, router.WithValidator(validator))
+	}
+	var wrappedRouter http.Handler = router.New(ns, routerOpts...)
+	wrappedRouter = middleware.CacheControl(cfg.CacheControl.Middleware(), wrappedRouter)
+	if len(cfg.Database.Replicas) != 0 {
+		wrappedRouter = middleware.ReadYourWrites(db.NewSticky(cfg.Database.ReadYourWrites), cfg.RateLimit.TrustForwardedFor, wrappedRouter)
+	}
+	limiter := ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Enabled {
+		wrappedRouter = middleware.RateLimit(limiter, middleware.RateLimits{
+			Read:              cfg.RateLimit.Read.Limit(),
+			Write:             cfg.RateLimit.Write.Limit(),
+			TrustForwardedFor: cfg.RateLimit.TrustForwardedFor,
+		}, wrappedRouter)
+	}
+	if len(authenticators) != 0 {
+		wrappedRouter = middleware.Authenticate(auth.Chain(authenticators...), wrappedRouter)
+	}
+	if cfg.RateLimit.Enabled {
+		wrappedRouter = middleware.RateLimitAddress(limiter, cfg.RateLimit.Address.Limit(), cfg.RateLimit.TrustForwardedFor, wrappedRouter)
+	}
+	if cfg.CORS.Enabled() {
+		wrappedRouter = middleware.CORS(cfg.CORS.Middleware(), wrappedRouter)
+	}
+	if cfg.Compression.Enabled {
+		wrappedRouter = middleware.Compress(cfg.Compression.MinSize, int64(cfg.HTTP.MaxBodySize), wrappedRouter)
+	}
+	wrappedRouter = middleware.LimitBody(int64(cfg.HTTP.MaxBodySize), wrappedRouter)
+	wrappedRouter = middleware.AddLogger(log, middleware.LogRequest(wrappedRouter))
+	log.Info("server running", "addr", cfg.HTTP.Addr)
 
 	server := &http.Server{
-		Addr:              ":8080",
-		ReadHeaderTimeout: 3 * time.Second,
+		Addr:              cfg.HTTP.Addr,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 		Handler:           wrappedRouter,
 	}
+	if bus != nil {
+		// Shutdown waits for connections to go idle, which streams never do, closing the bus ends them
+		server.RegisterOnShutdown(bus.Close)
+	}
 
 	errGrp, errGrpCtx := errgroup.WithContext(context.Background())
 
+	if cluster != nil {
+		errGrp.Go(func() error {
+			return cluster.Run(logger.CtxWithLogger(errGrpCtx, log))
+		})
+	}
+	if cfg.Store == "postgres" && len(listenOpts) != 0 {
+		connConfig, err := cfg.DB().ConnConfig()
+		if err != nil {
+			panic(fmt.Errorf("news change listener setup failed: %v", err))
+		}
+		listener := events.NewListener(connConfig, dbConn, listenOpts...)
+		errGrp.Go(func() error {
+			return listener.Run(logger.CtxWithLogger(errGrpCtx, log))
+		})
+	}
+	if dbConn != nil {
+		sink, err := newSink(cfg.Outbox)
+		if err != nil {
+			panic(fmt.Errorf("outbox sink setup failed: %v", err))
+		}
+		if c, ok := sink.(io.Closer); ok {
+			defer c.Close()
+		}
+		if webhooks != nil {
+			sink = outbox.Sinks(sink, webhooks)
+			wh := cfg.Webhooks
+			deliverer := webhook.NewDeliverer(dbConn,
+				webhook.WithClient(webhook.NewClient(wh.Timeout, wh.AllowPrivate)),
+				webhook.WithMaxAttempts(wh.MaxAttempts),
+				webhook.WithDisableAfter(wh.DisableAfter),
+				webhook.WithBackoff(5*time.Second, wh.MaxBackoff),
+				webhook.WithRetention(wh.Retention),
+			)
+			errGrp.Go(func() error {
+				return deliverer.Run(logger.CtxWithLogger(errGrpCtx, log))
+			})
+		}
+		relay := outbox.NewRelay(dbConn, sink,
+			outbox.WithInterval(cfg.Outbox.Interval),
+			outbox.WithBatchSize(cfg.Outbox.BatchSize),
+			outbox.WithMaxBackoff(cfg.Outbox.MaxBackoff),
+			outbox.WithRetention(cfg.Outbox.Retention),
+		)
+		errGrp.Go(func() error {
+			return relay.Run(logger.CtxWithLogger(errGrpCtx, log))
+		})
+	}
+
 	errGrp.Go(func() error {
 		if err := server.ListenAndServe(); err != nil {
 			log.Error("error in running server", "error", err)
@@ -63,7 +245,7 @@ func main() {
 		case <-errGrpCtx.Done():
 		}
 
-		ctx, cancelFunc := context.WithTimeout(errGrpCtx, 5*time.Second)
+		ctx, cancelFunc := context.WithTimeout(errGrpCtx, cfg.HTTP.ShutdownTimeout)
 		defer cancelFunc()
 
 		log.Info("initiating graceful shutdown")
@@ -78,3 +260,15 @@ func main() {
 		log.Error("failed running errgroup", "error", err)
 	}
 }
+
+// newSink returns the sink the news change events are published to
+func newSink(o config.Outbox) (outbox.Sink, error) {
+	switch o.Sink {
+	case "webhook":
+		return outbox.NewWebhookSink(o.URL, &http.Client{Timeout: 10 * time.Second}), nil
+	case "file":
+		return outbox.NewFileSink(o.Path)
+	default:
+		return outbox.LogSink(), nil
+	}
+}
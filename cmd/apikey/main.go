@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/apikey"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/config"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	cfg, args, err := config.Load("apikey", os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.Store == "memory" {
+		fmt.Fprintf(os.Stderr, "api keys require a database store, not %s\n", cfg.Store)
+		os.Exit(2)
+	}
+
+	dbConn, err := db.NewDB(cfg.DB())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer dbConn.Close()
+
+	app := &cli.App{
+		Name:  "apikey",
+		Usage: "manage api keys used to authenticate against the news api",
+		Commands: []*cli.Command{
+			newApiKeyCmd(apikey.NewStore(dbConn)),
+		},
+	}
+
+	if err := app.Run(append([]string{os.Args[0]}, args...)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newApiKeyCmd(s *apikey.Store) *cli.Command {
+	return &cli.Command{
+		Name:  "apikey",
+		Usage: "apikey is used for minting, listing and revoking api keys",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "mint",
+				Usage: "mint a new api key, the token is printed only once",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Usage: "name describing the key owner", Required: true},
+					&cli.StringSliceFlag{Name: "scope", Usage: "scope granted to the key, repeatable", Required: true},
+				},
+				Action: func(ctx *cli.Context) error {
+					var scopes []auth.Scope
+					for _, name := range ctx.StringSlice("scope") {
+						scope, err := auth.ParseScope(name)
+						if err != nil {
+							return err
+						}
+						scopes = append(scopes, scope)
+					}
+					key, token, err := s.Mint(ctx.Context, ctx.String("name"), scopes)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("minted api key %s (%s) with scopes %s\n", key.Id, key.Name, strings.Join(key.Scopes, ","))
+					fmt.Printf("token: %s\n", token)
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "list api keys",
+				Action: func(ctx *cli.Context) error {
+					keys, err := s.List(ctx.Context)
+					if err != nil {
+						return err
+					}
+					tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tCREATED\tREVOKED")
+					for _, key := range keys {
+						revoked := "-"
+						if !key.RevokedAt.IsZero() {
+							revoked = key.RevokedAt.Format(time.RFC3339)
+						}
+						fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", key.Id, key.Name, key.Prefix,
+							strings.Join(key.Scopes, ","), key.CreatedAt.Format(time.RFC3339), revoked)
+					}
+					return tw.Flush()
+				},
+			},
+			{
+				Name:      "revoke",
+				Usage:     "revoke an api key by id",
+				ArgsUsage: "<id>",
+				Action: func(ctx *cli.Context) error {
+					id, err := uuid.Parse(ctx.Args().First())
+					if err != nil {
+						return fmt.Errorf("invalid key id %q: %w", ctx.Args().First(), err)
+					}
+					if err := s.Revoke(ctx.Context, id); err != nil {
+						return err
+					}
+					fmt.Printf("revoked api key %s\n", id)
+					return nil
+				},
+			},
+		},
+	}
+}
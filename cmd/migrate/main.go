@@ -1,32 +1,41 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
 
+	"github.com/logeshwarann-dev/news-api-rest/internal/config"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
 	"github.com/logeshwarann-dev/news-api-rest/internal/migration"
-	"github.com/logeshwarann-dev/news-api-rest/internal/postgres"
 	"github.com/uptrace/bun/extra/bundebug"
 	"github.com/uptrace/bun/migrate"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
-	db, err := postgres.NewDB(&postgres.Config{
-		DbHost:   os.Getenv("DATABASE_HOST"),
-		DbPort:   os.Getenv("DATABASE_PORT"),
-		DbName:   os.Getenv("DATABASE_NAME"),
-		UserName: os.Getenv("DATABASE_USER"),
-		Password: os.Getenv("DATABASE_PASSWORD"),
-		SSLMode:  "disable",
-	})
+	cfg, args, err := config.Load("migrate", os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if cfg.Store == "memory" {
+		fmt.Fprintf(os.Stderr, "store %s has no database to migrate\n", cfg.Store)
+		os.Exit(2)
+	}
+
+	dbConn, err := db.NewDB(cfg.DB())
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	db.AddQueryHook(bundebug.NewQueryHook(
+	dbConn.AddQueryHook(bundebug.NewQueryHook(
 		bundebug.WithEnabled(false),
 		bundebug.FromEnv(),
 	))
@@ -34,12 +43,12 @@ func main() {
 	app := &cli.App{
 		Name: "migrate",
 		Commands: []*cli.Command{
-			newMigrationCmd(migrate.NewMigrator(db, migration.New(),
+			newMigrationCmd(migrate.NewMigrator(dbConn, migration.New(dbConn.Dialect().Name()),
 				migrate.WithMarkAppliedOnSuccess(true))),
 		},
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	if err := app.Run(append([]string{os.Args[0]}, args...)); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,19 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type Key struct {
+	bun.BaseModel `bun:"table:api_keys"`
+	Id            uuid.UUID `json:"id" bun:"id,pk,type:uuid"`
+	Name          string    `json:"name" bun:"name,nullzero,notnull"`
+	Prefix        string    `json:"prefix" bun:"prefix,nullzero,notnull"`
+	Hash          string    `json:"-" bun:"hash,nullzero,notnull,unique"`
+	Scopes        []string  `json:"scopes" bun:"scopes,nullzero,notnull,array"`
+	CreatedAt     time.Time `json:"created_at" bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	RevokedAt     time.Time `json:"revoked_at" bun:"revoked_at,nullzero"`
+}
@@ -0,0 +1,100 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/uptrace/bun"
+)
+
+var ErrKeyNotFound = errors.New("api key not found")
+
+type Store struct {
+	db bun.IDB
+}
+
+func NewStore(db bun.IDB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// mint a new key, the token is only returned here and never stored
+func (s Store) Mint(ctx context.Context, name string, scopes []auth.Scope) (key Key, token string, err error) {
+	token, prefix, err := NewToken()
+	if err != nil {
+		return key, "", fmt.Errorf("generate token: %w", err)
+	}
+	key = Key{
+		Id:     uuid.New(),
+		Name:   name,
+		Prefix: prefix,
+		Hash:   Hash(token),
+	}
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, string(scope))
+	}
+	if err := s.db.NewInsert().Model(&key).Returning("*").Scan(ctx, &key); err != nil {
+		return key, "", fmt.Errorf("insert api key: %w", err)
+	}
+	return key, token, nil
+}
+
+// list all keys including revoked ones
+func (s Store) List(ctx context.Context) (keys []Key, err error) {
+	if err := s.db.NewSelect().Model(&keys).Order("created_at").Scan(ctx); err != nil {
+		return keys, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// revoke key by id
+func (s Store) Revoke(ctx context.Context, id uuid.UUID) error {
+	r, err := s.db.NewUpdate().Model((*Key)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("id = ?", id).
+		Where("revoked_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	rows, err := r.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	if rows == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves an active key from its token
+func (s Store) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	if !IsToken(token) {
+		return auth.Principal{}, auth.ErrInvalidCredentials
+	}
+	var key Key
+	err := s.db.NewSelect().Model(&key).
+		Where("hash = ?", Hash(token)).
+		Where("revoked_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return auth.Principal{}, auth.ErrInvalidCredentials
+		}
+		return auth.Principal{}, fmt.Errorf("find api key: %w", err)
+	}
+	principal := auth.Principal{
+		Subject: key.Id.String(),
+		Name:    key.Name,
+	}
+	for _, scope := range key.Scopes {
+		principal.Scopes = append(principal.Scopes, auth.Scope(scope))
+	}
+	return principal, nil
+}
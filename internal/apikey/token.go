@@ -0,0 +1,33 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// tokenPrefix marks api keys so they are easy to spot in logs and secret scanners
+const tokenPrefix = "nak_"
+
+// NewToken returns a random api key token and the short prefix shown when listing keys
+func NewToken() (token string, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	return tokenPrefix + secret, tokenPrefix + secret[:8], nil
+}
+
+// Hash returns the digest stored in place of the token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsToken reports whether the bearer token looks like an api key
+func IsToken(token string) bool {
+	return strings.HasPrefix(token, tokenPrefix)
+}
@@ -0,0 +1,28 @@
+package apikey_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/apikey"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewToken(t *testing.T) {
+	token, prefix, err := apikey.NewToken()
+	assert.NoError(t, err)
+	assert.True(t, apikey.IsToken(token))
+	assert.True(t, strings.HasPrefix(token, prefix))
+
+	other, _, err := apikey.NewToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func Test_Hash(t *testing.T) {
+	token, _, err := apikey.NewToken()
+	assert.NoError(t, err)
+	assert.Equal(t, apikey.Hash(token), apikey.Hash(token))
+	assert.NotContains(t, apikey.Hash(token), token)
+	assert.Len(t, apikey.Hash(token), 64)
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Scope is a permission carried by a credential
+type Scope string
+
+const (
+	ScopeNewsRead   Scope = "news:read"
+	ScopeNewsWrite  Scope = "news:write"
+	ScopeNewsDelete Scope = "news:delete"
+	ScopeWebhooks   Scope = "webhooks"
+	ScopeAdmin      Scope = "admin"
+)
+
+// Scopes lists every known scope
+var Scopes = []Scope{ScopeNewsRead, ScopeNewsWrite, ScopeNewsDelete, ScopeWebhooks, ScopeAdmin}
+
+// Role is a job function mapped from identity provider claims
+type Role string
+
+const (
+	RoleReader   Role = "reader"
+	RoleReporter Role = "reporter"
+	RoleEditor   Role = "editor"
+	RoleAdmin    Role = "admin"
+)
+
+// roleScopes lists the scopes each role grants
+var roleScopes = map[Role][]Scope{
+	RoleReader:   {ScopeNewsRead},
+	RoleReporter: {ScopeNewsRead, ScopeNewsWrite, ScopeNewsDelete},
+	RoleEditor:   {ScopeNewsRead, ScopeNewsWrite, ScopeNewsDelete},
+	RoleAdmin:    {ScopeAdmin},
+}
+
+// ScopesForRoles returns the scopes granted by the known roles, unknown roles grant nothing
+func ScopesForRoles(roles []Role) (scopes []Scope) {
+	for _, role := range roles {
+		for _, scope := range roleScopes[role] {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// ErrInvalidCredentials is returned by authenticators for unknown, expired or revoked credentials
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ParseScope validates a scope name
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(s)
+	if !slices.Contains(Scopes, scope) {
+		return "", fmt.Errorf("unknown scope: %s", s)
+	}
+	return scope, nil
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject string
+	Name    string
+	Scopes  []Scope
+	Roles   []Role
+}
+
+// HasScope reports whether the principal was granted the scope, admin grants every scope
+func (p Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// HasRole reports whether the principal holds the role
+func (p Principal) HasRole(role Role) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// Authenticator resolves a bearer token to a principal
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Principal, error)
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator
+type AuthenticatorFunc func(ctx context.Context, token string) (Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, token string) (Principal, error) {
+	return f(ctx, token)
+}
+
+// Chain tries each authenticator in order until one recognizes the token
+func Chain(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, token string) (Principal, error) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(ctx, token)
+			if errors.Is(err, ErrInvalidCredentials) {
+				continue
+			}
+			return p, err
+		}
+		return Principal{}, ErrInvalidCredentials
+	})
+}
+
+type CtxKey struct{}
+
+func CtxWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, CtxKey{}, p)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(CtxKey{}).(Principal)
+	return p, ok
+}
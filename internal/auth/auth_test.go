@@ -0,0 +1,86 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HasScope(t *testing.T) {
+	testcases := []struct {
+		name      string
+		principal auth.Principal
+		scope     auth.Scope
+		expected  bool
+	}{
+		{
+			name:      "return_true_for_granted_scope",
+			principal: auth.Principal{Scopes: []auth.Scope{auth.ScopeNewsRead}},
+			scope:     auth.ScopeNewsRead,
+			expected:  true,
+		},
+		{
+			name:      "return_false_for_missing_scope",
+			principal: auth.Principal{Scopes: []auth.Scope{auth.ScopeNewsRead}},
+			scope:     auth.ScopeNewsDelete,
+			expected:  false,
+		},
+		{
+			name:      "return_true_for_admin",
+			principal: auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}},
+			scope:     auth.ScopeNewsDelete,
+			expected:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.principal.HasScope(tc.scope))
+		})
+	}
+}
+
+func Test_ParseScope(t *testing.T) {
+	scope, err := auth.ParseScope("news:write")
+	assert.NoError(t, err)
+	assert.Equal(t, auth.ScopeNewsWrite, scope)
+
+	_, err = auth.ParseScope("news:everything")
+	assert.ErrorContains(t, err, "unknown scope")
+}
+
+func Test_FromContext(t *testing.T) {
+	_, ok := auth.FromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := auth.CtxWithPrincipal(context.Background(), auth.Principal{Subject: "key-1"})
+	p, ok := auth.FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "key-1", p.Subject)
+}
+
+func Test_ScopesForRoles(t *testing.T) {
+	assert.Equal(t, []auth.Scope{auth.ScopeNewsRead}, auth.ScopesForRoles([]auth.Role{auth.RoleReader}))
+	assert.Equal(t,
+		[]auth.Scope{auth.ScopeNewsRead, auth.ScopeNewsWrite, auth.ScopeNewsDelete},
+		auth.ScopesForRoles([]auth.Role{auth.RoleReader, auth.RoleEditor}))
+	assert.Empty(t, auth.ScopesForRoles([]auth.Role{"intern"}))
+}
+
+func Test_Chain(t *testing.T) {
+	reject := auth.AuthenticatorFunc(func(context.Context, string) (auth.Principal, error) {
+		return auth.Principal{}, auth.ErrInvalidCredentials
+	})
+	accept := auth.AuthenticatorFunc(func(_ context.Context, token string) (auth.Principal, error) {
+		return auth.Principal{Subject: token}, nil
+	})
+
+	p, err := auth.Chain(reject, accept).Authenticate(context.Background(), "user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", p.Subject)
+
+	_, err = auth.Chain(reject, reject).Authenticate(context.Background(), "user-1")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
@@ -0,0 +1,216 @@
+// Package cache provides an in process read-through cache in front of the news store.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"golang.org/x/sync/singleflight"
+)
+
+// Store caches FindById, FindAll and Stats results of the wrapped store.
+// Every write drops the whole cache: a write may change any filtered list,
+// and dropping everything keeps loads racing with the write from caching
+// what they read before it. Reads pinned to the primary by db.WithPrimary
+// skip the cache, which may hold what a lagging replica returned. For the
+// primary window after an invalidation, loads are read from the primary so
+// that they do not cache what a replica returned before the change reached it.
+type Store struct {
+	next          handler.NewsStorer
+	lru           *LRU[string, any]
+	loadTimeout   time.Duration
+	primaryWindow time.Duration
+	now           func() time.Time
+	group         singleflight.Group
+	generation    atomic.Uint64
+	// invalidatedAt is the time of the last invalidation, in unix nanoseconds
+	invalidatedAt atomic.Int64
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+}
+
+var _ handler.NewsStorer = (*Store)(nil)
+
+type options struct {
+	size          int
+	ttl           time.Duration
+	loadTimeout   time.Duration
+	primaryWindow time.Duration
+	now           func() time.Time
+}
+
+// Option configures the Store
+type Option func(*options)
+
+// WithSize bounds the number of cached results
+func WithSize(size int) Option {
+	return func(o *options) {
+		o.size = size
+	}
+}
+
+// WithTTL bounds how long a result is served, which is also how stale a
+// replica may be after a write handled by another one
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithLoadTimeout bounds a load shared by concurrent misses, which outlives
+// the caller that started it
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.loadTimeout = timeout
+	}
+}
+
+// WithPrimaryWindow reads the loads made within window of an invalidation
+// from the primary, window being how far replicas may lag behind it. A
+// change notified by another api-server is then not cached as a replica
+// read before the change.
+func WithPrimaryWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.primaryWindow = window
+	}
+}
+
+// WithClock replaces time.Now, it is meant for tests
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// NewStore wraps next, by default caching 1000 results for 30 seconds and
+// giving up loads after 10 seconds
+func NewStore(next handler.NewsStorer, opts ...Option) *Store {
+	o := options{size: 1000, ttl: 30 * time.Second, loadTimeout: 10 * time.Second, now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Store{
+		next:          next,
+		lru:           NewLRU[string, any](o.size, o.ttl, o.now),
+		loadTimeout:   o.loadTimeout,
+		primaryWindow: o.primaryWindow,
+		now:           o.now,
+	}
+}
+
+// Metrics describes the cache efficiency
+type Metrics struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+}
+
+// Metrics returns the counters since the store was created
+func (s *Store) Metrics() Metrics {
+	return Metrics{
+		Hits:      s.hits.Load(),
+		Misses:    s.misses.Load(),
+		Evictions: s.lru.Evicted(),
+		Entries:   s.lru.Len(),
+	}
+}
+
+func (s *Store) FindById(ctx context.Context, id uuid.UUID) (news.Record, error) {
+	return load(ctx, s, "id:"+id.String(), func(ctx context.Context) (news.Record, error) {
+		return s.next.FindById(ctx, id)
+	})
+}
+
+func (s *Store) FindAll(ctx context.Context, f news.Filter) ([]news.Record, error) {
+	records, err := load(ctx, s, fmt.Sprintf("list:%+v", f), func(ctx context.Context) ([]news.Record, error) {
+		return s.next.FindAll(ctx, f)
+	})
+	// callers get their own slice
+	return slices.Clone(records), err
+}
+
+func (s *Store) Stats(ctx context.Context, f news.Filter) (news.Stats, error) {
+	return load(ctx, s, fmt.Sprintf("stats:%+v", f), func(ctx context.Context) (news.Stats, error) {
+		return s.next.Stats(ctx, f)
+	})
+}
+
+// Stream is not cached, it serves listings too large to be held in memory
+func (s *Store) Stream(ctx context.Context, f news.Filter) news.Records {
+	return s.next.Stream(ctx, f)
+}
+
+func (s *Store) Create(ctx context.Context, record news.Record) (news.Record, error) {
+	defer s.Invalidate()
+	return s.next.Create(ctx, record)
+}
+
+func (s *Store) UpdateById(ctx context.Context, id uuid.UUID, record news.Record) error {
+	defer s.Invalidate()
+	return s.next.UpdateById(ctx, id, record)
+}
+
+func (s *Store) UpdateByIdIfUnchanged(ctx context.Context, id uuid.UUID, updatedAt time.Time, record news.Record) error {
+	defer s.Invalidate()
+	return s.next.UpdateByIdIfUnchanged(ctx, id, updatedAt, record)
+}
+
+func (s *Store) DeleteById(ctx context.Context, id uuid.UUID) error {
+	defer s.Invalidate()
+	return s.next.DeleteById(ctx, id)
+}
+
+// Invalidate drops the cache, for changes made without going through the
+// store. It moves to a new generation, loads started before it store their
+// results under keys that are never looked up again.
+func (s *Store) Invalidate() {
+	s.invalidatedAt.Store(s.now().UnixNano())
+	s.generation.Add(1)
+	s.lru.Purge()
+}
+
+// load returns the cached result for key or calls fetch once for all the
+// concurrent callers missing it. Errors are not cached, and reads pinned to
+// the primary are neither served from nor stored in the cache; those made
+// for the cache within the primary window are, they are current. The shared
+// fetch is detached from the cancellation of the caller starting it, each
+// caller stops waiting for it when its own ctx is done.
+func load[T any](ctx context.Context, s *Store, key string, fetch func(context.Context) (T, error)) (T, error) {
+	if db.UsesPrimary(ctx) {
+		return fetch(ctx)
+	}
+	key = fmt.Sprintf("%d:%s", s.generation.Load(), key)
+	if v, ok := s.lru.Get(key); ok {
+		s.hits.Add(1)
+		return v.(T), nil
+	}
+	s.misses.Add(1)
+	ch := s.group.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.loadTimeout)
+		defer cancel()
+		if s.now().Sub(time.Unix(0, s.invalidatedAt.Load())) < s.primaryWindow {
+			ctx = db.WithPrimary(ctx)
+		}
+		v, err := fetch(ctx)
+		if err != nil {
+			return v, err
+		}
+		s.lru.Add(key, v)
+		return v, nil
+	})
+	select {
+	case res := <-ch:
+		return res.Val.(T), res.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
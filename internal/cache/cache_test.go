@@ -0,0 +1,235 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/cache"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	mockshandler "github.com/logeshwarann-dev/news-api-rest/internal/handler/mocks"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var newsId = uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a")
+
+func Test_StoreCachesReads(t *testing.T) {
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	filter := news.Filter{Tag: "go"}
+	mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{Id: newsId, Title: "cached"}, nil).Times(1)
+	mh.EXPECT().FindAll(gomock.Any(), filter).Return([]news.Record{{Id: newsId}}, nil).Times(1)
+	mh.EXPECT().Stats(gomock.Any(), filter).Return(news.Stats{Count: 1}, nil).Times(1)
+	s := cache.NewStore(mh)
+	ctx := context.Background()
+
+	for range 2 {
+		record, err := s.FindById(ctx, newsId)
+		require.NoError(t, err)
+		assert.Equal(t, "cached", record.Title)
+		records, err := s.FindAll(ctx, filter)
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+		stats, err := s.Stats(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.Count)
+	}
+
+	assert.Equal(t, cache.Metrics{Hits: 3, Misses: 3, Entries: 3}, s.Metrics())
+}
+
+func Test_StoreSkipsPrimaryReads(t *testing.T) {
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	gomock.InOrder(
+		mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{Id: newsId, Title: "replica"}, nil),
+		mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{Id: newsId, Title: "primary"}, nil).Times(2),
+	)
+	s := cache.NewStore(mh)
+	ctx := context.Background()
+
+	record, err := s.FindById(ctx, newsId)
+	require.NoError(t, err)
+	assert.Equal(t, "replica", record.Title)
+	for range 2 {
+		record, err = s.FindById(db.WithPrimary(ctx), newsId)
+		require.NoError(t, err)
+		assert.Equal(t, "primary", record.Title)
+	}
+	record, err = s.FindById(ctx, newsId)
+	require.NoError(t, err)
+	assert.Equal(t, "replica", record.Title, "primary reads leave the cache alone")
+	assert.Equal(t, cache.Metrics{Hits: 1, Misses: 1, Entries: 1}, s.Metrics())
+}
+
+func Test_StoreDoesNotCacheErrors(t *testing.T) {
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	gomock.InOrder(
+		mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{}, errors.New("db error")),
+		mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{Id: newsId}, nil),
+	)
+	s := cache.NewStore(mh)
+
+	_, err := s.FindById(context.Background(), newsId)
+	assert.Error(t, err)
+	_, err = s.FindById(context.Background(), newsId)
+	assert.NoError(t, err)
+}
+
+func Test_StoreInvalidatesOnWrites(t *testing.T) {
+	testcases := []struct {
+		name  string
+		write func(s *cache.Store, mh *mockshandler.MockNewsStorer) error
+	}{
+		{
+			name: "create",
+			write: func(s *cache.Store, mh *mockshandler.MockNewsStorer) error {
+				mh.EXPECT().Create(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+				_, err := s.Create(context.Background(), news.Record{})
+				return err
+			},
+		},
+		{
+			name: "update",
+			write: func(s *cache.Store, mh *mockshandler.MockNewsStorer) error {
+				mh.EXPECT().UpdateById(gomock.Any(), newsId, gomock.Any()).Return(nil)
+				return s.UpdateById(context.Background(), newsId, news.Record{})
+			},
+		},
+		{
+			name: "delete",
+			write: func(s *cache.Store, mh *mockshandler.MockNewsStorer) error {
+				mh.EXPECT().DeleteById(gomock.Any(), newsId).Return(nil)
+				return s.DeleteById(context.Background(), newsId)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{Id: newsId}, nil).Times(2)
+			mh.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+			s := cache.NewStore(mh)
+			ctx := context.Background()
+
+			s.FindById(ctx, newsId)
+			s.FindAll(ctx, news.Filter{})
+			require.NoError(t, tc.write(s, mh))
+			s.FindById(ctx, newsId)
+			s.FindAll(ctx, news.Filter{})
+
+			assert.Equal(t, uint64(4), s.Metrics().Misses)
+		})
+	}
+}
+
+func Test_StoreInvalidate(t *testing.T) {
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{Id: newsId}, nil).Times(2)
+	s := cache.NewStore(mh)
+
+	s.FindById(context.Background(), newsId)
+	s.Invalidate()
+	s.FindById(context.Background(), newsId)
+}
+
+func Test_StoreLoadsFromPrimaryAfterInvalidate(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	var fromPrimary []bool
+	mh.EXPECT().FindById(gomock.Any(), newsId).DoAndReturn(func(ctx context.Context, id uuid.UUID) (news.Record, error) {
+		fromPrimary = append(fromPrimary, db.UsesPrimary(ctx))
+		return news.Record{Id: id}, nil
+	}).Times(3)
+	s := cache.NewStore(mh, cache.WithPrimaryWindow(5*time.Second), cache.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	s.FindById(ctx, newsId)
+	// a change notified by another api-server, which the replicas may lack
+	s.Invalidate()
+	s.FindById(ctx, newsId)
+	s.FindById(ctx, newsId)
+	now = now.Add(5 * time.Second)
+	s.Invalidate()
+	now = now.Add(5 * time.Second)
+	s.FindById(ctx, newsId)
+
+	assert.Equal(t, []bool{false, true, false}, fromPrimary)
+	assert.Equal(t, uint64(1), s.Metrics().Hits, "primary loads are cached")
+}
+
+func Test_StoreExpiresEntries(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	mh.EXPECT().FindById(gomock.Any(), newsId).Return(news.Record{Id: newsId}, nil).Times(2)
+	s := cache.NewStore(mh, cache.WithTTL(time.Second), cache.WithClock(func() time.Time { return now }))
+
+	s.FindById(context.Background(), newsId)
+	now = now.Add(time.Second)
+	s.FindById(context.Background(), newsId)
+}
+
+func Test_StoreCoalescesConcurrentMisses(t *testing.T) {
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	release := make(chan struct{})
+	mh.EXPECT().FindById(gomock.Any(), newsId).DoAndReturn(func(context.Context, uuid.UUID) (news.Record, error) {
+		<-release
+		return news.Record{Id: newsId}, nil
+	}).Times(1)
+	s := cache.NewStore(mh)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record, err := s.FindById(context.Background(), newsId)
+			assert.NoError(t, err)
+			assert.Equal(t, newsId, record.Id)
+		}()
+	}
+	// let the goroutines pile up on the pending load
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+func Test_StoreLoadOutlivesCancelledCaller(t *testing.T) {
+	mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mh.EXPECT().FindById(gomock.Any(), newsId).DoAndReturn(func(ctx context.Context, _ uuid.UUID) (news.Record, error) {
+		close(started)
+		<-release
+		return news.Record{Id: newsId}, ctx.Err()
+	}).Times(1)
+	s := cache.NewStore(mh)
+	leaderCtx, cancel := context.WithCancel(context.Background())
+
+	leaderErr := make(chan error)
+	go func() {
+		_, err := s.FindById(leaderCtx, newsId)
+		leaderErr <- err
+	}()
+	<-started
+	followerErr := make(chan error)
+	go func() {
+		record, err := s.FindById(context.Background(), newsId)
+		assert.Equal(t, newsId, record.Id)
+		followerErr <- err
+	}()
+	// let the follower join the pending load
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	close(release)
+	assert.NoError(t, <-followerErr)
+	_, err := s.FindById(context.Background(), newsId)
+	assert.NoError(t, err, "the result is cached")
+}
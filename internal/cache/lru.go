@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a size bounded cache whose entries also expire after a ttl
+type LRU[K comparable, V any] struct {
+	m       sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List
+	entries map[K]*list.Element
+	// evicted counts the entries dropped to make room
+	evicted uint64
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// NewLRU returns a cache holding at most size entries for at most ttl
+func NewLRU[K comparable, V any](size int, ttl time.Duration, now func() time.Time) *LRU[K, V] {
+	return &LRU[K, V]{
+		size:    size,
+		ttl:     ttl,
+		now:     now,
+		order:   list.New(),
+		entries: map[K]*list.Element{},
+	}
+}
+
+// Get returns the live entry for key and marks it as recently used
+func (c *LRU[K, V]) Get(key K) (value V, ok bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return value, false
+	}
+	e := el.Value.(*entry[K, V])
+	if !c.now().Before(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return value, false
+	}
+	c.order.MoveToFront(el)
+	return e.value, true
+}
+
+// Add stores the value, evicting the least recently used entry when full
+func (c *LRU[K, V]) Add(key K, value V) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+		c.evicted++
+	}
+}
+
+// Purge drops every entry
+func (c *LRU[K, V]) Purge() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.order.Init()
+	clear(c.entries)
+}
+
+// Len returns the number of entries, expired ones included until they are looked up or evicted
+func (c *LRU[K, V]) Len() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.order.Len()
+}
+
+// Evicted returns the number of entries dropped to make room
+func (c *LRU[K, V]) Evicted() uint64 {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.evicted
+}
@@ -0,0 +1,32 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LRU(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	c := cache.NewLRU[string, int](2, time.Minute, func() time.Time { return now })
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	_, ok := c.Get("a")
+	assert.True(t, ok, "a is now the most recently used")
+
+	c.Add("c", 3)
+	_, ok = c.Get("b")
+	assert.False(t, ok, "b was evicted")
+	assert.Equal(t, uint64(1), c.Evicted())
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "a expired")
+	assert.Equal(t, 1, c.Len())
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,675 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/logeshwarann-dev/news-api-rest/internal/ratelimit"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings shared by the api-server and migrate commands
+type Config struct {
+	// Store is the news store backend, postgres, sqlite or memory
+	Store        string       `yaml:"store"`
+	Database     Database     `yaml:"database"`
+	HTTP         HTTP         `yaml:"http"`
+	Log          Log          `yaml:"log"`
+	Auth         Auth         `yaml:"auth"`
+	RateLimit    RateLimit    `yaml:"ratelimit"`
+	CORS         CORS         `yaml:"cors"`
+	CacheControl CacheControl `yaml:"cache_control"`
+	Cache        Cache        `yaml:"cache"`
+	Compression  Compression  `yaml:"compression"`
+	Outbox       Outbox       `yaml:"outbox"`
+	Webhooks     Webhooks     `yaml:"webhooks"`
+	Stream       Stream       `yaml:"stream"`
+	Legacy       Legacy       `yaml:"legacy"`
+}
+
+// Database holds the postgres connection and pool settings, and the sqlite file
+type Database struct {
+	Path            string        `yaml:"path"`
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	Name            string        `yaml:"name"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	SSLMode         string        `yaml:"sslmode"`
+	SSLRootCert     string        `yaml:"sslrootcert"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	Debug           bool          `yaml:"debug"`
+
+	// Replicas are the connection strings of read replicas of the postgres primary
+	Replicas             []string      `yaml:"replicas"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval"`
+	// ReadYourWrites is how long a client reads from the primary after a write
+	ReadYourWrites time.Duration `yaml:"read_your_writes"`
+}
+
+// HTTP holds the api server listener settings
+type HTTP struct {
+	Addr              string        `yaml:"addr"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	// MaxBodySize caps request bodies in bytes, after decompression too
+	MaxBodySize int `yaml:"max_body_size"`
+	// ValidateRequests rejects requests not matching the openapi description
+	ValidateRequests bool `yaml:"validate_requests"`
+}
+
+// Auth holds the request authentication settings
+type Auth struct {
+	// Mode is none or a comma separated list of apikey and jwt
+	Mode string `yaml:"mode"`
+	JWT  JWT    `yaml:"jwt"`
+}
+
+// JWT holds the settings for verifying gateway issued tokens
+type JWT struct {
+	// JWKS is a file path or an http(s) url
+	JWKS       string        `yaml:"jwks"`
+	Issuer     string        `yaml:"issuer"`
+	Audience   string        `yaml:"audience"`
+	RolesClaim string        `yaml:"roles_claim"`
+	Leeway     time.Duration `yaml:"leeway"`
+}
+
+// Methods returns the enabled authentication methods, none yields an empty list
+func (a Auth) Methods() (methods []string) {
+	for _, m := range strings.Split(a.Mode, ",") {
+		if m = strings.TrimSpace(m); m != "" && m != "none" {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// RateLimit holds the per client request limits
+type RateLimit struct {
+	Enabled bool           `yaml:"enabled"`
+	Read    RateLimitClass `yaml:"read"`
+	Write   RateLimitClass `yaml:"write"`
+	// Address limits every request of a client address before authentication
+	Address RateLimitClass `yaml:"address"`
+	// TrustForwardedFor identifies anonymous clients by the address the proxy appended to X-Forwarded-For, enable it only behind a proxy
+	TrustForwardedFor bool `yaml:"trust_forwarded_for"`
+}
+
+// RateLimitClass allows Requests per Period with bursts of up to Requests
+type RateLimitClass struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+}
+
+// Limit returns the token bucket for the class
+func (c RateLimitClass) Limit() ratelimit.Limit {
+	return ratelimit.Limit{Requests: c.Requests, Period: c.Period}
+}
+
+// CORS holds the cross origin settings for browser clients
+type CORS struct {
+	// AllowedOrigins holds exact origins, "*" or wildcard subdomains such as
+	// https://*.example.com, an empty list disables cors
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods"`
+	AllowedHeaders   []string      `yaml:"allowed_headers"`
+	ExposedHeaders   []string      `yaml:"exposed_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// Enabled reports whether any origin is allowed
+func (c CORS) Enabled() bool {
+	return len(c.AllowedOrigins) != 0
+}
+
+// Middleware returns the settings for middleware.CORS
+func (c CORS) Middleware() middleware.CORSConfig {
+	return middleware.CORSConfig{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   c.AllowedMethods,
+		AllowedHeaders:   c.AllowedHeaders,
+		ExposedHeaders:   c.ExposedHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+}
+
+// CacheControl holds the Cache-Control values of successful reads
+type CacheControl struct {
+	Public  string `yaml:"public"`
+	Private string `yaml:"private"`
+}
+
+// Middleware returns the settings for middleware.CacheControl
+func (c CacheControl) Middleware() middleware.CachePolicy {
+	return middleware.CachePolicy{Public: c.Public, Private: c.Private}
+}
+
+// Cache holds the settings of the in process read-through cache
+type Cache struct {
+	Enabled bool          `yaml:"enabled"`
+	Size    int           `yaml:"size"`
+	TTL     time.Duration `yaml:"ttl"`
+}
+
+// Compression holds the settings of middleware.Compress
+type Compression struct {
+	Enabled bool `yaml:"enabled"`
+	MinSize int  `yaml:"min_size"`
+}
+
+// Outbox holds the settings of the relay publishing the news change events
+type Outbox struct {
+	// Sink is log, webhook or file
+	Sink       string        `yaml:"sink"`
+	URL        string        `yaml:"url"`
+	Path       string        `yaml:"path"`
+	Interval   time.Duration `yaml:"interval"`
+	BatchSize  int           `yaml:"batch_size"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// Retention is how long published events are kept, 0 keeps them forever
+	Retention time.Duration `yaml:"retention"`
+}
+
+// Webhooks holds the settings of the webhook subscriptions and their deliveries
+type Webhooks struct {
+	Enabled bool          `yaml:"enabled"`
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAttempts is how many times a delivery is tried before it fails
+	MaxAttempts int `yaml:"max_attempts"`
+	// DisableAfter is how many failed deliveries in a row disable a subscription
+	DisableAfter int           `yaml:"disable_after"`
+	MaxBackoff   time.Duration `yaml:"max_backoff"`
+	// AllowPrivate lets subscriptions post to loopback and private addresses
+	AllowPrivate bool          `yaml:"allow_private"`
+	Retention    time.Duration `yaml:"retention"`
+}
+
+// Stream holds the settings of the live stream of news changes
+type Stream struct {
+	Enabled   bool          `yaml:"enabled"`
+	Heartbeat time.Duration `yaml:"heartbeat"`
+	// Replay is how many recent events are kept for clients resuming the stream
+	Replay int `yaml:"replay"`
+}
+
+// Legacy holds the settings of the unversioned aliases of the /v1 routes
+type Legacy struct {
+	Enabled bool `yaml:"enabled"`
+	// Deprecation is when the aliases were deprecated, sent in Deprecation
+	Deprecation time.Time `yaml:"deprecation"`
+	// Sunset is when the aliases stop being served, sent in Sunset
+	Sunset time.Time `yaml:"sunset"`
+}
+
+// Log holds the logger settings
+type Log struct {
+	Level string `yaml:"level"`
+}
+
+// Default returns the configuration used when nothing else is provided
+func Default() *Config {
+	return &Config{
+		Store: "postgres",
+		Database: Database{
+			Path:            "news.db",
+			Port:            "5432",
+			SSLMode:         "disable",
+			MaxIdleConns:    5,
+			MaxOpenConns:    25,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+
+			ReplicaCheckInterval: 5 * time.Second,
+			ReadYourWrites:       5 * time.Second,
+		},
+		HTTP: HTTP{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 3 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   5 * time.Second,
+			MaxBodySize:       1 << 20,
+		},
+		Log: Log{
+			Level: "info",
+		},
+		Auth: Auth{
+			Mode: "apikey",
+			JWT: JWT{
+				RolesClaim: "roles",
+				Leeway:     30 * time.Second,
+			},
+		},
+		RateLimit: RateLimit{
+			Enabled: true,
+			Read:    RateLimitClass{Requests: 300, Period: time.Minute},
+			Write:   RateLimitClass{Requests: 60, Period: time.Minute},
+			Address: RateLimitClass{Requests: 600, Period: time.Minute},
+		},
+		CORS: CORS{
+			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+			AllowedHeaders: []string{"Authorization", "Content-Type"},
+			ExposedHeaders: []string{"ETag", "Last-Modified", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Deprecation", "Sunset", "Link"},
+			MaxAge:         10 * time.Minute,
+		},
+		CacheControl: CacheControl{
+			Public:  "public, max-age=60",
+			Private: "private, no-cache",
+		},
+		Cache: Cache{
+			Enabled: true,
+			Size:    1000,
+			TTL:     30 * time.Second,
+		},
+		Compression: Compression{
+			Enabled: true,
+			MinSize: 1024,
+		},
+		Outbox: Outbox{
+			Sink:       "log",
+			Interval:   time.Second,
+			BatchSize:  100,
+			MaxBackoff: time.Minute,
+			Retention:  7 * 24 * time.Hour,
+		},
+		Webhooks: Webhooks{
+			Enabled:      true,
+			Timeout:      10 * time.Second,
+			MaxAttempts:  10,
+			DisableAfter: 20,
+			MaxBackoff:   time.Hour,
+			Retention:    7 * 24 * time.Hour,
+		},
+		Stream: Stream{
+			Enabled:   true,
+			Heartbeat: 15 * time.Second,
+			Replay:    1000,
+		},
+		Legacy: Legacy{
+			Enabled:     true,
+			Deprecation: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+			Sunset:      time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+// Load builds the configuration from defaults, an optional yaml file, environment
+// variables and command line flags, in increasing order of precedence.
+// It returns the arguments left over after flag parsing.
+func Load(name string, args []string) (*Config, []string, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a yaml config file (env CONFIG_FILE)")
+	for _, s := range settings {
+		usage := fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.value.String())
+		// bool flags may be set without a value, as -cache.enabled
+		if _, ok := s.value.(*boolValue); ok {
+			fs.Bool(s.key, false, usage)
+			continue
+		}
+		fs.String(s.key, "", usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	flags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var errs error
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok {
+			if err := s.value.Set(v); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%s: invalid value %q from env %s", s.key, v, s.env))
+			}
+		}
+		if v, ok := flags[s.key]; ok {
+			if err := s.value.Set(v); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("%s: invalid value %q from flag -%s", s.key, v, s.key))
+			}
+		}
+	}
+	if errs != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", errs)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("decode config file %s: %w", path, err)
+	}
+	return nil
+}
+
+var sslModes = map[string]bool{
+	"disable":     true,
+	"allow":       true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() (errs error) {
+	switch c.Store {
+	case db.Postgres:
+		errs = errors.Join(errs, c.Database.validate())
+	case db.SQLite:
+		if c.Database.Path == "" {
+			errs = errors.Join(errs, errors.New("database.path: required when store is sqlite"))
+		}
+		if len(c.Database.Replicas) != 0 {
+			errs = errors.Join(errs, errors.New("database.replicas: only supported when store is postgres"))
+		}
+	case "memory":
+		if slices.Contains(c.Auth.Methods(), "apikey") {
+			errs = errors.Join(errs, errors.New("auth.mode: apikey requires a database store"))
+		}
+	default:
+		errs = errors.Join(errs, fmt.Errorf("store: unknown store %q", c.Store))
+	}
+
+	h := c.HTTP
+	if _, _, err := net.SplitHostPort(h.Addr); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("http.addr: %w", err))
+	}
+	if h.ReadHeaderTimeout < 0 {
+		errs = errors.Join(errs, errors.New("http.read_header_timeout: must not be negative"))
+	}
+	if h.ReadTimeout < 0 {
+		errs = errors.Join(errs, errors.New("http.read_timeout: must not be negative"))
+	}
+	if h.WriteTimeout < 0 {
+		errs = errors.Join(errs, errors.New("http.write_timeout: must not be negative"))
+	}
+	if h.IdleTimeout < 0 {
+		errs = errors.Join(errs, errors.New("http.idle_timeout: must not be negative"))
+	}
+	if h.ShutdownTimeout <= 0 {
+		errs = errors.Join(errs, errors.New("http.shutdown_timeout: must be positive"))
+	}
+	if h.MaxBodySize <= 0 {
+		errs = errors.Join(errs, errors.New("http.max_body_size: must be positive"))
+	}
+
+	if _, err := c.Log.SlogLevel(); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("log.level: unknown level %q", c.Log.Level))
+	}
+
+	if c.Auth.Mode != "none" && len(c.Auth.Methods()) == 0 {
+		errs = errors.Join(errs, errors.New("auth.mode: must be none or list at least one method"))
+	}
+	for _, m := range c.Auth.Methods() {
+		switch m {
+		case "apikey":
+		case "jwt":
+			jwt := c.Auth.JWT
+			if jwt.JWKS == "" {
+				errs = errors.Join(errs, errors.New("auth.jwt.jwks: required when auth.mode includes jwt"))
+			}
+			if jwt.Issuer == "" {
+				errs = errors.Join(errs, errors.New("auth.jwt.issuer: required when auth.mode includes jwt"))
+			}
+			if jwt.Audience == "" {
+				errs = errors.Join(errs, errors.New("auth.jwt.audience: required when auth.mode includes jwt"))
+			}
+			if jwt.Leeway < 0 {
+				errs = errors.Join(errs, errors.New("auth.jwt.leeway: must not be negative"))
+			}
+		default:
+			errs = errors.Join(errs, fmt.Errorf("auth.mode: unknown mode %q", m))
+		}
+	}
+
+	if c.RateLimit.Enabled {
+		for name, class := range map[string]RateLimitClass{"read": c.RateLimit.Read, "write": c.RateLimit.Write, "address": c.RateLimit.Address} {
+			if class.Requests <= 0 {
+				errs = errors.Join(errs, fmt.Errorf("ratelimit.%s.requests: must be positive", name))
+			}
+			if class.Period <= 0 {
+				errs = errors.Join(errs, fmt.Errorf("ratelimit.%s.period: must be positive", name))
+			}
+		}
+	}
+
+	cors := c.CORS
+	for _, origin := range cors.AllowedOrigins {
+		if origin == "*" {
+			if cors.AllowCredentials {
+				errs = errors.Join(errs, errors.New("cors.allowed_origins: * cannot be combined with allow_credentials"))
+			}
+			continue
+		}
+		u, err := url.Parse(strings.Replace(origin, "*.", "", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			errs = errors.Join(errs, fmt.Errorf("cors.allowed_origins: %q is not an origin", origin))
+		}
+	}
+	if cors.Enabled() && len(cors.AllowedMethods) == 0 {
+		errs = errors.Join(errs, errors.New("cors.allowed_methods: must not be empty when origins are allowed"))
+	}
+	if cors.MaxAge < 0 {
+		errs = errors.Join(errs, errors.New("cors.max_age: must not be negative"))
+	}
+
+	if c.Cache.Enabled {
+		if c.Cache.Size <= 0 {
+			errs = errors.Join(errs, errors.New("cache.size: must be positive"))
+		}
+		if c.Cache.TTL <= 0 {
+			errs = errors.Join(errs, errors.New("cache.ttl: must be positive"))
+		}
+	}
+
+	if c.Compression.MinSize < 0 {
+		errs = errors.Join(errs, errors.New("compression.min_size: must not be negative"))
+	}
+
+	if c.Stream.Enabled {
+		if c.Stream.Heartbeat <= 0 {
+			errs = errors.Join(errs, errors.New("stream.heartbeat: must be positive"))
+		}
+		if c.Stream.Replay < 0 {
+			errs = errors.Join(errs, errors.New("stream.replay: must not be negative"))
+		}
+	}
+
+	if c.Legacy.Enabled && !c.Legacy.Sunset.After(c.Legacy.Deprecation) {
+		errs = errors.Join(errs, errors.New("legacy.sunset: must be after legacy.deprecation"))
+	}
+
+	if c.Store != "memory" {
+		errs = errors.Join(errs, c.Outbox.validate())
+		if c.Webhooks.Enabled {
+			errs = errors.Join(errs, c.Webhooks.validate())
+		}
+	}
+
+	if errs != nil {
+		return fmt.Errorf("invalid configuration:\n%w", errs)
+	}
+	return nil
+}
+
+// validate reports the invalid outbox settings
+func (o Outbox) validate() (errs error) {
+	switch o.Sink {
+	case "log":
+	case "webhook":
+		if u, err := url.Parse(o.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = errors.Join(errs, errors.New("outbox.url: must be an http(s) url when sink is webhook"))
+		}
+	case "file":
+		if o.Path == "" {
+			errs = errors.Join(errs, errors.New("outbox.path: required when sink is file"))
+		}
+	default:
+		errs = errors.Join(errs, fmt.Errorf("outbox.sink: unknown sink %q", o.Sink))
+	}
+	if o.Interval <= 0 {
+		errs = errors.Join(errs, errors.New("outbox.interval: must be positive"))
+	}
+	if o.BatchSize <= 0 {
+		errs = errors.Join(errs, errors.New("outbox.batch_size: must be positive"))
+	}
+	if o.MaxBackoff < o.Interval {
+		errs = errors.Join(errs, errors.New("outbox.max_backoff: must not be shorter than interval"))
+	}
+	if o.Retention < 0 {
+		errs = errors.Join(errs, errors.New("outbox.retention: must not be negative"))
+	}
+	return errs
+}
+
+// validate reports the invalid webhook settings
+func (wh Webhooks) validate() (errs error) {
+	if wh.Timeout <= 0 {
+		errs = errors.Join(errs, errors.New("webhooks.timeout: must be positive"))
+	}
+	if wh.MaxAttempts <= 0 {
+		errs = errors.Join(errs, errors.New("webhooks.max_attempts: must be positive"))
+	}
+	if wh.DisableAfter <= 0 {
+		errs = errors.Join(errs, errors.New("webhooks.disable_after: must be positive"))
+	}
+	if wh.MaxBackoff <= 0 {
+		errs = errors.Join(errs, errors.New("webhooks.max_backoff: must be positive"))
+	}
+	if wh.Retention < 0 {
+		errs = errors.Join(errs, errors.New("webhooks.retention: must not be negative"))
+	}
+	return errs
+}
+
+// validate reports the invalid database settings
+func (db Database) validate() (errs error) {
+	if db.Host == "" {
+		errs = errors.Join(errs, errors.New("database.host: must not be empty"))
+	}
+	if port, err := strconv.Atoi(db.Port); err != nil || port < 1 || port > 65535 {
+		errs = errors.Join(errs, fmt.Errorf("database.port: %q is not a valid port", db.Port))
+	}
+	if db.Name == "" {
+		errs = errors.Join(errs, errors.New("database.name: must not be empty"))
+	}
+	if db.User == "" {
+		errs = errors.Join(errs, errors.New("database.user: must not be empty"))
+	}
+	if !sslModes[db.SSLMode] {
+		errs = errors.Join(errs, fmt.Errorf("database.sslmode: unknown mode %q", db.SSLMode))
+	}
+	if db.SSLMode == "verify-ca" || db.SSLMode == "verify-full" {
+		if db.SSLRootCert == "" {
+			errs = errors.Join(errs, fmt.Errorf("database.sslrootcert: required when sslmode is %s", db.SSLMode))
+		}
+	}
+	if db.SSLRootCert != "" {
+		if _, err := os.Stat(db.SSLRootCert); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("database.sslrootcert: %w", err))
+		}
+	}
+	if db.MaxIdleConns < 0 {
+		errs = errors.Join(errs, errors.New("database.max_idle_conns: must not be negative"))
+	}
+	if db.MaxOpenConns < 0 {
+		errs = errors.Join(errs, errors.New("database.max_open_conns: must not be negative"))
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		errs = errors.Join(errs, errors.New("database.max_idle_conns: must not exceed max_open_conns"))
+	}
+	if db.ConnMaxLifetime < 0 {
+		errs = errors.Join(errs, errors.New("database.conn_max_lifetime: must not be negative"))
+	}
+	if db.ConnMaxIdleTime < 0 {
+		errs = errors.Join(errs, errors.New("database.conn_max_idle_time: must not be negative"))
+	}
+	for i, dsn := range db.Replicas {
+		// the parse error may quote the connection string, keep it out of the logs
+		if _, err := pgx.ParseConfig(dsn); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("database.replicas: entry %d is not a valid connection string", i+1))
+		}
+	}
+	if len(db.Replicas) != 0 && db.ReplicaCheckInterval <= 0 {
+		errs = errors.Join(errs, errors.New("database.replica_check_interval: must be positive"))
+	}
+	if db.ReadYourWrites < 0 {
+		errs = errors.Join(errs, errors.New("database.read_your_writes: must not be negative"))
+	}
+	return errs
+}
+
+// DB returns the connection settings of the store for db.NewDB
+func (c *Config) DB() *db.Config {
+	d := c.Database
+	return &db.Config{
+		Dialect:         c.Store,
+		Path:            d.Path,
+		DbHost:          d.Host,
+		DbPort:          d.Port,
+		DbName:          d.Name,
+		UserName:        d.User,
+		Password:        d.Password,
+		SSLMode:         d.SSLMode,
+		SSLRootCert:     d.SSLRootCert,
+		MaxIdleConn:     d.MaxIdleConns,
+		MaxOpenConn:     d.MaxOpenConns,
+		ConnMaxLifetime: d.ConnMaxLifetime,
+		ConnMaxIdleTime: d.ConnMaxIdleTime,
+		Debug:           d.Debug,
+
+		Replicas:             d.Replicas,
+		ReplicaCheckInterval: d.ReplicaCheckInterval,
+	}
+}
+
+// SlogLevel parses the configured log level
+func (l Log) SlogLevel() (level slog.Level, err error) {
+	err = level.UnmarshalText([]byte(l.Level))
+	return level, err
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting binds a config field to its environment variable and flag
+type setting struct {
+	key   string
+	env   string
+	usage string
+	value flag.Value
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"store", "STORE", "news store backend, postgres, sqlite or memory (kept until exit, no database needed)", (*stringValue)(&c.Store)},
+		{"database.path", "DATABASE_PATH", "sqlite database file, :memory: keeps it in memory", (*stringValue)(&c.Database.Path)},
+		{"database.host", "DATABASE_HOST", "database host", (*stringValue)(&c.Database.Host)},
+		{"database.port", "DATABASE_PORT", "database port", (*stringValue)(&c.Database.Port)},
+		{"database.name", "DATABASE_NAME", "database name", (*stringValue)(&c.Database.Name)},
+		{"database.user", "DATABASE_USER", "database user", (*stringValue)(&c.Database.User)},
+		{"database.password", "DATABASE_PASSWORD", "database password", (*secretValue)(&c.Database.Password)},
+		{"database.sslmode", "DATABASE_SSLMODE", "database ssl mode", (*stringValue)(&c.Database.SSLMode)},
+		{"database.sslrootcert", "DATABASE_SSLROOTCERT", "path to the database root certificate", (*stringValue)(&c.Database.SSLRootCert)},
+		{"database.max_idle_conns", "DATABASE_MAX_IDLE_CONNS", "maximum idle connections in the pool", (*intValue)(&c.Database.MaxIdleConns)},
+		{"database.max_open_conns", "DATABASE_MAX_OPEN_CONNS", "maximum open connections, 0 is unlimited", (*intValue)(&c.Database.MaxOpenConns)},
+		{"database.conn_max_lifetime", "DATABASE_CONN_MAX_LIFETIME", "maximum lifetime of a connection", (*durationValue)(&c.Database.ConnMaxLifetime)},
+		{"database.conn_max_idle_time", "DATABASE_CONN_MAX_IDLE_TIME", "maximum idle time of a connection", (*durationValue)(&c.Database.ConnMaxIdleTime)},
+		{"database.replicas", "DATABASE_REPLICAS", "comma separated connection strings of postgres read replicas", (*listValue)(&c.Database.Replicas)},
+		{"database.replica_check_interval", "DATABASE_REPLICA_CHECK_INTERVAL", "how often replicas are health checked", (*durationValue)(&c.Database.ReplicaCheckInterval)},
+		{"database.read_your_writes", "DATABASE_READ_YOUR_WRITES", "how long a client reads from the primary after a write, 0 disables", (*durationValue)(&c.Database.ReadYourWrites)},
+		{"database.debug", "DATABASE_DEBUG", "log every database query", (*boolValue)(&c.Database.Debug)},
+		{"http.addr", "HTTP_ADDR", "http listen address", (*stringValue)(&c.HTTP.Addr)},
+		{"http.read_header_timeout", "HTTP_READ_HEADER_TIMEOUT", "timeout for reading request headers", (*durationValue)(&c.HTTP.ReadHeaderTimeout)},
+		{"http.read_timeout", "HTTP_READ_TIMEOUT", "timeout for reading the whole request", (*durationValue)(&c.HTTP.ReadTimeout)},
+		{"http.write_timeout", "HTTP_WRITE_TIMEOUT", "timeout for writing the response", (*durationValue)(&c.HTTP.WriteTimeout)},
+		{"http.idle_timeout", "HTTP_IDLE_TIMEOUT", "keep-alive idle timeout", (*durationValue)(&c.HTTP.IdleTimeout)},
+		{"http.shutdown_timeout", "HTTP_SHUTDOWN_TIMEOUT", "graceful shutdown timeout", (*durationValue)(&c.HTTP.ShutdownTimeout)},
+		{"http.max_body_size", "HTTP_MAX_BODY_SIZE", "largest request body in bytes, after decompression too", (*intValue)(&c.HTTP.MaxBodySize)},
+		{"http.validate_requests", "HTTP_VALIDATE_REQUESTS", "reject requests not matching the openapi description", (*boolValue)(&c.HTTP.ValidateRequests)},
+		{"log.level", "LOG_LEVEL", "log level (debug, info, warn, error)", (*stringValue)(&c.Log.Level)},
+		{"auth.mode", "AUTH_MODE", "request authentication, none or a comma separated list of apikey and jwt", (*stringValue)(&c.Auth.Mode)},
+		{"auth.jwt.jwks", "AUTH_JWT_JWKS", "file path or url of the json web key set", (*stringValue)(&c.Auth.JWT.JWKS)},
+		{"auth.jwt.issuer", "AUTH_JWT_ISSUER", "expected iss claim", (*stringValue)(&c.Auth.JWT.Issuer)},
+		{"auth.jwt.audience", "AUTH_JWT_AUDIENCE", "expected aud claim", (*stringValue)(&c.Auth.JWT.Audience)},
+		{"auth.jwt.roles_claim", "AUTH_JWT_ROLES_CLAIM", "claim holding the caller roles", (*stringValue)(&c.Auth.JWT.RolesClaim)},
+		{"auth.jwt.leeway", "AUTH_JWT_LEEWAY", "allowed clock skew for exp and nbf", (*durationValue)(&c.Auth.JWT.Leeway)},
+		{"ratelimit.enabled", "RATELIMIT_ENABLED", "limit requests per client", (*boolValue)(&c.RateLimit.Enabled)},
+		{"ratelimit.read.requests", "RATELIMIT_READ_REQUESTS", "read requests allowed per period", (*intValue)(&c.RateLimit.Read.Requests)},
+		{"ratelimit.read.period", "RATELIMIT_READ_PERIOD", "period of the read limit", (*durationValue)(&c.RateLimit.Read.Period)},
+		{"ratelimit.write.requests", "RATELIMIT_WRITE_REQUESTS", "write requests allowed per period", (*intValue)(&c.RateLimit.Write.Requests)},
+		{"ratelimit.write.period", "RATELIMIT_WRITE_PERIOD", "period of the write limit", (*durationValue)(&c.RateLimit.Write.Period)},
+		{"ratelimit.address.requests", "RATELIMIT_ADDRESS_REQUESTS", "requests of a client address allowed per period, checked before authentication", (*intValue)(&c.RateLimit.Address.Requests)},
+		{"ratelimit.address.period", "RATELIMIT_ADDRESS_PERIOD", "period of the address limit", (*durationValue)(&c.RateLimit.Address.Period)},
+		{"ratelimit.trust_forwarded_for", "RATELIMIT_TRUST_FORWARDED_FOR", "identify anonymous clients by X-Forwarded-For", (*boolValue)(&c.RateLimit.TrustForwardedFor)},
+		{"cors.allowed_origins", "CORS_ALLOWED_ORIGINS", "comma separated origins allowed to call the api, empty disables cors", (*listValue)(&c.CORS.AllowedOrigins)},
+		{"cors.allowed_methods", "CORS_ALLOWED_METHODS", "comma separated methods allowed in cross origin requests", (*listValue)(&c.CORS.AllowedMethods)},
+		{"cors.allowed_headers", "CORS_ALLOWED_HEADERS", "comma separated request headers allowed in cross origin requests", (*listValue)(&c.CORS.AllowedHeaders)},
+		{"cors.exposed_headers", "CORS_EXPOSED_HEADERS", "comma separated response headers readable by browsers", (*listValue)(&c.CORS.ExposedHeaders)},
+		{"cors.allow_credentials", "CORS_ALLOW_CREDENTIALS", "allow cookies and authorization headers in cross origin requests", (*boolValue)(&c.CORS.AllowCredentials)},
+		{"cors.max_age", "CORS_MAX_AGE", "how long browsers may cache a preflight response", (*durationValue)(&c.CORS.MaxAge)},
+		{"cache_control.public", "CACHE_CONTROL_PUBLIC", "Cache-Control of successful anonymous reads", (*stringValue)(&c.CacheControl.Public)},
+		{"cache_control.private", "CACHE_CONTROL_PRIVATE", "Cache-Control of successful authenticated reads", (*stringValue)(&c.CacheControl.Private)},
+		{"cache.enabled", "CACHE_ENABLED", "cache reads in process", (*boolValue)(&c.Cache.Enabled)},
+		{"cache.size", "CACHE_SIZE", "maximum number of cached results", (*intValue)(&c.Cache.Size)},
+		{"cache.ttl", "CACHE_TTL", "how long a cached result is served", (*durationValue)(&c.Cache.TTL)},
+		{"compression.enabled", "COMPRESSION_ENABLED", "compress responses with gzip, br or zstd", (*boolValue)(&c.Compression.Enabled)},
+		{"compression.min_size", "COMPRESSION_MIN_SIZE", "smallest response body in bytes that is compressed", (*intValue)(&c.Compression.MinSize)},
+		{"outbox.sink", "OUTBOX_SINK", "where news change events are published, log, webhook or file", (*stringValue)(&c.Outbox.Sink)},
+		{"outbox.url", "OUTBOX_URL", "url the webhook sink posts the events to", (*stringValue)(&c.Outbox.URL)},
+		{"outbox.path", "OUTBOX_PATH", "file the file sink appends the events to", (*stringValue)(&c.Outbox.Path)},
+		{"outbox.interval", "OUTBOX_INTERVAL", "how often pending events are looked for", (*durationValue)(&c.Outbox.Interval)},
+		{"outbox.batch_size", "OUTBOX_BATCH_SIZE", "events published per round", (*intValue)(&c.Outbox.BatchSize)},
+		{"outbox.max_backoff", "OUTBOX_MAX_BACKOFF", "longest wait between retries of a failing event", (*durationValue)(&c.Outbox.MaxBackoff)},
+		{"outbox.retention", "OUTBOX_RETENTION", "how long published events are kept, 0 keeps them forever", (*durationValue)(&c.Outbox.Retention)},
+		{"webhooks.enabled", "WEBHOOKS_ENABLED", "serve webhook subscriptions and deliver the news events to them", (*boolValue)(&c.Webhooks.Enabled)},
+		{"webhooks.timeout", "WEBHOOKS_TIMEOUT", "timeout of a delivery", (*durationValue)(&c.Webhooks.Timeout)},
+		{"webhooks.max_attempts", "WEBHOOKS_MAX_ATTEMPTS", "attempts of a delivery before it fails", (*intValue)(&c.Webhooks.MaxAttempts)},
+		{"webhooks.disable_after", "WEBHOOKS_DISABLE_AFTER", "failed deliveries in a row disabling a subscription", (*intValue)(&c.Webhooks.DisableAfter)},
+		{"webhooks.max_backoff", "WEBHOOKS_MAX_BACKOFF", "longest wait between attempts of a delivery", (*durationValue)(&c.Webhooks.MaxBackoff)},
+		{"webhooks.allow_private", "WEBHOOKS_ALLOW_PRIVATE", "allow deliveries to loopback and private addresses", (*boolValue)(&c.Webhooks.AllowPrivate)},
+		{"webhooks.retention", "WEBHOOKS_RETENTION", "how long deliveries are kept in the log, 0 keeps them forever", (*durationValue)(&c.Webhooks.Retention)},
+		{"stream.enabled", "STREAM_ENABLED", "serve the live stream of news changes on /news/stream", (*boolValue)(&c.Stream.Enabled)},
+		{"stream.heartbeat", "STREAM_HEARTBEAT", "interval of the heartbeats keeping idle streams open", (*durationValue)(&c.Stream.Heartbeat)},
+		{"stream.replay", "STREAM_REPLAY", "recent events kept for clients resuming the stream", (*intValue)(&c.Stream.Replay)},
+		{"legacy.enabled", "LEGACY_ENABLED", "serve the unversioned paths as deprecated aliases of the /v1 routes", (*boolValue)(&c.Legacy.Enabled)},
+		{"legacy.deprecation", "LEGACY_DEPRECATION", "when the unversioned paths were deprecated (RFC 3339 or yyyy-mm-dd)", (*timeValue)(&c.Legacy.Deprecation)},
+		{"legacy.sunset", "LEGACY_SUNSET", "when the unversioned paths stop being served (RFC 3339 or yyyy-mm-dd)", (*timeValue)(&c.Legacy.Sunset)},
+	}
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(s)
+	return nil
+}
+
+func (v *stringValue) String() string { return string(*v) }
+
+// secretValue hides its content from flag usage output
+type secretValue string
+
+func (v *secretValue) Set(s string) error {
+	*v = secretValue(s)
+	return nil
+}
+
+func (v *secretValue) String() string {
+	if *v == "" {
+		return ""
+	}
+	return "****"
+}
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = intValue(i)
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+// timeValue is an RFC 3339 time or a yyyy-mm-dd date at midnight UTC
+type timeValue time.Time
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, s); err != nil {
+			return err
+		}
+	}
+	*v = timeValue(t)
+	return nil
+}
+
+func (v *timeValue) String() string {
+	if time.Time(*v).IsZero() {
+		return ""
+	}
+	return time.Time(*v).Format(time.RFC3339)
+}
+
+// listValue is a comma separated list, an empty string yields an empty list
+type listValue []string
+
+func (v *listValue) Set(s string) error {
+	*v = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*v = append(*v, item)
+		}
+	}
+	return nil
+}
+
+func (v *listValue) String() string { return strings.Join(*v, ",") }
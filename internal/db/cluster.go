@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/uptrace/bun"
+)
+
+type primaryKey struct{}
+
+// WithPrimary marks the context so that reads made with it go to the primary
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsesPrimary reports whether reads made with the context go to the primary
+func UsesPrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+type replica struct {
+	db      *bun.DB
+	name    string
+	healthy atomic.Bool
+}
+
+// Cluster is a primary with read replicas. Reads are spread round-robin over
+// the replicas that passed their last health check, and go to the primary
+// when none did or when the context asks for it with WithPrimary.
+type Cluster struct {
+	primary  *bun.DB
+	replicas []*replica
+	next     atomic.Uint64
+	interval time.Duration
+}
+
+// OpenCluster opens the primary and every replica of c.Replicas with the pool
+// settings of c
+func OpenCluster(c *Config) (*Cluster, error) {
+	if len(c.Replicas) != 0 && c.Dialect != Postgres && c.Dialect != "" {
+		return nil, fmt.Errorf("replicas are only supported on %s", Postgres)
+	}
+	primary, err := NewDB(c)
+	if err != nil {
+		return nil, err
+	}
+	var replicas []*bun.DB
+	for i, dsn := range c.Replicas {
+		rc := *c
+		rc.DSN = dsn
+		replica, err := NewDB(&rc)
+		if err != nil {
+			NewCluster(c.ReplicaCheckInterval, primary, replicas...).Close()
+			return nil, fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, replica)
+	}
+	return NewCluster(c.ReplicaCheckInterval, primary, replicas...), nil
+}
+
+// NewCluster routes the reads over replicas health checked every interval,
+// 5 seconds when it is not positive. Replicas take no reads until Run has
+// checked them.
+func NewCluster(interval time.Duration, primary *bun.DB, replicas ...*bun.DB) *Cluster {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	c := &Cluster{
+		primary:  primary,
+		interval: interval,
+	}
+	for i, db := range replicas {
+		c.replicas = append(c.replicas, &replica{db: db, name: fmt.Sprintf("replica-%d", i+1)})
+	}
+	return c
+}
+
+// Primary returns the database taking the writes
+func (c *Cluster) Primary() *bun.DB {
+	return c.primary
+}
+
+// Reader returns the database serving a read made with ctx
+func (c *Cluster) Reader(ctx context.Context) bun.IDB {
+	if len(c.replicas) == 0 || UsesPrimary(ctx) {
+		return c.primary
+	}
+	healthy := make([]*bun.DB, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r.db)
+		}
+	}
+	if len(healthy) == 0 {
+		return c.primary
+	}
+	return healthy[(c.next.Add(1)-1)%uint64(len(healthy))]
+}
+
+// Run checks the replicas at once and then every check interval, until ctx
+// is done. Health changes are logged with the logger of ctx.
+func (c *Cluster) Run(ctx context.Context) error {
+	if len(c.replicas) == 0 {
+		return nil
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.check(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check pings every replica, a replica is healthy when it answers within the check interval
+func (c *Cluster) check(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	for _, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, c.interval)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.Info("replica is healthy", "replica", r.name)
+			} else {
+				log.Warn("replica is unhealthy, reads fall back to other replicas or the primary", "replica", r.name, "error", err)
+			}
+		}
+	}
+}
+
+// Close closes the primary and every replica
+func (c *Cluster) Close() error {
+	errs := c.primary.Close()
+	for _, r := range c.replicas {
+		errs = errors.Join(errs, r.db.Close())
+	}
+	return errs
+}
@@ -0,0 +1,121 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+)
+
+// runChecks runs the health checks of the cluster until the test ends
+func runChecks(tb testing.TB, cluster *db.Cluster) {
+	tb.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- cluster.Run(ctx) }()
+	tb.Cleanup(func() {
+		cancel()
+		assert.NoError(tb, <-done)
+	})
+}
+
+func TestCluster_Reader(t *testing.T) {
+	primary := setupSQLite(t, ":memory:")
+	replicaA := setupSQLite(t, ":memory:")
+	replicaB := setupSQLite(t, ":memory:")
+	down := setupSQLite(t, ":memory:")
+	require.NoError(t, down.Close())
+
+	t.Run("read_from_primary_without_replicas", func(t *testing.T) {
+		cluster := db.NewCluster(time.Millisecond, primary)
+		assert.Same(t, primary, cluster.Reader(context.Background()))
+	})
+
+	t.Run("read_from_primary_until_replicas_are_checked", func(t *testing.T) {
+		cluster := db.NewCluster(time.Hour, primary, replicaA)
+		assert.Same(t, primary, cluster.Reader(context.Background()))
+	})
+
+	cluster := db.NewCluster(10*time.Millisecond, primary, replicaA, down, replicaB)
+	runChecks(t, cluster)
+	require.Eventually(t, func() bool {
+		a, b := cluster.Reader(context.Background()), cluster.Reader(context.Background())
+		return a != b && a != bun.IDB(primary) && b != bun.IDB(primary)
+	}, time.Second, time.Millisecond)
+
+	t.Run("round_robin_over_healthy_replicas", func(t *testing.T) {
+		seen := map[bun.IDB]int{}
+		for range 10 {
+			seen[cluster.Reader(context.Background())]++
+		}
+		assert.Len(t, seen, 2)
+		assert.Equal(t, 5, seen[replicaA])
+		assert.Equal(t, 5, seen[replicaB])
+	})
+
+	t.Run("read_from_primary_when_asked", func(t *testing.T) {
+		assert.Same(t, primary, cluster.Reader(db.WithPrimary(context.Background())))
+	})
+}
+
+func TestCluster_NewsStore(t *testing.T) {
+	primary := setupSQLite(t, ":memory:")
+	replica := setupSQLite(t, ":memory:")
+	cluster := db.NewCluster(10*time.Millisecond, primary, replica)
+	runChecks(t, cluster)
+	require.Eventually(t, func() bool {
+		return cluster.Reader(context.Background()) == bun.IDB(replica)
+	}, time.Second, time.Millisecond)
+	s := news.NewStore(cluster.Primary(), news.WithReader(cluster))
+
+	created, err := s.Create(context.Background(), storetest.Record("Batman", time.Time{}, "marvel"))
+	require.NoError(t, err)
+
+	// nothing replicates between the two databases, so reads show where they went
+	_, err = s.FindById(context.Background(), created.Id)
+	assert.Error(t, err, "reads go to the replica")
+	got, err := s.FindById(db.WithPrimary(context.Background()), created.Id)
+	assert.NoError(t, err, "reads asking for the primary see the write")
+	assert.Equal(t, created.Id, got.Id)
+	stats, err := s.Stats(db.WithPrimary(context.Background()), news.Filter{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.Count)
+}
+
+func TestOpenCluster(t *testing.T) {
+	_, err := db.OpenCluster(&db.Config{Dialect: db.SQLite, Path: ":memory:", Replicas: []string{"host=replica"}})
+	assert.ErrorContains(t, err, "replicas are only supported on postgres")
+
+	cluster, err := db.OpenCluster(&db.Config{DbHost: "primary", DbPort: "5432", SSLMode: "disable", Replicas: []string{"postgres://news@replica-1/news", "host=replica-2 dbname=news"}})
+	require.NoError(t, err, "connections are opened lazily")
+	assert.Same(t, cluster.Primary(), cluster.Reader(context.Background()))
+	assert.NoError(t, cluster.Close())
+}
+
+func TestSticky(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("pin_writers_for_the_window", func(t *testing.T) {
+		sticky := db.NewSticky(5*time.Second, db.WithClock(clock))
+		assert.False(t, sticky.Pinned("sub:alice"))
+		sticky.Wrote("sub:alice")
+		assert.True(t, sticky.Pinned("sub:alice"))
+		assert.False(t, sticky.Pinned("sub:bob"))
+
+		now = now.Add(5 * time.Second)
+		assert.False(t, sticky.Pinned("sub:alice"))
+	})
+
+	t.Run("never_pin_without_window", func(t *testing.T) {
+		sticky := db.NewSticky(0, db.WithClock(clock))
+		sticky.Wrote("sub:alice")
+		assert.False(t, sticky.Pinned("sub:alice"))
+	})
+}
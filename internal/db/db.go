@@ -0,0 +1,153 @@
+// Package db opens the bun database shared by the news and api key stores,
+// on postgres or sqlite.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"github.com/uptrace/bun/extra/bundebug"
+)
+
+// Supported dialects
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+type Config struct {
+	// Dialect is Postgres, the default, or SQLite
+	Dialect string
+	// Path is the sqlite database file, :memory: keeps the database in memory
+	Path            string
+	DbHost          string
+	DbPort          string
+	DbName          string
+	UserName        string
+	Password        string
+	SSLMode         string
+	SSLRootCert     string
+	MaxIdleConn     int
+	MaxOpenConn     int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	Debug           bool
+
+	// DSN is a postgres connection string used instead of the Db fields
+	DSN string
+	// Replicas are the connection strings of the postgres read replicas of OpenCluster
+	Replicas []string
+	// ReplicaCheckInterval is how often the replicas of OpenCluster are health checked
+	ReplicaCheckInterval time.Duration
+}
+
+func (c *Config) getDsn() string {
+	if c.DSN != "" {
+		return c.DSN
+	}
+	dsn := fmt.Sprintf("dbname=%s host=%s port=%s user=%s password=%s sslmode=%s",
+		quoteDsnValue(c.DbName),
+		quoteDsnValue(c.DbHost),
+		quoteDsnValue(c.DbPort),
+		quoteDsnValue(c.UserName),
+		quoteDsnValue(c.Password),
+		quoteDsnValue(c.SSLMode),
+	)
+	if c.SSLRootCert != "" {
+		dsn += fmt.Sprintf(" sslrootcert=%s", quoteDsnValue(c.SSLRootCert))
+	}
+	return dsn
+}
+
+// dsnEscaper escapes the characters ending or escaping a quoted value
+var dsnEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// quoteDsnValue quotes a keyword/value connection string value, which may
+// then hold spaces, quotes and backslashes or be empty
+func quoteDsnValue(v string) string {
+	return "'" + dsnEscaper.Replace(v) + "'"
+}
+
+func NewDB(c *Config) (*bun.DB, error) {
+	var (
+		db  *bun.DB
+		err error
+	)
+	switch c.Dialect {
+	case Postgres, "":
+		db, err = newPostgres(c)
+	case SQLite:
+		db, err = newSQLite(c)
+	default:
+		return nil, fmt.Errorf("unknown dialect %q", c.Dialect)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.Debug {
+		db.AddQueryHook(bundebug.NewQueryHook(bundebug.WithVerbose(true)))
+	}
+	return db, nil
+}
+
+// ConnConfig returns the settings of a single postgres connection, for uses
+// the pool does not serve such as listening to notifications
+func (c *Config) ConnConfig() (*pgx.ConnConfig, error) {
+	return pgx.ParseConfig(c.getDsn())
+}
+
+func newPostgres(c *Config) (*bun.DB, error) {
+	pgConfig, err := c.ConnConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB := stdlib.OpenDB(*pgConfig)
+	sqlDB.SetMaxIdleConns(c.MaxIdleConn)
+	sqlDB.SetMaxOpenConns(c.MaxOpenConn)
+	sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+
+	return bun.NewDB(sqlDB, pgdialect.New()), nil
+}
+
+// sqlitePragmas apply to the connection they run on
+var sqlitePragmas = []string{
+	"PRAGMA journal_mode = WAL",
+	"PRAGMA busy_timeout = 5000",
+	"PRAGMA foreign_keys = ON",
+}
+
+// newSQLite opens the database on a single connection that is never closed:
+// sqlite has one writer at a time, the pragmas hold for that connection only
+// and an in-memory database lives as long as it does. Queries must not keep
+// it busy between rows, news.Store.Stream reads its page first for that reason.
+// The pool settings of the config are ignored.
+func newSQLite(c *Config) (*bun.DB, error) {
+	if c.Path == "" {
+		return nil, fmt.Errorf("sqlite database path is empty")
+	}
+	sqlDB, err := sql.Open(sqliteshim.ShimName, c.Path)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+	for _, pragma := range sqlitePragmas {
+		if _, err := sqlDB.Exec(pragma); err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("%s: %w", pragma, err)
+		}
+	}
+	return bun.NewDB(sqlDB, sqlitedialect.New()), nil
+}
@@ -0,0 +1,122 @@
+package db_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/migration"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/migrate"
+)
+
+// setupSQLite opens a migrated sqlite database
+func setupSQLite(tb testing.TB, path string) *bun.DB {
+	tb.Helper()
+	ctx := context.Background()
+	sqlite, err := db.NewDB(&db.Config{Dialect: db.SQLite, Path: path})
+	require.NoError(tb, err)
+	tb.Cleanup(func() { sqlite.Close() })
+
+	m := migrate.NewMigrator(sqlite, migration.New(dialect.SQLite), migrate.WithMarkAppliedOnSuccess(true))
+	require.NoError(tb, m.Init(ctx))
+	_, err = m.Migrate(ctx)
+	require.NoError(tb, err)
+	return sqlite
+}
+
+func TestNewDB_SQLiteNewsStore(t *testing.T) {
+	sqlite := setupSQLite(t, ":memory:")
+	storetest.Run(t, func(t *testing.T) handler.NewsStorer {
+		_, err := sqlite.NewTruncateTable().Model((*news.Record)(nil)).Exec(context.Background())
+		require.NoError(t, err)
+		return news.NewStore(sqlite)
+	})
+}
+
+func TestNewDB_SQLiteMigrations(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "news.db")
+	sqlite := setupSQLite(t, path)
+	m := migrate.NewMigrator(sqlite, migration.New(dialect.SQLite))
+
+	for {
+		group, err := m.Rollback(ctx)
+		require.NoError(t, err)
+		if group.IsZero() {
+			break
+		}
+	}
+	var tables []string
+	require.NoError(t, sqlite.NewSelect().Table("sqlite_master").Column("name").
+		Where("type = 'table' AND name IN ('news', 'api_keys', 'outbox', 'webhook_subscriptions', 'webhook_deliveries')").Scan(ctx, &tables))
+	assert.Empty(t, tables, "down migrations drop every table")
+}
+
+func TestNewDB(t *testing.T) {
+	testcases := []struct {
+		name            string
+		config          db.Config
+		expectedDialect dialect.Name
+		expectedErr     string
+	}{
+		{
+			name:            "default_to_postgres",
+			config:          db.Config{DbHost: "localhost", DbPort: "5432", DbName: "news", UserName: "postgres", SSLMode: "disable"},
+			expectedDialect: dialect.PG,
+		},
+		{
+			name:            "open_sqlite",
+			config:          db.Config{Dialect: db.SQLite, Path: ":memory:"},
+			expectedDialect: dialect.SQLite,
+		},
+		{
+			name:        "return_error_for_empty_sqlite_path",
+			config:      db.Config{Dialect: db.SQLite},
+			expectedErr: "path is empty",
+		},
+		{
+			name:        "return_error_for_unknown_dialect",
+			config:      db.Config{Dialect: "mysql"},
+			expectedErr: `unknown dialect "mysql"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := db.NewDB(&tc.config)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer got.Close()
+			assert.Equal(t, tc.expectedDialect, got.Dialect().Name())
+		})
+	}
+}
+
+func TestConfig_ConnConfig(t *testing.T) {
+	cfg := db.Config{
+		DbHost:   "localhost",
+		DbPort:   "5432",
+		DbName:   "news room",
+		UserName: "postgres",
+		Password: `pa ss'wo\rd sslmode=disable`,
+		SSLMode:  "require",
+	}
+
+	got, err := cfg.ConnConfig()
+	require.NoError(t, err)
+	assert.Equal(t, `pa ss'wo\rd sslmode=disable`, got.Password)
+	assert.Equal(t, "news room", got.Database)
+	assert.Equal(t, uint16(5432), got.Port)
+	assert.NotNil(t, got.TLSConfig, "the password does not override sslmode")
+}
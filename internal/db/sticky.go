@@ -0,0 +1,73 @@
+package db
+
+import (
+	"sync"
+	"time"
+)
+
+// Sticky remembers the clients that wrote recently, so that their reads stay
+// on the primary until the replicas have caught up with their writes
+type Sticky struct {
+	m         sync.Mutex
+	window    time.Duration
+	until     map[string]time.Time
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// StickyOption configures the Sticky
+type StickyOption func(*Sticky)
+
+// WithClock replaces time.Now, it is meant for tests
+func WithClock(now func() time.Time) StickyOption {
+	return func(s *Sticky) {
+		s.now = now
+	}
+}
+
+// NewSticky keeps a client on the primary for window after its last write
+func NewSticky(window time.Duration, opts ...StickyOption) *Sticky {
+	s := &Sticky{
+		window: window,
+		until:  map[string]time.Time{},
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.lastSweep = s.now()
+	return s
+}
+
+// Wrote records a write by the client
+func (s *Sticky) Wrote(client string) {
+	if s.window <= 0 {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	now := s.now()
+	s.sweep(now)
+	s.until[client] = now.Add(s.window)
+}
+
+// Pinned reports whether the client wrote within the window
+func (s *Sticky) Pinned(client string) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+	until, ok := s.until[client]
+	return ok && s.now().Before(until)
+}
+
+// sweep drops the expired clients once per window
+func (s *Sticky) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.window {
+		return
+	}
+	s.lastSweep = now
+	for client, until := range s.until {
+		if !now.Before(until) {
+			delete(s.until, client)
+		}
+	}
+}
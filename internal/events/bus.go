@@ -0,0 +1,162 @@
+// Package events fans the news changes out to live subscribers, keeping the
+// latest ones so that reconnecting subscribers can resume where they left.
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+)
+
+// Event is a change of a news record. The changes notified by postgres are
+// identified by the news_change_seq of the notification, which every
+// api-server receives in the same order, so that a subscriber may resume on
+// any of them. The changes published by the api-server itself are identified
+// by "<bus epoch>-<sequence>", so that ids of a restarted server are told
+// apart from the previous ones.
+type Event struct {
+	Id     string
+	Type   string
+	Record news.Record
+}
+
+// Bus publishes events to its subscribers and keeps the latest ones for replay
+type Bus struct {
+	m     sync.Mutex
+	epoch string
+	seq   uint64
+	// last is the id of the latest event
+	last   string
+	replay []Event
+	size   int
+	subs   map[*Subscription]struct{}
+	closed bool
+	// buffer is the number of events a subscriber may lag behind before it is dropped
+	buffer int
+}
+
+// NewBus returns a bus replaying up to size events
+func NewBus(size int) *Bus {
+	return &Bus{
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		size:   size,
+		replay: make([]Event, 0, size),
+		subs:   map[*Subscription]struct{}{},
+		buffer: 64,
+	}
+}
+
+// Subscription receives the events published after it was made on C, which
+// is closed when the bus closes or the subscriber fell too far behind
+type Subscription struct {
+	C   <-chan Event
+	c   chan Event
+	bus *Bus
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.bus.m.Lock()
+	defer s.bus.m.Unlock()
+	s.bus.drop(s)
+}
+
+// Publish sends an event to every subscriber without waiting for them.
+// Subscribers whose buffer is full are dropped, to resume with Last-Event-ID.
+func (b *Bus) Publish(typ string, record news.Record) Event {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.seq++
+	return b.publish(Event{Id: fmt.Sprintf("%s-%d", b.epoch, b.seq), Type: typ, Record: record})
+}
+
+// PublishWithId publishes an event identified by id, which should be shared
+// by every bus receiving the event so that subscribers resume on any of them
+func (b *Bus) PublishWithId(id, typ string, record news.Record) Event {
+	b.m.Lock()
+	defer b.m.Unlock()
+	return b.publish(Event{Id: id, Type: typ, Record: record})
+}
+
+// Reset forgets the published events, for when some were missed: subscribers
+// resuming from before are then told to reload the news
+func (b *Bus) Reset() {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.last = ""
+	b.replay = make([]Event, 0, b.size)
+}
+
+// publish buffers and sends event, b.m must be held
+func (b *Bus) publish(event Event) Event {
+	b.last = event.Id
+	if b.size > 0 {
+		if len(b.replay) == b.size {
+			b.replay = append(b.replay[:0], b.replay[1:]...)
+		}
+		b.replay = append(b.replay, event)
+	}
+	for s := range b.subs {
+		select {
+		case s.c <- event:
+		default:
+			b.drop(s)
+		}
+	}
+	return event
+}
+
+// Subscribe returns a subscription along with the buffered events following
+// lastId, the id of the last event the subscriber received, if any. complete
+// is false, and nothing is replayed, when the events since lastId cannot all
+// be replayed because they were evicted, missed or lastId is unknown to the bus; the
+// subscriber should then reload the news. The subscription is nil when the
+// bus is closed.
+func (b *Bus) Subscribe(lastId string) (sub *Subscription, replay []Event, complete bool) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if b.closed {
+		return nil, nil, false
+	}
+	c := make(chan Event, b.buffer)
+	sub = &Subscription{C: c, c: c, bus: b}
+	b.subs[sub] = struct{}{}
+	if lastId == "" {
+		return sub, nil, true
+	}
+
+	if lastId == b.last {
+		return sub, nil, true
+	}
+	// events are replayed in the order they were published, which is not the
+	// order of the notified ids when concurrent transactions commit
+	for i := len(b.replay) - 1; i >= 0; i-- {
+		if b.replay[i].Id == lastId {
+			return sub, slices.Clone(b.replay[i+1:]), true
+		}
+	}
+	return sub, nil, false
+}
+
+// Close ends every subscription and refuses new ones, it is meant to run on
+// server shutdown so that streaming handlers return
+func (b *Bus) Close() {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		b.drop(s)
+	}
+}
+
+// drop ends a subscription, b.m must be held
+func (b *Bus) drop(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
@@ -0,0 +1,147 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func titles(events []events.Event) []string {
+	var titles []string
+	for _, e := range events {
+		titles = append(titles, e.Record.Title)
+	}
+	return titles
+}
+
+func TestBus_Publish(t *testing.T) {
+	bus := events.NewBus(10)
+	sub, replay, complete := bus.Subscribe("")
+	require.NotNil(t, sub)
+	defer sub.Close()
+	assert.Empty(t, replay)
+	assert.True(t, complete)
+
+	first := bus.Publish(outbox.NewsCreated, news.Record{Title: "Batman"})
+	second := bus.Publish(outbox.NewsUpdated, news.Record{Title: "Robin"})
+	assert.NotEqual(t, first.Id, second.Id)
+
+	assert.Equal(t, first, <-sub.C)
+	assert.Equal(t, second, <-sub.C)
+}
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := events.NewBus(2)
+	first := bus.Publish(outbox.NewsCreated, news.Record{Title: "Batman"})
+	second := bus.Publish(outbox.NewsCreated, news.Record{Title: "Robin"})
+	third := bus.Publish(outbox.NewsCreated, news.Record{Title: "Joker"})
+
+	testcases := []struct {
+		name             string
+		lastId           string
+		expectedReplay   []string
+		expectedComplete bool
+	}{
+		{
+			name:             "replay_buffered_events",
+			lastId:           second.Id,
+			expectedReplay:   []string{"Joker"},
+			expectedComplete: true,
+		},
+		{
+			name:             "replay_nothing_when_up_to_date",
+			lastId:           third.Id,
+			expectedComplete: true,
+		},
+		{
+			name:   "incomplete_after_eviction",
+			lastId: first.Id,
+		},
+		{
+			name:   "incomplete_for_another_bus",
+			lastId: "abc-2",
+		},
+		{
+			name:   "incomplete_for_unknown_id",
+			lastId: "not-an-id",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, replay, complete := bus.Subscribe(tc.lastId)
+			require.NotNil(t, sub)
+			defer sub.Close()
+			assert.Equal(t, tc.expectedReplay, titles(replay))
+			assert.Equal(t, tc.expectedComplete, complete)
+		})
+	}
+}
+
+func TestBus_PublishWithId(t *testing.T) {
+	// api-servers receive the notified changes in commit order, which is not
+	// the order of their ids when concurrent transactions commit
+	servers := []*events.Bus{events.NewBus(10), events.NewBus(10)}
+	for _, bus := range servers {
+		bus.PublishWithId("7", outbox.NewsCreated, news.Record{Title: "Batman"})
+		bus.PublishWithId("9", outbox.NewsCreated, news.Record{Title: "Robin"})
+		bus.PublishWithId("8", outbox.NewsCreated, news.Record{Title: "Joker"})
+	}
+
+	sub, replay, complete := servers[1].Subscribe("9")
+	require.NotNil(t, sub)
+	defer sub.Close()
+	assert.True(t, complete, "the id of another api-server is resumed from")
+	assert.Equal(t, []string{"Joker"}, titles(replay))
+}
+
+func TestBus_Reset(t *testing.T) {
+	bus := events.NewBus(10)
+	bus.PublishWithId("1", outbox.NewsCreated, news.Record{Title: "Batman"})
+	bus.Reset()
+	bus.PublishWithId("3", outbox.NewsCreated, news.Record{Title: "Robin"})
+
+	for _, lastId := range []string{"1", ""} {
+		sub, replay, complete := bus.Subscribe(lastId)
+		require.NotNil(t, sub)
+		sub.Close()
+		assert.Empty(t, replay)
+		assert.Equal(t, lastId == "", complete, "events may have been missed since %q", lastId)
+	}
+}
+
+func TestBus_DropSlowSubscriber(t *testing.T) {
+	bus := events.NewBus(0)
+	slow, _, _ := bus.Subscribe("")
+	fast, _, _ := bus.Subscribe("")
+	defer fast.Close()
+
+	for range 100 {
+		bus.Publish(outbox.NewsCreated, news.Record{Title: "Batman"})
+		<-fast.C
+	}
+
+	var received int
+	for range slow.C {
+		received++
+	}
+	assert.Less(t, received, 100, "the slow subscriber is dropped once its buffer is full")
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe("")
+	bus.Close()
+
+	_, ok := <-sub.C
+	assert.False(t, ok)
+	sub.Close()
+
+	sub, _, _ = bus.Subscribe("")
+	assert.Nil(t, sub)
+	bus.Publish(outbox.NewsCreated, news.Record{Title: "Batman"})
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/uptrace/bun"
+)
+
+// Channel is the postgres channel the news_notify trigger notifies the news
+// changes on
+const Channel = "news_changes"
+
+// notification is the payload of the news_notify trigger, Seq being the
+// news_change_seq value identifying the change on every api-server
+type notification struct {
+	Seq  int64     `json:"seq"`
+	Type string    `json:"type"`
+	Id   uuid.UUID `json:"id"`
+}
+
+// Listener listens to the news changes notified by postgres, so that every
+// api-server learns of the changes made through any of them. It publishes
+// them on a bus and drops caches.
+type Listener struct {
+	config     *pgx.ConnConfig
+	db         bun.IDB
+	bus        *Bus
+	invalidate []func()
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures the Listener
+type Option func(*Listener)
+
+// WithBus publishes the changes on bus, with the record read back from the database
+func WithBus(bus *Bus) Option {
+	return func(l *Listener) {
+		l.bus = bus
+	}
+}
+
+// WithInvalidate calls invalidate on every change, and whenever changes may
+// have been missed while reconnecting
+func WithInvalidate(invalidate func()) Option {
+	return func(l *Listener) {
+		l.invalidate = append(l.invalidate, invalidate)
+	}
+}
+
+// WithBackoff sets the first wait before reconnecting, doubling up to max
+func WithBackoff(base, max time.Duration) Option {
+	return func(l *Listener) {
+		l.minBackoff = base
+		l.maxBackoff = max
+	}
+}
+
+// NewListener listens with a connection of config and reads the changed
+// records from db, which should be the primary. It reconnects after 1 second
+// by default, doubling up to 1 minute.
+func NewListener(config *pgx.ConnConfig, db bun.IDB, opts ...Option) *Listener {
+	l := &Listener{
+		config:     config,
+		db:         db,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Run listens until ctx is done, reconnecting whenever the connection is
+// lost. Changes made while disconnected are not published.
+func (l *Listener) Run(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	backoff := l.minBackoff
+	for {
+		err := l.listen(ctx, func() {
+			backoff = l.minBackoff
+			log.Info("listening to news changes", "channel", Channel)
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Warn("news change listener disconnected, reconnecting", "error", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, l.maxBackoff)
+	}
+}
+
+// listen handles the notifications of one connection until it fails,
+// calling listening once it receives them
+func (l *Listener) listen(ctx context.Context, listening func()) error {
+	conn, err := pgx.ConnectConfig(ctx, l.config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		conn.Close(closeCtx)
+	}()
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+	listening()
+	// the caches may hold changes made while not listening, which the bus missed
+	l.drop()
+	if l.bus != nil {
+		l.bus.Reset()
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		l.handle(ctx, n.Payload)
+	}
+}
+
+// handle drops the caches and publishes the change notified with payload
+func (l *Listener) handle(ctx context.Context, payload string) {
+	log := logger.FromContext(ctx)
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		log.Error("invalid news change notification", "payload", payload, "error", err)
+		return
+	}
+	l.drop()
+	if l.bus == nil {
+		return
+	}
+
+	// the record may have changed again since, it is published as it is now
+	var record news.Record
+	err := l.db.NewSelect().Model(&record).WhereAllWithDeleted().Where("id = ?", n.Id).Scan(ctx)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && n.Type == outbox.NewsDeleted:
+		// deleted for good, only its id is left
+		record = news.Record{Id: n.Id, DeletedAt: time.Now().UTC()}
+	case errors.Is(err, sql.ErrNoRows):
+		return
+	case err != nil:
+		log.Error("failed reading changed news", "id", n.Id, "error", err)
+		return
+	}
+	l.bus.PublishWithId(strconv.FormatInt(n.Seq, 10), n.Type, record)
+}
+
+func (l *Listener) drop() {
+	for _, invalidate := range l.invalidate {
+		invalidate()
+	}
+}
@@ -0,0 +1,155 @@
+package events_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/migration"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/logeshwarann-dev/news-api-rest/internal/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	pgtc "github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/migrate"
+)
+
+// setupPostgres starts a migrated postgres, skipping the test without Docker
+func setupPostgres(t *testing.T) *db.Config {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+	ctx := context.Background()
+	ctr, err := pgtc.Run(
+		ctx,
+		"postgres:16-alpine",
+		pgtc.WithDatabase("postgres"),
+		pgtc.WithUsername("postgres"),
+		pgtc.WithPassword("postgres"),
+		testcontainers.WithWaitStrategy(
+			wait.ForListeningPort("5432/tcp").
+				WithStartupTimeout(30*time.Second),
+		),
+	)
+	testcontainers.CleanupContainer(t, ctr)
+	require.NoError(t, err)
+	dsn, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	require.NoError(t, err)
+
+	cfg := &db.Config{Dialect: db.Postgres, DSN: dsn}
+	pg, err := db.NewDB(cfg)
+	require.NoError(t, err)
+	defer pg.Close()
+	m := migrate.NewMigrator(pg, migration.New(dialect.PG), migrate.WithMarkAppliedOnSuccess(true))
+	require.NoError(t, m.Init(ctx))
+	_, err = m.Migrate(ctx)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestListener(t *testing.T) {
+	cfg := setupPostgres(t)
+	pg, err := db.NewDB(cfg)
+	require.NoError(t, err)
+	defer pg.Close()
+	connConfig, err := cfg.ConnConfig()
+	require.NoError(t, err)
+
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe("")
+	defer sub.Close()
+	var invalidations atomic.Int32
+	listener := events.NewListener(connConfig, pg,
+		events.WithBus(bus),
+		events.WithInvalidate(func() { invalidations.Add(1) }),
+		events.WithBackoff(10*time.Millisecond, 100*time.Millisecond),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- listener.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+	// the caches are dropped once listening
+	require.Eventually(t, func() bool { return invalidations.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	receive := func(tb testing.TB) events.Event {
+		tb.Helper()
+		select {
+		case event := <-sub.C:
+			return event
+		case <-time.After(5 * time.Second):
+			tb.Fatal("no event received")
+			return events.Event{}
+		}
+	}
+
+	s := news.NewStore(pg)
+	created, err := s.Create(ctx, storetest.Record("Batman", time.Time{}, "marvel"))
+	require.NoError(t, err)
+	event := receive(t)
+	assert.Equal(t, outbox.NewsCreated, event.Type)
+	assert.Equal(t, created.Id, event.Record.Id)
+	assert.Equal(t, "Batman", event.Record.Author)
+
+	created.Title = "Robin"
+	require.NoError(t, s.UpdateById(ctx, created.Id, created))
+	event = receive(t)
+	assert.Equal(t, outbox.NewsUpdated, event.Type)
+	assert.Equal(t, "Robin", event.Record.Title)
+
+	require.NoError(t, s.DeleteById(ctx, created.Id))
+	beforeLoss := receive(t)
+	event = beforeLoss
+	assert.Equal(t, outbox.NewsDeleted, event.Type)
+	assert.Equal(t, created.Id, event.Record.Id)
+	assert.False(t, event.Record.DeletedAt.IsZero())
+	assert.Equal(t, int32(4), invalidations.Load())
+
+	t.Run("reconnect_after_connection_loss", func(t *testing.T) {
+		_, err := pg.ExecContext(ctx, "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE query LIKE 'LISTEN%'")
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return invalidations.Load() == 5 }, 5*time.Second, 10*time.Millisecond)
+
+		_, err = s.Create(ctx, storetest.Record("Joker", time.Time{}))
+		require.NoError(t, err)
+		assert.Equal(t, "Joker", receive(t).Record.Author)
+		resumed, _, complete := bus.Subscribe(beforeLoss.Id)
+		defer resumed.Close()
+		assert.False(t, complete, "changes may have been missed while reconnecting")
+	})
+
+	t.Run("resume_on_another_api_server", func(t *testing.T) {
+		other := events.NewBus(10)
+		var listening atomic.Bool
+		go events.NewListener(connConfig, pg,
+			events.WithBus(other),
+			events.WithInvalidate(func() { listening.Store(true) }),
+		).Run(ctx)
+		require.Eventually(t, listening.Load, 5*time.Second, 10*time.Millisecond)
+
+		_, err := s.Create(ctx, storetest.Record("Penguin", time.Time{}))
+		require.NoError(t, err)
+		_, err = s.Create(ctx, storetest.Record("Riddler", time.Time{}))
+		require.NoError(t, err)
+		last := receive(t)
+		assert.Equal(t, "Penguin", last.Record.Author)
+		receive(t)
+
+		var replay []events.Event
+		require.Eventually(t, func() bool {
+			resumed, missed, complete := other.Subscribe(last.Id)
+			defer resumed.Close()
+			replay = missed
+			return complete && len(missed) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, "Riddler", replay[0].Record.Author)
+	})
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+)
+
+// newsStore is the handler.NewsStorer wrapped by Store, declared here since
+// the handlers depend on this package for the bus
+type newsStore interface {
+	Create(context.Context, news.Record) (news.Record, error)
+	FindAll(context.Context, news.Filter) ([]news.Record, error)
+	Stream(context.Context, news.Filter) news.Records
+	Stats(context.Context, news.Filter) (news.Stats, error)
+	FindById(context.Context, uuid.UUID) (news.Record, error)
+	UpdateById(context.Context, uuid.UUID, news.Record) error
+	UpdateByIdIfUnchanged(context.Context, uuid.UUID, time.Time, news.Record) error
+	DeleteById(context.Context, uuid.UUID) error
+}
+
+// Store publishes on a bus the changes made through the wrapped store. It
+// must wrap the store itself rather than a cache, so that the published
+// records are read back from it.
+type Store struct {
+	newsStore
+	bus *Bus
+}
+
+func NewStore(next newsStore, bus *Bus) *Store {
+	return &Store{newsStore: next, bus: bus}
+}
+
+func (s *Store) Create(ctx context.Context, record news.Record) (news.Record, error) {
+	created, err := s.newsStore.Create(ctx, record)
+	if err == nil {
+		s.bus.Publish(outbox.NewsCreated, created)
+	}
+	return created, err
+}
+
+// UpdateById publishes the record as stored, with its new update time. It is
+// read back from the primary since a replica may not have the update yet.
+func (s *Store) UpdateById(ctx context.Context, id uuid.UUID, record news.Record) error {
+	if err := s.newsStore.UpdateById(ctx, id, record); err != nil {
+		return err
+	}
+	s.publishUpdate(ctx, id)
+	return nil
+}
+
+func (s *Store) UpdateByIdIfUnchanged(ctx context.Context, id uuid.UUID, updatedAt time.Time, record news.Record) error {
+	if err := s.newsStore.UpdateByIdIfUnchanged(ctx, id, updatedAt, record); err != nil {
+		return err
+	}
+	s.publishUpdate(ctx, id)
+	return nil
+}
+
+func (s *Store) publishUpdate(ctx context.Context, id uuid.UUID) {
+	if updated, err := s.newsStore.FindById(db.WithPrimary(ctx), id); err == nil {
+		s.bus.Publish(outbox.NewsUpdated, updated)
+	}
+}
+
+// DeleteById publishes the record found before the delete, deleting a
+// missing record publishes nothing
+func (s *Store) DeleteById(ctx context.Context, id uuid.UUID) error {
+	found, findErr := s.newsStore.FindById(db.WithPrimary(ctx), id)
+	if err := s.newsStore.DeleteById(ctx, id); err != nil {
+		return err
+	}
+	if findErr == nil {
+		found.DeletedAt = time.Now().UTC()
+		s.bus.Publish(outbox.NewsDeleted, found)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/logeshwarann-dev/news-api-rest/internal/store"
+	"github.com/logeshwarann-dev/news-api-rest/internal/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ handler.NewsStorer = (*events.Store)(nil)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) handler.NewsStorer {
+		return events.NewStore(store.New(), events.NewBus(10))
+	})
+}
+
+func TestStore_Publish(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus(10)
+	s := events.NewStore(store.New(), bus)
+	sub, _, _ := bus.Subscribe("")
+	defer sub.Close()
+
+	created, err := s.Create(ctx, storetest.Record("Batman", time.Time{}, "marvel"))
+	require.NoError(t, err)
+	event := <-sub.C
+	assert.Equal(t, outbox.NewsCreated, event.Type)
+	assert.Equal(t, created, event.Record)
+
+	created.Title = "Robin"
+	require.NoError(t, s.UpdateById(ctx, created.Id, created))
+	event = <-sub.C
+	assert.Equal(t, outbox.NewsUpdated, event.Type)
+	assert.Equal(t, "Robin", event.Record.Title)
+	assert.False(t, event.Record.UpdatedAt.IsZero())
+
+	require.NoError(t, s.DeleteById(ctx, created.Id))
+	event = <-sub.C
+	assert.Equal(t, outbox.NewsDeleted, event.Type)
+	assert.Equal(t, created.Id, event.Record.Id)
+	assert.False(t, event.Record.DeletedAt.IsZero())
+
+	// failed changes publish nothing
+	assert.Error(t, s.UpdateById(ctx, uuid.New(), created))
+	require.NoError(t, s.DeleteById(ctx, uuid.New()))
+	select {
+	case event := <-sub.C:
+		t.Fatalf("unexpected event %v", event)
+	default:
+	}
+}
@@ -0,0 +1,222 @@
+// Package feed renders news records as syndication feeds.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+)
+
+// Format is a syndication format
+type Format int
+
+const (
+	RSS Format = iota
+	Atom
+	JSON
+)
+
+// MediaType returns the media type of the format
+func (f Format) MediaType() string {
+	switch f {
+	case Atom:
+		return "application/atom+xml"
+	case JSON:
+		return "application/feed+json"
+	default:
+		return "application/rss+xml"
+	}
+}
+
+// ContentType returns the Content-Type header of the format
+func (f Format) ContentType() string {
+	return f.MediaType() + "; charset=utf-8"
+}
+
+// Meta describes the feed itself
+type Meta struct {
+	Title       string
+	Description string
+	// Link is the web page the feed is about
+	Link string
+	// Self is the url the feed is served from
+	Self string
+	// Next is the url of the following page, empty on the last one
+	Next string
+	// Updated is the latest update among the records of the feed
+	Updated time.Time
+}
+
+// Write renders the records in the format, newest first as given
+func Write(w io.Writer, f Format, meta Meta, records []news.Record) error {
+	var doc any
+	switch f {
+	case RSS:
+		doc = NewRSS(meta, records)
+	case Atom:
+		doc = NewAtom(meta, records)
+	case JSON:
+		return json.NewEncoder(w).Encode(NewJSONFeed(meta, records))
+	default:
+		return fmt.Errorf("unknown feed format %d", f)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// RSSFeed is an RSS 2.0 document
+type RSSFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      AtomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []RSSItem `xml:"item"`
+}
+
+type RSSItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	GUID        RSSGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type RSSGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// NewRSS maps the records to RSS items
+func NewRSS(meta Meta, records []news.Record) RSSFeed {
+	feed := RSSFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: RSSChannel{
+			Title:       meta.Title,
+			Link:        meta.Link,
+			Description: meta.Description,
+			AtomLink:    AtomLink{Href: meta.Self, Rel: "self", Type: RSS.MediaType()},
+		},
+	}
+	if !meta.Updated.IsZero() {
+		feed.Channel.LastBuildDate = meta.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, r := range records {
+		feed.Channel.Items = append(feed.Channel.Items, RSSItem{
+			Title:       r.Title,
+			Link:        r.Source,
+			Description: r.Summary,
+			Creator:     r.Author,
+			Categories:  r.Tags,
+			GUID:        RSSGUID{Value: urn(r)},
+			PubDate:     r.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return feed
+}
+
+// AtomFeed is an Atom (RFC 4287) document
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Summary    string         `xml:"summary"`
+	Author     AtomPerson     `xml:"author"`
+	Links      []AtomLink     `xml:"link"`
+	Categories []AtomCategory `xml:"category"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// NewAtom maps the records to Atom entries
+func NewAtom(meta Meta, records []news.Record) AtomFeed {
+	updated := meta.Updated
+	if updated.IsZero() {
+		// an empty feed still needs an updated element
+		updated = time.Unix(0, 0)
+	}
+	feed := AtomFeed{
+		ID:       meta.Self,
+		Title:    meta.Title,
+		Subtitle: meta.Description,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []AtomLink{
+			{Href: meta.Self, Rel: "self", Type: Atom.MediaType()},
+			{Href: meta.Link, Rel: "alternate"},
+		},
+	}
+	if meta.Next != "" {
+		// RFC 5005 paged feed
+		feed.Links = append(feed.Links, AtomLink{Href: meta.Next, Rel: "next", Type: Atom.MediaType()})
+	}
+	for _, r := range records {
+		entry := AtomEntry{
+			ID:        urn(r),
+			Title:     r.Title,
+			Summary:   r.Summary,
+			Author:    AtomPerson{Name: r.Author},
+			Published: r.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:   r.UpdatedAt.UTC().Format(time.RFC3339),
+		}
+		if r.Source != "" {
+			entry.Links = append(entry.Links, AtomLink{Href: r.Source, Rel: "alternate"})
+		}
+		for _, tag := range r.Tags {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// urn identifies a record independently of where the feed is served
+func urn(r news.Record) string {
+	return "urn:uuid:" + r.Id.String()
+}
@@ -0,0 +1,119 @@
+package feed_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/feed"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	created = time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	updated = time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC)
+	meta    = feed.Meta{
+		Title:       "News",
+		Description: "Latest news",
+		Link:        "https://api.example.com/news",
+		Self:        "https://api.example.com/feeds/atom.xml",
+		Updated:     updated,
+	}
+	records = []news.Record{{
+		Id:        uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a"),
+		Author:    "Batman",
+		Title:     "Breaking <NEWS>",
+		Summary:   "A brief summary",
+		Source:    "https://www.example.com/breaking",
+		Tags:      []string{"marvel", "sci-fi"},
+		CreatedAt: created,
+		UpdatedAt: updated,
+	}}
+)
+
+func Test_WriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, feed.Write(&buf, feed.RSS, meta, records))
+
+	var got feed.RSSFeed
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "2.0", got.Version)
+	assert.Equal(t, "Fri, 02 Oct 2026 09:00:00 +0000", got.Channel.LastBuildDate)
+	require.Len(t, got.Channel.Items, 1)
+	item := got.Channel.Items[0]
+	assert.Equal(t, "Breaking <NEWS>", item.Title)
+	assert.Equal(t, "https://www.example.com/breaking", item.Link)
+	assert.Equal(t, "A brief summary", item.Description)
+	assert.Equal(t, []string{"marvel", "sci-fi"}, item.Categories)
+	assert.Equal(t, "urn:uuid:c2f92052-348f-4372-b4bc-43dbbc88445a", item.GUID.Value)
+	assert.Equal(t, "Thu, 01 Oct 2026 08:30:00 +0000", item.PubDate)
+	assert.Contains(t, buf.String(), `<dc:creator>Batman</dc:creator>`)
+}
+
+func Test_WriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, feed.Write(&buf, feed.Atom, meta, records))
+
+	var got feed.AtomFeed
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "https://api.example.com/feeds/atom.xml", got.ID)
+	assert.Equal(t, "2026-10-02T09:00:00Z", got.Updated)
+	require.Len(t, got.Entries, 1)
+	entry := got.Entries[0]
+	assert.Equal(t, "urn:uuid:c2f92052-348f-4372-b4bc-43dbbc88445a", entry.ID)
+	assert.Equal(t, "Batman", entry.Author.Name)
+	assert.Equal(t, []feed.AtomLink{{Href: "https://www.example.com/breaking", Rel: "alternate"}}, entry.Links)
+	assert.Equal(t, []feed.AtomCategory{{Term: "marvel"}, {Term: "sci-fi"}}, entry.Categories)
+	assert.Equal(t, "2026-10-01T08:30:00Z", entry.Published)
+	assert.Contains(t, buf.String(), `<feed xmlns="http://www.w3.org/2005/Atom">`)
+}
+
+func Test_WriteEmptyAtom(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, feed.Write(&buf, feed.Atom, feed.Meta{Title: "News"}, nil))
+
+	var got feed.AtomFeed
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.NotEmpty(t, got.Updated)
+	assert.Empty(t, got.Entries)
+}
+
+func Test_WriteJSONFeed(t *testing.T) {
+	var buf bytes.Buffer
+	m := meta
+	m.Next = "https://api.example.com/feeds/feed.json?offset=50"
+
+	require.NoError(t, feed.Write(&buf, feed.JSON, m, records))
+
+	var got feed.JSONFeed
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, feed.JSONFeedVersion, got.Version)
+	assert.Equal(t, "https://api.example.com/feeds/feed.json?offset=50", got.NextURL)
+	require.Len(t, got.Items, 1)
+	assert.Equal(t, feed.JSONFeedItem{
+		ID:            "c2f92052-348f-4372-b4bc-43dbbc88445a",
+		URL:           "https://www.example.com/breaking",
+		Title:         "Breaking <NEWS>",
+		Summary:       "A brief summary",
+		DatePublished: "2026-10-01T08:30:00Z",
+		DateModified:  "2026-10-02T09:00:00Z",
+		Authors:       []feed.JSONFeedAuthor{{Name: "Batman"}},
+		Tags:          []string{"marvel", "sci-fi"},
+	}, got.Items[0])
+}
+
+func Test_WriteEmptyJSONFeed(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, feed.Write(&buf, feed.JSON, feed.Meta{Title: "News"}, nil))
+
+	assert.JSONEq(t, `{"version":"https://jsonfeed.org/version/1.1","title":"News","items":[]}`, buf.String())
+}
@@ -0,0 +1,68 @@
+package feed
+
+import (
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+)
+
+// JSONFeedVersion identifies the JSON Feed 1.1 specification
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSONFeed is a JSON Feed 1.1 document
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url,omitempty"`
+	FeedURL     string         `json:"feed_url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	NextURL     string         `json:"next_url,omitempty"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	ContentText   string           `json:"content_text"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []JSONFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// NewJSONFeed maps the records to JSON Feed items
+func NewJSONFeed(meta Meta, records []news.Record) JSONFeed {
+	feed := JSONFeed{
+		Version:     JSONFeedVersion,
+		Title:       meta.Title,
+		HomePageURL: meta.Link,
+		FeedURL:     meta.Self,
+		Description: meta.Description,
+		NextURL:     meta.Next,
+		// items is required even when empty
+		Items: []JSONFeedItem{},
+	}
+	for _, r := range records {
+		item := JSONFeedItem{
+			ID:            r.Id.String(),
+			URL:           r.Source,
+			Title:         r.Title,
+			ContentText:   r.Content,
+			Summary:       r.Summary,
+			DatePublished: r.CreatedAt.UTC().Format(time.RFC3339),
+			DateModified:  r.UpdatedAt.UTC().Format(time.RFC3339),
+			Tags:          r.Tags,
+		}
+		if r.Author != "" {
+			item.Authors = []JSONFeedAuthor{{Name: r.Author}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
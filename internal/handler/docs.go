@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+)
+
+// GetOpenAPI serves the OpenAPI document of the api
+func GetOpenAPI() http.HandlerFunc {
+	spec := openapi.Spec()
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(spec)
+	}
+}
+
+// GetDocs serves the documentation page rendering the OpenAPI document
+func GetDocs() http.HandlerFunc {
+	docs, err := openapi.Docs()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			logger.FromContext(r.Context()).Error("documentation rendering failed", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(docs)
+	}
+}
@@ -0,0 +1,31 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetOpenAPI(t *testing.T) {
+	w := httptest.NewRecorder()
+	handler.GetOpenAPI().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.True(t, json.Valid(w.Body.Bytes()))
+}
+
+func Test_GetDocs(t *testing.T) {
+	w := httptest.NewRecorder()
+	handler.GetDocs().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `href="openapi.json"`)
+	// the page is complete as served, it loads no script
+	assert.NotContains(t, w.Body.String(), "<script")
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/feed"
+	"github.com/logeshwarann-dev/news-api-rest/internal/httpcache"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/logeshwarann-dev/news-api-rest/internal/validator"
+)
+
+// FeedSize is the number of newest records included in a feed
+const FeedSize = 50
+
+// GetFeed serves the newest news as a feed. It takes the filters of the listing
+// endpoint from the query, the tag and author path values taking precedence.
+func GetFeed(ns NewsStorer, format feed.Format) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("getfeed request recieved")
+
+		filter, err := validator.ValidateFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid filter", "error", err)
+			problem.Write(w, problem.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if tag := r.PathValue("tag"); tag != "" {
+			filter.Tag = tag
+		}
+		if author := r.PathValue("author"); author != "" {
+			filter.Author = author
+		}
+		if filter.Limit == 0 {
+			filter.Limit = FeedSize
+		}
+		filter.Order = news.NewestFirst
+
+		stats, err := ns.Stats(ctx, filter)
+		if err != nil {
+			log.Error("failed reading feed stats", "error", err)
+			writeStoreError(w, err)
+			return
+		}
+		etag := httpcache.ETag("feed", format, filter, stats.Count, stats.LastModified.UnixMicro())
+		if httpcache.NotModified(w, r, etag, stats.LastModified) {
+			return
+		}
+		records, err := ns.FindAll(ctx, filter)
+		if err != nil {
+			log.Error("failed finding feed news", "error", err)
+			writeStoreError(w, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := feed.Write(&buf, format, feedMeta(r, filter, stats, len(records)), records); err != nil {
+			log.Error("failed encoding feed", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", format.ContentType())
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
+
+func feedMeta(r *http.Request, filter news.Filter, stats news.Stats, count int) feed.Meta {
+	base := baseURL(r)
+	title := "News"
+	listing := url.Values{"sort": {"newest"}}
+	if filter.Tag != "" {
+		title += " tagged " + filter.Tag
+		listing.Set("tag", filter.Tag)
+	}
+	if filter.Author != "" {
+		title += " by " + filter.Author
+		listing.Set("author", filter.Author)
+	}
+	// the listing is served under the same version prefix as the feed
+	prefix, _, _ := strings.Cut(r.URL.Path, "/feeds/")
+	meta := feed.Meta{
+		Title:       title,
+		Description: "Latest " + strings.ToLower(title),
+		Link:        base + prefix + "/news?" + listing.Encode(),
+		Self:        base + r.URL.RequestURI(),
+		Updated:     stats.LastModified,
+	}
+	if filter.Offset+count < stats.Count {
+		next := r.URL.Query()
+		next.Set("offset", strconv.Itoa(filter.Offset+filter.Limit))
+		meta.Next = base + r.URL.Path + "?" + next.Encode()
+	}
+	return meta
+}
+
+// baseURL returns the scheme and host the request was addressed to
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// writeStoreError answers with the status carried by store errors, 500 otherwise
+func writeStoreError(w http.ResponseWriter, err error) {
+	var dbErr *news.CustomError
+	if errors.As(err, &dbErr) {
+		w.WriteHeader(dbErr.GetHttpStatus())
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+}
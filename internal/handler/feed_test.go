@@ -0,0 +1,223 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/logeshwarann-dev/news-api-rest/internal/feed"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	mockshandler "github.com/logeshwarann-dev/news-api-rest/internal/handler/mocks"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/logeshwarann-dev/news-api-rest/internal/store"
+)
+
+func Test_GetFeed(t *testing.T) {
+	lastModified := time.Date(2026, 10, 2, 9, 0, 0, 500, time.UTC)
+	testcases := []struct {
+		name                 string
+		target               string
+		format               feed.Format
+		pathValues           map[string]string
+		ifModifiedSince      string
+		setup                func(mh *mockshandler.MockNewsStorer)
+		expectedStatus       int
+		expectedContentType  string
+		expectedLastModified string
+		expectedBody         string
+	}{
+		{
+			name:   "rss_newest_first",
+			format: feed.RSS,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				filter := news.Filter{Limit: handler.FeedSize, Order: news.NewestFirst}
+				mh.EXPECT().Stats(gomock.Any(), filter).Return(news.Stats{Count: 1, LastModified: lastModified}, nil)
+				mh.EXPECT().FindAll(gomock.Any(), filter).Return([]news.Record{{Title: "test-title"}}, nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedContentType:  "application/rss+xml; charset=utf-8",
+			expectedLastModified: "Fri, 02 Oct 2026 09:00:00 GMT",
+			expectedBody:         "<title>test-title</title>",
+		},
+		{
+			name:       "atom_by_tag",
+			format:     feed.Atom,
+			pathValues: map[string]string{"tag": "go"},
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				filter := news.Filter{Tag: "go", Limit: handler.FeedSize, Order: news.NewestFirst}
+				mh.EXPECT().Stats(gomock.Any(), filter).Return(news.Stats{}, nil)
+				mh.EXPECT().FindAll(gomock.Any(), filter).Return(nil, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/atom+xml; charset=utf-8",
+			expectedBody:        "<title>News tagged go</title>",
+		},
+		{
+			name:            "not_modified_skips_listing",
+			format:          feed.RSS,
+			pathValues:      map[string]string{"author": "Batman"},
+			ifModifiedSince: "Fri, 02 Oct 2026 09:00:00 GMT",
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				filter := news.Filter{Author: "Batman", Limit: handler.FeedSize, Order: news.NewestFirst}
+				mh.EXPECT().Stats(gomock.Any(), filter).Return(news.Stats{Count: 1, LastModified: lastModified}, nil)
+			},
+			expectedStatus:       http.StatusNotModified,
+			expectedLastModified: "Fri, 02 Oct 2026 09:00:00 GMT",
+		},
+		{
+			name:            "modified_since",
+			format:          feed.RSS,
+			ifModifiedSince: "Fri, 02 Oct 2026 08:59:59 GMT",
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{Count: 1, LastModified: lastModified}, nil)
+				mh.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedContentType:  "application/rss+xml; charset=utf-8",
+			expectedLastModified: "Fri, 02 Oct 2026 09:00:00 GMT",
+		},
+		{
+			name:   "json_feed_with_next_page",
+			target: "/feeds/feed.json?author=Batman&limit=1",
+			format: feed.JSON,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				filter := news.Filter{Author: "Batman", Limit: 1, Order: news.NewestFirst}
+				mh.EXPECT().Stats(gomock.Any(), filter).Return(news.Stats{Count: 2, LastModified: lastModified}, nil)
+				mh.EXPECT().FindAll(gomock.Any(), filter).Return([]news.Record{{Title: "test-title"}}, nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedContentType:  "application/feed+json; charset=utf-8",
+			expectedLastModified: "Fri, 02 Oct 2026 09:00:00 GMT",
+			expectedBody:         `"next_url":"http://example.com/feeds/feed.json?author=Batman\u0026limit=1\u0026offset=1"`,
+		},
+		{
+			name:   "link_listing_of_same_version",
+			target: "/v1/feeds/feed.json?tag=go",
+			format: feed.JSON,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				filter := news.Filter{Tag: "go", Limit: 50, Order: news.NewestFirst}
+				mh.EXPECT().Stats(gomock.Any(), filter).Return(news.Stats{Count: 1, LastModified: lastModified}, nil)
+				mh.EXPECT().FindAll(gomock.Any(), filter).Return([]news.Record{{Title: "test-title"}}, nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedContentType:  "application/feed+json; charset=utf-8",
+			expectedLastModified: "Fri, 02 Oct 2026 09:00:00 GMT",
+			expectedBody:         `"home_page_url":"http://example.com/v1/news?sort=newest\u0026tag=go"`,
+		},
+		{
+			name:   "json_feed_last_page",
+			target: "/feeds/feed.json?limit=1&offset=1",
+			format: feed.JSON,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				filter := news.Filter{Limit: 1, Offset: 1, Order: news.NewestFirst}
+				mh.EXPECT().Stats(gomock.Any(), filter).Return(news.Stats{Count: 2, LastModified: lastModified}, nil)
+				mh.EXPECT().FindAll(gomock.Any(), filter).Return([]news.Record{{Title: "test-title"}}, nil)
+			},
+			expectedStatus:       http.StatusOK,
+			expectedContentType:  "application/feed+json; charset=utf-8",
+			expectedLastModified: "Fri, 02 Oct 2026 09:00:00 GMT",
+			expectedBody:         `"description":"Latest news","items"`,
+		},
+		{
+			name:                "invalid_filter",
+			target:              "/feeds/feed.json?limit=none",
+			format:              feed.JSON,
+			setup:               func(mh *mockshandler.MockNewsStorer) {},
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: problem.ContentType,
+		},
+		{
+			name:   "stats_error",
+			format: feed.Atom,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			//Arrange
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			tc.setup(mh)
+			w := httptest.NewRecorder()
+			target := tc.target
+			if target == "" {
+				target = "/feeds/rss.xml"
+			}
+			r := httptest.NewRequest(http.MethodGet, target, nil)
+			for k, v := range tc.pathValues {
+				r.SetPathValue(k, v)
+			}
+			if tc.ifModifiedSince != "" {
+				r.Header.Set("If-Modified-Since", tc.ifModifiedSince)
+			}
+			//Act
+			handler.GetFeed(mh, tc.format)(w, r)
+			//Assert
+			if w.Result().StatusCode != tc.expectedStatus {
+				t.Errorf("expected status: %d, got status: %d", tc.expectedStatus, w.Result().StatusCode)
+			}
+			if got := w.Header().Get("Content-Type"); got != tc.expectedContentType {
+				t.Errorf("expected content type: %s, got: %s", tc.expectedContentType, got)
+			}
+			if got := w.Header().Get("Last-Modified"); got != tc.expectedLastModified {
+				t.Errorf("expected last modified: %s, got: %s", tc.expectedLastModified, got)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("expected body to contain: %s, got: %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_GetFeedModifiedByDelete(t *testing.T) {
+	//Arrange
+	now := time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC)
+	ns := store.New(store.WithClock(func() time.Time { return now }))
+	var created news.Record
+	for _, title := range []string{"kept-title", "deleted-title"} {
+		var err error
+		created, err = ns.Create(context.Background(), news.Record{
+			Author: "test-author", Title: title, Summary: "test-summary", Content: "test-content",
+			Source: "https://google.com", Tags: []string{"go"},
+		})
+		if err != nil {
+			t.Fatalf("create news: %v", err)
+		}
+	}
+	get := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/feeds/tags/go/rss.xml", nil)
+		r.SetPathValue("tag", "go")
+		if ifModifiedSince != "" {
+			r.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		handler.GetFeed(ns, feed.RSS)(w, r)
+		return w
+	}
+	first := get("")
+	now = now.Add(time.Minute)
+	if err := ns.DeleteById(context.Background(), created.Id); err != nil {
+		t.Fatalf("delete news: %v", err)
+	}
+	//Act
+	second := get(first.Header().Get("Last-Modified"))
+	//Assert
+	if second.Code != http.StatusOK {
+		t.Errorf("expected status: %d, got status: %d", http.StatusOK, second.Code)
+	}
+	if second.Header().Get("Last-Modified") != "Fri, 02 Oct 2026 09:01:00 GMT" {
+		t.Errorf("expected the deletion as last modified, got: %s", second.Header().Get("Last-Modified"))
+	}
+	if strings.Contains(second.Body.String(), "deleted-title") {
+		t.Errorf("expected the deleted news out of the feed, got: %s", second.Body.String())
+	}
+}
@@ -1,15 +1,27 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"maps"
+	"mime"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/httpcache"
 	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
 	"github.com/logeshwarann-dev/news-api-rest/internal/model"
 	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/policy"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/logeshwarann-dev/news-api-rest/internal/render"
 	"github.com/logeshwarann-dev/news-api-rest/internal/validator"
 )
 
@@ -19,24 +31,43 @@ import (
 type NewsStorer interface {
 	//Create News
 	Create(context.Context, news.Record) (news.Record, error)
-	//Get All News
-	FindAll(context.Context) ([]news.Record, error)
+	//Get All News matching a filter
+	FindAll(context.Context, news.Filter) ([]news.Record, error)
+	//Stream News matching a filter without loading them all
+	Stream(context.Context, news.Filter) news.Records
+	//Count News matching a filter and find their latest update
+	Stats(context.Context, news.Filter) (news.Stats, error)
 	//Get News By Id
 	FindById(context.Context, uuid.UUID) (news.Record, error)
 	//Update News By Id
 	UpdateById(context.Context, uuid.UUID, news.Record) error
+	//Update News By Id while it was not updated since the given time
+	UpdateByIdIfUnchanged(context.Context, uuid.UUID, time.Time, news.Record) error
 	//Delete News By Id
 	DeleteById(context.Context, uuid.UUID) error
 }
 
+// Authorizer decides whether the caller may perform an action on a news record
+type Authorizer interface {
+	Authorize(context.Context, policy.Action, news.Record) error
+}
+
 func PostNews(ns NewsStorer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		log := logger.FromContext(ctx)
 		log.Info("postnews request recieved")
+		enc, ok := render.Select(w, r)
+		if !ok {
+			return
+		}
 		var newsRequestBody model.NewsRecord
 		if err := json.NewDecoder(r.Body).Decode(&newsRequestBody); err != nil {
 			log.Error("request decode failed, invalid request", "error", err.Error())
+			if p, ok := problem.TooLarge(err); ok {
+				problem.Write(w, p)
+				return
+			}
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
@@ -48,6 +79,8 @@ func PostNews(ns NewsStorer) http.HandlerFunc {
 			w.Write([]byte(err.Error()))
 			return
 		}
+		newsReq.Owner = editor(ctx)
+		newsReq.Editor = editor(ctx)
 		respRecord, err := ns.Create(ctx, newsReq)
 		if err != nil {
 			log.Error("failed adding news in db", "error", err.Error())
@@ -59,12 +92,7 @@ func PostNews(ns NewsStorer) http.HandlerFunc {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		if err := json.NewEncoder(w).Encode(respRecord); err != nil {
-			log.Error("failed encoding records", "error", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		w.WriteHeader(http.StatusCreated)
+		enc.Write(w, r, http.StatusCreated, respRecord)
 	}
 }
 
@@ -73,8 +101,37 @@ func GetAllNews(ns NewsStorer) http.HandlerFunc {
 		ctx := r.Context()
 		log := logger.FromContext(ctx)
 		log.Info("getallnews request recieved")
+		enc, ok := render.Select(w, r)
+		if !ok {
+			return
+		}
+		filter, err := validator.ValidateFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid filter", "error", err)
+			problem.Write(w, problem.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		// the validators come from the row count and latest update, sparing the encoding of the body
+		stats, err := ns.Stats(ctx, filter)
+		if err != nil {
+			log.Error("failed reading news stats", "error", err)
+			writeStoreError(w, err)
+			return
+		}
+		etag := httpcache.ETag("news", enc.Format, filter, stats.Count, stats.LastModified.UnixMicro())
+		if httpcache.NotModified(w, r, etag, stats.LastModified) {
+			return
+		}
+		if enc.CanStream() {
+			// rows are encoded as they are read, keeping memory flat for large listings
+			if err := render.Stream(enc, w, r, http.StatusOK, "news", ns.Stream(ctx, filter)); err != nil {
+				log.Error("failed streaming news", "error", err)
+				writeStoreError(w, err)
+			}
+			return
+		}
 
-		records, err := ns.FindAll(ctx)
+		records, err := ns.FindAll(ctx, filter)
 		newsRecords := model.AllNewsRecords{
 			NewsRecords: records,
 		}
@@ -88,12 +145,7 @@ func GetAllNews(ns NewsStorer) http.HandlerFunc {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		if err := json.NewEncoder(w).Encode(newsRecords); err != nil {
-			log.Error("failed encoding records", "error", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		w.WriteHeader(http.StatusOK)
+		enc.Write(w, r, http.StatusOK, newsRecords)
 	}
 }
 
@@ -102,6 +154,10 @@ func GetNewsByID(ns NewsStorer) http.HandlerFunc {
 		ctx := r.Context()
 		log := logger.FromContext(ctx)
 		log.Info("getnewsbyid request recieved")
+		enc, ok := render.Select(w, r)
+		if !ok {
+			return
+		}
 		id := r.PathValue("news_id")
 		newsId, err := validator.ValidateNewsId(id)
 		if err != nil {
@@ -120,17 +176,15 @@ func GetNewsByID(ns NewsStorer) http.HandlerFunc {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-
-		if err := json.NewEncoder(w).Encode(newsRecord); err != nil {
-			log.Error("failed encoding response", "error", err)
-			w.WriteHeader(http.StatusInternalServerError)
+		etag := httpcache.ETag("news", enc.Format, newsRecord.Id, newsRecord.UpdatedAt.UnixMicro())
+		if httpcache.NotModified(w, r, etag, newsRecord.UpdatedAt) {
 			return
 		}
-		w.WriteHeader(http.StatusOK)
+		enc.Write(w, r, http.StatusOK, newsRecord)
 	}
 }
 
-func UpdateNewsByID(ns NewsStorer) http.HandlerFunc {
+func UpdateNewsByID(ns NewsStorer, az Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		log := logger.FromContext(ctx)
@@ -138,6 +192,10 @@ func UpdateNewsByID(ns NewsStorer) http.HandlerFunc {
 		var req model.NewsRecord
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			log.Error("request decoding failed", "error", err)
+			if p, ok := problem.TooLarge(err); ok {
+				problem.Write(w, p)
+				return
+			}
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
@@ -166,8 +224,16 @@ func UpdateNewsByID(ns NewsStorer) http.HandlerFunc {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		newsReq.UpdatedAt = found.UpdatedAt
+		if !authorize(w, r, az, policy.ActionUpdate, found) {
+			return
+		}
+		if newsReq.Author, err = attribution(ctx, az, newsReq.Author, found); err != nil {
+			log.Error("authorization failed", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 		newsReq.DeletedAt = found.DeletedAt
+		newsReq.Editor = editor(ctx)
 		err = ns.UpdateById(ctx, newsId, newsReq)
 		if err != nil {
 			log.Error("unable to update news by id", "error", err)
@@ -183,7 +249,147 @@ func UpdateNewsByID(ns NewsStorer) http.HandlerFunc {
 	}
 }
 
-func DeleteNewsByID(ns NewsStorer) http.HandlerFunc {
+const (
+	// mergePatch is the only patch format PATCH accepts (RFC 7396)
+	mergePatch = "application/merge-patch+json"
+	// maxPatchSize bounds the patch read whatever the server body limit is
+	maxPatchSize = 1 << 20
+	// maxPatchAttempts bounds the merges of a patch without If-Match with a
+	// news changing meanwhile
+	maxPatchAttempts = 3
+)
+
+// etags returns the entity tags GetNewsByID gives the record in every format
+func etags(record news.Record) []string {
+	var tags []string
+	for _, format := range render.Default.Formats() {
+		tags = append(tags, httpcache.ETag("news", format, record.Id, record.UpdatedAt.UnixMicro()))
+	}
+	return tags
+}
+
+// PatchNewsByID applies a JSON merge patch (RFC 7396) to a news: members of
+// the patch replace those of the news, arrays included. Every field being
+// required, members set to null are refused.
+// The update only applies to the version the patch was merged with: when
+// If-Match is sent, a news changed since is answered with 412, otherwise the
+// patch is merged again with the new version.
+func PatchNewsByID(ns NewsStorer, az Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("patchnewsbyid request recieved")
+		newsId, err := validator.ValidateNewsId(r.PathValue("news_id"))
+		if err != nil {
+			log.Error("invalid news id", "error", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != mergePatch {
+			log.Error("unsupported patch media type", "content_type", r.Header.Get("Content-Type"))
+			w.Header().Set("Accept-Patch", mergePatch)
+			problem.Write(w, problem.New(http.StatusUnsupportedMediaType, "the patch must be sent as "+mergePatch))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+		if err != nil {
+			log.Error("request reading failed", "error", err)
+			if p, ok := problem.TooLarge(err); ok {
+				problem.Write(w, p)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var patch map[string]json.RawMessage
+		if err := json.Unmarshal(body, &patch); err != nil {
+			log.Error("request decoding failed", "error", err)
+			problem.Write(w, problem.New(http.StatusBadRequest, "the patch must be a json object"))
+			return
+		}
+		for _, name := range slices.Sorted(maps.Keys(patch)) {
+			if string(patch[name]) == "null" {
+				problem.Write(w, problem.New(http.StatusBadRequest, name+" is required and cannot be removed"))
+				return
+			}
+		}
+		// the version merged with is read from the primary, a cached or
+		// replicated one may be out of date
+		ctx = db.WithPrimary(ctx)
+		for attempt := 1; ; attempt++ {
+			found, err := ns.FindById(ctx, newsId)
+			if err != nil {
+				log.Error("failed finding newsrecord by id", "error", err)
+				writeStoreError(w, err)
+				return
+			}
+			if !authorize(w, r, az, policy.ActionUpdate, found) {
+				return
+			}
+			if !httpcache.Matches(r, etags(found)...) {
+				log.Info("patch precondition failed", "if_match", r.Header.Get("If-Match"))
+				problem.Write(w, problem.New(http.StatusPreconditionFailed, "the news record changed since it was read"))
+				return
+			}
+			newsReq, err := merge(found, body)
+			if err != nil {
+				log.Error("invalid patch", "error", err)
+				problem.Write(w, problem.New(http.StatusBadRequest, err.Error()))
+				return
+			}
+			if newsReq.Author, err = attribution(ctx, az, newsReq.Author, found); err != nil {
+				log.Error("authorization failed", "error", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			newsReq.DeletedAt = found.DeletedAt
+			newsReq.Editor = editor(ctx)
+			err = ns.UpdateByIdIfUnchanged(ctx, newsId, found.UpdatedAt, newsReq)
+			if errors.Is(err, news.ErrChanged) {
+				if r.Header.Get("If-Match") != "" {
+					log.Info("patch precondition failed", "if_match", r.Header.Get("If-Match"))
+					problem.Write(w, problem.New(http.StatusPreconditionFailed, "the news record changed since it was read"))
+					return
+				}
+				if attempt < maxPatchAttempts {
+					log.Info("news changed while patched, merging again", "attempt", attempt)
+					continue
+				}
+				log.Error("news kept changing while patched", "attempts", attempt)
+				problem.Write(w, problem.New(http.StatusConflict, "the news record kept changing, retry the patch"))
+				return
+			}
+			if err != nil {
+				log.Error("unable to patch news by id", "error", err)
+				writeStoreError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+}
+
+// merge applies the patch body to the found record and validates the result
+func merge(found news.Record, body []byte) (news.Record, error) {
+	req := model.NewsRecord{
+		Author:    found.Author,
+		Title:     found.Title,
+		Summary:   found.Summary,
+		Content:   found.Content,
+		Source:    found.Source,
+		CreatedAt: found.CreatedAt.Format(time.RFC3339Nano),
+		Tags:      slices.Clone(found.Tags),
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return news.Record{}, err
+	}
+	return validator.ValidateNewsRequest(req)
+}
+
+func DeleteNewsByID(ns NewsStorer, az Authorizer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		log := logger.FromContext(ctx)
@@ -201,6 +407,20 @@ func DeleteNewsByID(ns NewsStorer) http.HandlerFunc {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
+		found, err := ns.FindById(ctx, newsId)
+		if err != nil {
+			log.Error("failed finding newsrecord by id", "error", err)
+			var dbErr *news.CustomError
+			if errors.As(err, &dbErr) {
+				w.WriteHeader(dbErr.GetHttpStatus())
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !authorize(w, r, az, policy.ActionDelete, found) {
+			return
+		}
 		if err := ns.DeleteById(ctx, newsId); err != nil {
 			log.Error("failed to delete news by id", "error", err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -209,3 +429,42 @@ func DeleteNewsByID(ns NewsStorer) http.HandlerFunc {
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+// authorize consults the policy for the record and writes a problem response when the action is denied
+func authorize(w http.ResponseWriter, r *http.Request, az Authorizer, action policy.Action, record news.Record) bool {
+	err := az.Authorize(r.Context(), action, record)
+	if err == nil {
+		return true
+	}
+	log := logger.FromContext(r.Context())
+	if errors.Is(err, policy.ErrForbidden) {
+		log.Info("action denied by policy", "action", action, "error", err)
+		problem.Write(w, problem.New(http.StatusForbidden, err.Error()))
+		return false
+	}
+	log.Error("authorization failed", "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+	return false
+}
+
+// attribution returns the author to store when updating found: the requested
+// one if the caller may attribute news to anyone, the stored one otherwise
+func attribution(ctx context.Context, az Authorizer, requested string, found news.Record) (string, error) {
+	if requested == found.Author {
+		return requested, nil
+	}
+	err := az.Authorize(ctx, policy.ActionAttribute, found)
+	if errors.Is(err, policy.ErrForbidden) {
+		logger.FromContext(ctx).Info("author kept, caller may not attribute news", "error", err)
+		return found.Author, nil
+	}
+	return requested, err
+}
+
+// editor returns the authenticated subject recorded as the last editor of a record
+func editor(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.Subject
+	}
+	return ""
+}
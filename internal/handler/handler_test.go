@@ -1,19 +1,29 @@
 package handler_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
 	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
 	mockshandler "github.com/logeshwarann-dev/news-api-rest/internal/handler/mocks"
+	"github.com/logeshwarann-dev/news-api-rest/internal/httpcache"
 	"github.com/logeshwarann-dev/news-api-rest/internal/model"
 	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/policy"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/logeshwarann-dev/news-api-rest/internal/store"
 )
 
 func Test_PostNews(t *testing.T) {
@@ -43,7 +53,7 @@ func Test_PostNews(t *testing.T) {
 					"summary": "test-summary",
 					"content": "test-content",
 					"source": "https://www.google.com",
-					"createdAt": "",
+					"created_at": "",
 					"tags": ["test-tag"]
 					}`),
 			setup: func(tb testing.TB) handler.NewsStorer {
@@ -62,7 +72,7 @@ func Test_PostNews(t *testing.T) {
 					"summary": "test-summary",
 					"content": "test-content",
 					"source": "https://www.google.com",
-					"createdAt": "2026-01-30T18:35:43+05:30",
+					"created_at": "2026-01-30T18:35:43+05:30",
 					"tags": ["test-tag"]
 					}`),
 			setup: func(tb testing.TB) handler.NewsStorer {
@@ -83,7 +93,7 @@ func Test_PostNews(t *testing.T) {
 					"summary": "test-summary",
 					"content": "test-content",
 					"source": "https://www.google.com",
-					"createdAt": "2026-01-30T18:35:43+05:30",
+					"created_at": "2026-01-30T18:35:43+05:30",
 					"tags": ["test-tag"]
 					}`),
 			setup: func(tb testing.TB) handler.NewsStorer {
@@ -104,7 +114,7 @@ func Test_PostNews(t *testing.T) {
 			"summary": "test-summary",
 			"content": "test-content",
 			"source": "https://google.com",
-			"createdAt": "2026-01-30T18:35:43+05:30",
+			"created_at": "2026-01-30T18:35:43+05:30",
 			"tags": ["test-tag"]
 			}`),
 			setup: func(tb testing.TB) handler.NewsStorer {
@@ -113,7 +123,7 @@ func Test_PostNews(t *testing.T) {
 				mh.EXPECT().Create(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
 				return mh
 			},
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusCreated,
 		},
 	}
 	for _, tc := range testCases {
@@ -142,7 +152,8 @@ func Test_GetAllNews(t *testing.T) {
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
-				mh.EXPECT().FindAll(gomock.Any()).Return([]news.Record{}, errors.New("db error"))
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{}, nil)
+				mh.EXPECT().Stream(gomock.Any(), gomock.Any()).Return(recordSeq(errors.New("db error")))
 				return mh
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -152,7 +163,8 @@ func Test_GetAllNews(t *testing.T) {
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
-				mh.EXPECT().FindAll(gomock.Any()).Return([]news.Record{}, news.NewCustomError(errors.New("db_custom_error"), http.StatusBadRequest))
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{}, nil)
+				mh.EXPECT().Stream(gomock.Any(), gomock.Any()).Return(recordSeq(news.NewCustomError(errors.New("db_custom_error"), http.StatusBadRequest)))
 				return mh
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -162,7 +174,8 @@ func Test_GetAllNews(t *testing.T) {
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
-				mh.EXPECT().FindAll(gomock.Any()).Return([]news.Record{}, nil)
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{}, nil)
+				mh.EXPECT().Stream(gomock.Any(), gomock.Any()).Return(recordSeq(nil))
 				return mh
 			},
 			expectedStatus: http.StatusOK,
@@ -298,7 +311,7 @@ func Test_UpdateNewsByID(t *testing.T) {
 					"summary": "test-summary",
 					"content": "test-content",
 					"source": "https://google.com",
-					"createdAt": "2026-01-30T18:35:43+05:30",
+					"created_at": "2026-01-30T18:35:43+05:30",
 					"tags": ["test-tag"]
 					}`),
 			newsId: "$%123",
@@ -317,7 +330,7 @@ func Test_UpdateNewsByID(t *testing.T) {
 					"summary": "",
 					"content": "test-content",
 					"source": "https://google.com",
-					"createdAt": "1234",
+					"created_at": "1234",
 					"tags": ["test-tag"]
 					}`),
 			newsId: "c2f92052-348f-4372-b4bc-43dbbc88445a",
@@ -337,13 +350,14 @@ func Test_UpdateNewsByID(t *testing.T) {
 				"summary": "test-summary",
 				"content": "test-content",
 				"source": "https://google.com",
-				"createdAt": "2026-01-30T18:35:43+05:30",
+				"created_at": "2026-01-30T18:35:43+05:30",
 				"tags": ["test-tag"]
 				}`),
 			newsId: "c2f92052-348f-4372-b4bc-43dbbc88445a",
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
 				mh.EXPECT().UpdateById(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("db error"))
 				return mh
 			},
@@ -359,13 +373,14 @@ func Test_UpdateNewsByID(t *testing.T) {
 				"summary": "test-summary",
 				"content": "test-content",
 				"source": "https://google.com",
-				"createdAt": "2026-01-30T18:35:43+05:30",
+				"created_at": "2026-01-30T18:35:43+05:30",
 				"tags": ["test-tag"]
 				}`),
 			newsId: "c2f92052-348f-4372-b4bc-43dbbc88445a",
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
 				mh.EXPECT().UpdateById(gomock.Any(), gomock.Any(), gomock.Any()).Return(news.NewCustomError(errors.New("db_custom_error"), http.StatusBadRequest))
 				return mh
 			},
@@ -381,13 +396,14 @@ func Test_UpdateNewsByID(t *testing.T) {
 			"summary": "test-summary",
 			"content": "test-content",
 			"source": "https://google.com",
-			"createdAt": "2026-01-30T18:35:43+05:30",
+			"created_at": "2026-01-30T18:35:43+05:30",
 			"tags": ["test-tag"]
 			}`),
 			newsId: "c2f92052-348f-4372-b4bc-43dbbc88445a",
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
 				mh.EXPECT().UpdateById(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				return mh
 			},
@@ -403,7 +419,7 @@ func Test_UpdateNewsByID(t *testing.T) {
 			r := httptest.NewRequest(http.MethodPut, "/news/", tc.request)
 			r.SetPathValue("news_id", tc.newsId)
 			//Act
-			handler.UpdateNewsByID(tc.setup(t))(w, r)
+			handler.UpdateNewsByID(tc.setup(t), policy.AllowAll{})(w, r)
 			//Assert
 			if w.Result().StatusCode != tc.expectedStatus {
 				t.Errorf("expected status: %d, got status: %d", tc.expectedStatus, w.Result().StatusCode)
@@ -412,6 +428,313 @@ func Test_UpdateNewsByID(t *testing.T) {
 	}
 }
 
+func Test_PatchNewsByID(t *testing.T) {
+	found := news.Record{
+		Id:        uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a"),
+		Author:    "test-author",
+		Title:     "test-title",
+		Summary:   "test-summary",
+		Content:   "test-content",
+		Source:    "https://google.com",
+		Tags:      []string{"test-tag"},
+		CreatedAt: time.Date(2026, time.January, 30, 13, 5, 43, 0, time.UTC),
+		UpdatedAt: time.Date(2026, time.February, 2, 9, 0, 0, 0, time.UTC),
+	}
+	changed := found
+	changed.Summary = "changed-summary"
+	changed.UpdatedAt = found.UpdatedAt.Add(time.Second)
+	errChanged := news.NewCustomError(news.ErrChanged, http.StatusPreconditionFailed)
+	testcases := []struct {
+		name           string
+		request        string
+		newsId         string
+		headers        map[string]string
+		setup          func(mh *mockshandler.MockNewsStorer, got *news.Record)
+		expectedStatus int
+		expectedRecord news.Record
+	}{
+		{
+			name:           "not_a_merge_patch",
+			request:        `{"title": "new-title"}`,
+			newsId:         "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			headers:        map[string]string{"Content-Type": "application/json"},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "incorrect_news_id",
+			request:        `{"title": "new-title"}`,
+			newsId:         "$%123",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "patch_not_an_object",
+			request:        `["title"]`,
+			newsId:         "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "remove_required_member",
+			request:        `{"title": null}`,
+			newsId:         "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "news_not_found",
+			request: `{"title": "new-title"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, news.NewCustomError(errors.New("not found"), http.StatusNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "member_of_wrong_type",
+			request: `{"tags": "test-tag"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "unknown_member",
+			request: `{"titel": "new-title"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "stale_if_match",
+			request: `{"title": "new-title"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			headers: map[string]string{"If-Match": `"xyz", W/` + httpcache.ETag("news", "json", found.Id, found.UpdatedAt.UnixMicro())},
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "invalid_patched_news",
+			request: `{"author": "", "tags": []}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "patch_success",
+			request: `{"title": "new-title", "tags": ["go", "news"]}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil)
+				mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), found.Id, found.UpdatedAt, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, _ time.Time, r news.Record) error {
+					*got = r
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedRecord: news.Record{
+				Author:    "test-author",
+				Title:     "new-title",
+				Summary:   "test-summary",
+				Content:   "test-content",
+				Source:    "https://google.com",
+				Tags:      []string{"go", "news"},
+				CreatedAt: found.CreatedAt,
+			},
+		},
+		{
+			name:    "matching_if_match",
+			request: `{"title": "new-title"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			// the tag of any representation of the record matches
+			headers: map[string]string{"If-Match": `"xyz", ` + httpcache.ETag("news", "xml", found.Id, found.UpdatedAt.UnixMicro())},
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil)
+				mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), found.Id, found.UpdatedAt, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, _ time.Time, r news.Record) error {
+					*got = r
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedRecord: news.Record{
+				Author:    "test-author",
+				Title:     "new-title",
+				Summary:   "test-summary",
+				Content:   "test-content",
+				Source:    "https://google.com",
+				Tags:      []string{"test-tag"},
+				CreatedAt: found.CreatedAt,
+			},
+		},
+		{
+			name:    "changed_after_if_match",
+			request: `{"title": "new-title"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			headers: map[string]string{"If-Match": httpcache.ETag("news", "json", found.Id, found.UpdatedAt.UnixMicro())},
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil)
+				mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), found.Id, found.UpdatedAt, gomock.Any()).Return(errChanged)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "merge_again_when_changed",
+			request: `{"title": "new-title"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				gomock.InOrder(
+					mh.EXPECT().FindById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ uuid.UUID) (news.Record, error) {
+						if !db.UsesPrimary(ctx) {
+							t.Error("the patched version was not read from the primary")
+						}
+						return found, nil
+					}),
+					mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), found.Id, found.UpdatedAt, gomock.Any()).Return(errChanged),
+					mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(changed, nil),
+					mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), found.Id, changed.UpdatedAt, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, _ time.Time, r news.Record) error {
+						*got = r
+						return nil
+					}),
+				)
+			},
+			expectedStatus: http.StatusOK,
+			expectedRecord: news.Record{
+				Author:    "test-author",
+				Title:     "new-title",
+				Summary:   "changed-summary",
+				Content:   "test-content",
+				Source:    "https://google.com",
+				Tags:      []string{"test-tag"},
+				CreatedAt: found.CreatedAt,
+			},
+		},
+		{
+			name:    "keeps_changing",
+			request: `{"title": "new-title"}`,
+			newsId:  "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(found, nil).Times(3)
+				mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), found.Id, found.UpdatedAt, gomock.Any()).Return(errChanged).Times(3)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			//Arrange
+			var got news.Record
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			if tc.setup != nil {
+				tc.setup(mh, &got)
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "/news/", strings.NewReader(tc.request))
+			r.Header.Set("Content-Type", "application/merge-patch+json")
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			r.SetPathValue("news_id", tc.newsId)
+			//Act
+			handler.PatchNewsByID(mh, policy.AllowAll{})(w, r)
+			//Assert
+			if w.Result().StatusCode != tc.expectedStatus {
+				t.Errorf("expected status: %d, got status: %d", tc.expectedStatus, w.Result().StatusCode)
+			}
+			if tc.expectedStatus == http.StatusUnsupportedMediaType && w.Header().Get("Accept-Patch") != "application/merge-patch+json" {
+				t.Errorf("expected Accept-Patch: application/merge-patch+json, got: %q", w.Header().Get("Accept-Patch"))
+			}
+			if !got.CreatedAt.Equal(tc.expectedRecord.CreatedAt) {
+				t.Errorf("expected created_at: %v, got: %v", tc.expectedRecord.CreatedAt, got.CreatedAt)
+			}
+			got.CreatedAt = tc.expectedRecord.CreatedAt
+			if !reflect.DeepEqual(got, tc.expectedRecord) {
+				t.Errorf("expected record: %+v, got record: %+v", tc.expectedRecord, got)
+			}
+			if found.Tags[0] != "test-tag" {
+				t.Errorf("the found record was modified: %v", found.Tags)
+			}
+		})
+	}
+}
+
+func Test_UpdateNewsAttribution(t *testing.T) {
+	found := news.Record{
+		Id:        uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a"),
+		Author:    "Clark Kent",
+		Title:     "test-title",
+		Summary:   "test-summary",
+		Content:   "test-content",
+		Source:    "https://google.com",
+		Tags:      []string{"test-tag"},
+		Owner:     "u2",
+		CreatedAt: time.Date(2026, time.January, 30, 13, 5, 43, 0, time.UTC),
+	}
+	reporter := auth.Principal{Subject: "u2", Roles: []auth.Role{auth.RoleReporter}}
+	editor := auth.Principal{Subject: "u3", Roles: []auth.Role{auth.RoleEditor}}
+	put := `{"author": "Lois Lane", "title": "new-title", "summary": "test-summary", "content": "test-content",
+		"source": "https://google.com", "created_at": "2026-01-30T13:05:43Z", "tags": ["test-tag"]}`
+	testcases := []struct {
+		name           string
+		method         string
+		request        string
+		principal      auth.Principal
+		expectedAuthor string
+	}{
+		{name: "reporter_update_keeps_author", method: http.MethodPut, request: put, principal: reporter, expectedAuthor: "Clark Kent"},
+		{name: "reporter_patch_keeps_author", method: http.MethodPatch, request: `{"author": "Lois Lane"}`, principal: reporter, expectedAuthor: "Clark Kent"},
+		{name: "editor_update_changes_author", method: http.MethodPut, request: put, principal: editor, expectedAuthor: "Lois Lane"},
+		{name: "editor_patch_changes_author", method: http.MethodPatch, request: `{"author": "Lois Lane"}`, principal: editor, expectedAuthor: "Lois Lane"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			//Arrange
+			var got news.Record
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			mh.EXPECT().FindById(gomock.Any(), found.Id).Return(found, nil)
+			if tc.method == http.MethodPatch {
+				mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), found.Id, found.UpdatedAt, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, _ time.Time, r news.Record) error {
+					got = r
+					return nil
+				})
+			} else {
+				mh.EXPECT().UpdateById(gomock.Any(), found.Id, gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, r news.Record) error {
+					got = r
+					return nil
+				})
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/news/", strings.NewReader(tc.request))
+			if tc.method == http.MethodPatch {
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+			}
+			r = r.WithContext(auth.CtxWithPrincipal(r.Context(), tc.principal))
+			r.SetPathValue("news_id", found.Id.String())
+			h := handler.UpdateNewsByID(mh, policy.New(policy.DefaultRules...))
+			if tc.method == http.MethodPatch {
+				h = handler.PatchNewsByID(mh, policy.New(policy.DefaultRules...))
+			}
+			//Act
+			h(w, r)
+			//Assert
+			if w.Result().StatusCode != http.StatusOK {
+				t.Errorf("expected status: %d, got status: %d", http.StatusOK, w.Result().StatusCode)
+			}
+			if got.Author != tc.expectedAuthor {
+				t.Errorf("expected author: %q, got author: %q", tc.expectedAuthor, got.Author)
+			}
+			if got.Editor != tc.principal.Subject {
+				t.Errorf("expected editor: %q, got editor: %q", tc.principal.Subject, got.Editor)
+			}
+		})
+	}
+}
+
 func Test_DeleteNewsByID(t *testing.T) {
 	testcases := []struct {
 		name           string
@@ -434,6 +757,7 @@ func Test_DeleteNewsByID(t *testing.T) {
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
 				mh.EXPECT().DeleteById(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
 				return mh
 			},
@@ -445,17 +769,30 @@ func Test_DeleteNewsByID(t *testing.T) {
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
 				mh.EXPECT().DeleteById(gomock.Any(), gomock.Any()).Return(news.NewCustomError(errors.New("db_custom_error"), http.StatusInternalServerError))
 				return mh
 			},
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name:   "not_found",
+			newsId: "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(tb testing.TB) handler.NewsStorer {
+				tb.Helper()
+				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, news.NewCustomError(errors.New("no rows"), http.StatusNotFound))
+				return mh
+			},
+			expectedStatus: http.StatusNotFound,
+		},
 		{
 			name:   "delete_success",
 			newsId: "c2f92052-348f-4372-b4bc-43dbbc88445a",
 			setup: func(tb testing.TB) handler.NewsStorer {
 				tb.Helper()
 				mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
 				mh.EXPECT().DeleteById(gomock.Any(), gomock.Any()).Return(nil)
 				return mh
 			},
@@ -471,11 +808,432 @@ func Test_DeleteNewsByID(t *testing.T) {
 			r := httptest.NewRequest(http.MethodDelete, "/news/", nil)
 			r.SetPathValue("news_id", tc.newsId)
 			//Act
-			handler.DeleteNewsByID(tc.setup(t))(w, r)
+			handler.DeleteNewsByID(tc.setup(t), policy.AllowAll{})(w, r)
+			//Assert
+			if w.Result().StatusCode != tc.expectedStatus {
+				t.Errorf("expected status: %d, got status: %d", tc.expectedStatus, w.Result().StatusCode)
+			}
+		})
+	}
+}
+
+func Test_WriteHandlersRecordEditor(t *testing.T) {
+	body := `{
+		"author": "test-author",
+		"title": "test-title",
+		"summary": "test-summary",
+		"content": "test-content",
+		"source": "https://google.com",
+		"created_at": "2026-01-30T18:35:43+05:30",
+		"tags": ["test-tag"]
+		}`
+	testcases := []struct {
+		name           string
+		method         string
+		handler        func(handler.NewsStorer) http.HandlerFunc
+		setup          func(mh *mockshandler.MockNewsStorer, got *news.Record)
+		principal      *auth.Principal
+		expectedEditor string
+	}{
+		{
+			name:    "post_records_subject",
+			method:  http.MethodPost,
+			handler: handler.PostNews,
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r news.Record) (news.Record, error) {
+					*got = r
+					return r, nil
+				})
+			},
+			principal:      &auth.Principal{Subject: "user-42"},
+			expectedEditor: "user-42",
+		},
+		{
+			name:    "put_records_subject",
+			method:  http.MethodPut,
+			handler: func(ns handler.NewsStorer) http.HandlerFunc { return handler.UpdateNewsByID(ns, policy.AllowAll{}) },
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{Editor: "user-1"}, nil)
+				mh.EXPECT().UpdateById(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, r news.Record) error {
+					*got = r
+					return nil
+				})
+			},
+			principal:      &auth.Principal{Subject: "user-42"},
+			expectedEditor: "user-42",
+		},
+		{
+			name:    "patch_records_subject",
+			method:  http.MethodPatch,
+			handler: func(ns handler.NewsStorer) http.HandlerFunc { return handler.PatchNewsByID(ns, policy.AllowAll{}) },
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{Editor: "user-1"}, nil)
+				mh.EXPECT().UpdateByIdIfUnchanged(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, _ uuid.UUID, _ time.Time, r news.Record) error {
+					*got = r
+					return nil
+				})
+			},
+			principal:      &auth.Principal{Subject: "user-42"},
+			expectedEditor: "user-42",
+		},
+		{
+			name:    "anonymous_post_leaves_editor_empty",
+			method:  http.MethodPost,
+			handler: handler.PostNews,
+			setup: func(mh *mockshandler.MockNewsStorer, got *news.Record) {
+				mh.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, r news.Record) (news.Record, error) {
+					*got = r
+					return r, nil
+				})
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			//Arrange
+			var got news.Record
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			tc.setup(mh, &got)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/news/", strings.NewReader(body))
+			if tc.method == http.MethodPatch {
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+			}
+			r.SetPathValue("news_id", "c2f92052-348f-4372-b4bc-43dbbc88445a")
+			if tc.principal != nil {
+				r = r.WithContext(auth.CtxWithPrincipal(r.Context(), *tc.principal))
+			}
+			//Act
+			tc.handler(mh)(w, r)
+			//Assert
+			if got.Editor != tc.expectedEditor {
+				t.Errorf("expected editor: %q, got editor: %q", tc.expectedEditor, got.Editor)
+			}
+			// the creator owns the news
+			if tc.method == http.MethodPost && got.Owner != tc.expectedEditor {
+				t.Errorf("expected owner: %q, got owner: %q", tc.expectedEditor, got.Owner)
+			}
+		})
+	}
+}
+
+func Test_ModifyHandlersConsultPolicy(t *testing.T) {
+	body := `{
+		"author": "test-author",
+		"title": "test-title",
+		"summary": "test-summary",
+		"content": "test-content",
+		"source": "https://google.com",
+		"created_at": "2026-01-30T18:35:43+05:30",
+		"tags": ["test-tag"]
+		}`
+	testcases := []struct {
+		name           string
+		method         string
+		handler        func(handler.NewsStorer, handler.Authorizer) http.HandlerFunc
+		setup          func(mh *mockshandler.MockNewsStorer, ma *mockshandler.MockAuthorizer)
+		expectedStatus int
+	}{
+		{
+			name:    "update_forbidden",
+			method:  http.MethodPut,
+			handler: handler.UpdateNewsByID,
+			setup: func(mh *mockshandler.MockNewsStorer, ma *mockshandler.MockAuthorizer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{Author: "someone-else"}, nil)
+				ma.EXPECT().Authorize(gomock.Any(), policy.ActionUpdate, news.Record{Author: "someone-else"}).Return(policy.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:    "patch_forbidden",
+			method:  http.MethodPatch,
+			handler: handler.PatchNewsByID,
+			setup: func(mh *mockshandler.MockNewsStorer, ma *mockshandler.MockAuthorizer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{Author: "someone-else"}, nil)
+				ma.EXPECT().Authorize(gomock.Any(), policy.ActionUpdate, news.Record{Author: "someone-else"}).Return(policy.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:    "delete_forbidden",
+			method:  http.MethodDelete,
+			handler: handler.DeleteNewsByID,
+			setup: func(mh *mockshandler.MockNewsStorer, ma *mockshandler.MockAuthorizer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{Author: "someone-else"}, nil)
+				ma.EXPECT().Authorize(gomock.Any(), policy.ActionDelete, gomock.Any()).Return(policy.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:    "authorizer_error",
+			method:  http.MethodDelete,
+			handler: handler.DeleteNewsByID,
+			setup: func(mh *mockshandler.MockNewsStorer, ma *mockshandler.MockAuthorizer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+				ma.EXPECT().Authorize(gomock.Any(), policy.ActionDelete, gomock.Any()).Return(errors.New("policy store down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:    "delete_allowed",
+			method:  http.MethodDelete,
+			handler: handler.DeleteNewsByID,
+			setup: func(mh *mockshandler.MockNewsStorer, ma *mockshandler.MockAuthorizer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+				ma.EXPECT().Authorize(gomock.Any(), policy.ActionDelete, gomock.Any()).Return(nil)
+				mh.EXPECT().DeleteById(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			//Arrange
+			ctrl := gomock.NewController(t)
+			mh := mockshandler.NewMockNewsStorer(ctrl)
+			ma := mockshandler.NewMockAuthorizer(ctrl)
+			tc.setup(mh, ma)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/news/", strings.NewReader(body))
+			if tc.method == http.MethodPatch {
+				r.Header.Set("Content-Type", "application/merge-patch+json")
+			}
+			r.SetPathValue("news_id", "c2f92052-348f-4372-b4bc-43dbbc88445a")
+			//Act
+			tc.handler(mh, ma)(w, r)
 			//Assert
 			if w.Result().StatusCode != tc.expectedStatus {
 				t.Errorf("expected status: %d, got status: %d", tc.expectedStatus, w.Result().StatusCode)
 			}
+			if tc.expectedStatus == http.StatusForbidden {
+				var p problem.Problem
+				if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+					t.Errorf("problem unmarshalling failed: %v", err)
+				}
+				if got := w.Header().Get("Content-Type"); got != problem.ContentType {
+					t.Errorf("expected content type: %s, got: %s", problem.ContentType, got)
+				}
+				if p.Status != http.StatusForbidden {
+					t.Errorf("expected problem status: %d, got: %d", http.StatusForbidden, p.Status)
+				}
+			}
 		})
 	}
 }
+
+func Test_GetAllNewsFormats(t *testing.T) {
+	records := []news.Record{{
+		Id:     uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a"),
+		Author: "test-author",
+		Title:  "test-title",
+		Tags:   []string{"go", "api"},
+	}}
+	testcases := []struct {
+		name                string
+		target              string
+		accept              string
+		streams             bool
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "json_by_default",
+			target:              "/news",
+			streams:             true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `"tags":["go","api"]`,
+		},
+		{
+			name:                "csv_by_accept",
+			target:              "/news",
+			accept:              "text/csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,author,title,summary,content,source,tags,editor,created_at,updated_at\nc2f92052-348f-4372-b4bc-43dbbc88445a,test-author,test-title,,,,go;api,",
+		},
+		{
+			name:                "xml_by_format",
+			target:              "/news?format=xml",
+			accept:              "application/json",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/xml",
+			expectedBody:        "<tags>\n      <tag>go</tag>\n      <tag>api</tag>\n    </tags>",
+		},
+		{
+			name:                "ndjson_line_per_record",
+			target:              "/news",
+			streams:             true,
+			accept:              "application/x-ndjson",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody:        `{"id":"c2f92052-348f-4372-b4bc-43dbbc88445a","author":"test-author"`,
+		},
+		{
+			name:                "not_acceptable",
+			target:              "/news",
+			accept:              "application/pdf",
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: problem.ContentType,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			//Arrange
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			if tc.expectedStatus == http.StatusOK {
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{Count: 1}, nil)
+			}
+			switch {
+			case tc.expectedStatus != http.StatusOK:
+			case tc.streams:
+				mh.EXPECT().Stream(gomock.Any(), gomock.Any()).Return(recordSeq(nil, records...))
+			default:
+				mh.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(records, nil)
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			//Act
+			handler.GetAllNews(mh)(w, r)
+			//Assert
+			if w.Result().StatusCode != tc.expectedStatus {
+				t.Errorf("expected status: %d, got status: %d", tc.expectedStatus, w.Result().StatusCode)
+			}
+			if got := w.Header().Get("Content-Type"); got != tc.expectedContentType {
+				t.Errorf("expected content type: %s, got: %s", tc.expectedContentType, got)
+			}
+			if !strings.Contains(w.Body.String(), tc.expectedBody) {
+				t.Errorf("expected body to contain: %s, got: %s", tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+// recordSeq yields the records, then err when it is not nil
+func recordSeq(err error, records ...news.Record) news.Records {
+	return func(yield func(news.Record, error) bool) {
+		for _, record := range records {
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(news.Record{}, err)
+		}
+	}
+}
+
+func Test_ReadHandlersAnswerNotModified(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC)
+	record := news.Record{Id: uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a"), UpdatedAt: updatedAt}
+	testcases := []struct {
+		name    string
+		handler func(handler.NewsStorer) http.HandlerFunc
+		setup   func(mh *mockshandler.MockNewsStorer)
+	}{
+		{
+			name:    "get_by_id",
+			handler: handler.GetNewsByID,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().FindById(gomock.Any(), record.Id).Return(record, nil).Times(2)
+			},
+		},
+		{
+			name:    "list_without_reading_rows",
+			handler: handler.GetAllNews,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{Count: 1, LastModified: updatedAt}, nil).Times(2)
+				mh.EXPECT().Stream(gomock.Any(), gomock.Any()).Return(recordSeq(nil, record))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			//Arrange
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			tc.setup(mh)
+			h := tc.handler(mh)
+			get := func(header, value string) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, "/news", nil)
+				r.SetPathValue("news_id", record.Id.String())
+				if header != "" {
+					r.Header.Set(header, value)
+				}
+				h(w, r)
+				return w
+			}
+			//Act
+			first := get("", "")
+			second := get("If-None-Match", first.Header().Get("ETag"))
+			//Assert
+			if first.Code != http.StatusOK {
+				t.Errorf("expected status: %d, got status: %d", http.StatusOK, first.Code)
+			}
+			if first.Header().Get("ETag") == "" {
+				t.Errorf("expected an etag")
+			}
+			if first.Header().Get("Last-Modified") != "Fri, 02 Oct 2026 09:00:00 GMT" {
+				t.Errorf("expected last modified, got: %s", first.Header().Get("Last-Modified"))
+			}
+			if second.Code != http.StatusNotModified {
+				t.Errorf("expected status: %d, got status: %d", http.StatusNotModified, second.Code)
+			}
+			if second.Body.Len() != 0 {
+				t.Errorf("expected empty body, got: %s", second.Body.String())
+			}
+		})
+	}
+}
+
+func Test_GetAllNewsModifiedByDelete(t *testing.T) {
+	//Arrange
+	now := time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC)
+	ns := store.New(store.WithClock(func() time.Time { return now }))
+	var created news.Record
+	for range 2 {
+		var err error
+		created, err = ns.Create(context.Background(), news.Record{
+			Author: "test-author", Title: "test-title", Summary: "test-summary", Content: "test-content",
+			Source: "https://google.com", Tags: []string{"test-tag"},
+		})
+		if err != nil {
+			t.Fatalf("create news: %v", err)
+		}
+	}
+	get := func(ifModifiedSince string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/news", nil)
+		if ifModifiedSince != "" {
+			r.Header.Set("If-Modified-Since", ifModifiedSince)
+		}
+		handler.GetAllNews(ns)(w, r)
+		return w
+	}
+	first := get("")
+	now = now.Add(time.Minute)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, "/news/", nil)
+	r.SetPathValue("news_id", created.Id.String())
+	handler.DeleteNewsByID(ns, policy.AllowAll{})(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete news: got status: %d", w.Code)
+	}
+	//Act
+	second := get(first.Header().Get("Last-Modified"))
+	//Assert
+	if second.Code != http.StatusOK {
+		t.Errorf("expected status: %d, got status: %d", http.StatusOK, second.Code)
+	}
+	if second.Header().Get("Last-Modified") != "Fri, 02 Oct 2026 09:01:00 GMT" {
+		t.Errorf("expected the deletion as last modified, got: %s", second.Header().Get("Last-Modified"))
+	}
+}
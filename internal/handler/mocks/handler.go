@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: handler.go
+// Source: internal/handler/handler.go
 
 // Package mockshandler is a generated GoMock package.
 package mockshandler
@@ -7,10 +7,12 @@ package mockshandler
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	news "github.com/logeshwarann-dev/news-api-rest/internal/news"
+	policy "github.com/logeshwarann-dev/news-api-rest/internal/policy"
 )
 
 // MockNewsStorer is a mock of NewsStorer interface.
@@ -66,18 +68,18 @@ func (mr *MockNewsStorerMockRecorder) DeleteById(arg0, arg1 interface{}) *gomock
 }
 
 // FindAll mocks base method.
-func (m *MockNewsStorer) FindAll(arg0 context.Context) ([]news.Record, error) {
+func (m *MockNewsStorer) FindAll(arg0 context.Context, arg1 news.Filter) ([]news.Record, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", arg0)
+	ret := m.ctrl.Call(m, "FindAll", arg0, arg1)
 	ret0, _ := ret[0].([]news.Record)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll.
-func (mr *MockNewsStorerMockRecorder) FindAll(arg0 interface{}) *gomock.Call {
+func (mr *MockNewsStorerMockRecorder) FindAll(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockNewsStorer)(nil).FindAll), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockNewsStorer)(nil).FindAll), arg0, arg1)
 }
 
 // FindById mocks base method.
@@ -95,6 +97,35 @@ func (mr *MockNewsStorerMockRecorder) FindById(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockNewsStorer)(nil).FindById), arg0, arg1)
 }
 
+// Stats mocks base method.
+func (m *MockNewsStorer) Stats(arg0 context.Context, arg1 news.Filter) (news.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", arg0, arg1)
+	ret0, _ := ret[0].(news.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockNewsStorerMockRecorder) Stats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockNewsStorer)(nil).Stats), arg0, arg1)
+}
+
+// Stream mocks base method.
+func (m *MockNewsStorer) Stream(arg0 context.Context, arg1 news.Filter) news.Records {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", arg0, arg1)
+	ret0, _ := ret[0].(news.Records)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockNewsStorerMockRecorder) Stream(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockNewsStorer)(nil).Stream), arg0, arg1)
+}
+
 // UpdateById mocks base method.
 func (m *MockNewsStorer) UpdateById(arg0 context.Context, arg1 uuid.UUID, arg2 news.Record) error {
 	m.ctrl.T.Helper()
@@ -108,3 +139,54 @@ func (mr *MockNewsStorerMockRecorder) UpdateById(arg0, arg1, arg2 interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockNewsStorer)(nil).UpdateById), arg0, arg1, arg2)
 }
+
+// UpdateByIdIfUnchanged mocks base method.
+func (m *MockNewsStorer) UpdateByIdIfUnchanged(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 news.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateByIdIfUnchanged", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateByIdIfUnchanged indicates an expected call of UpdateByIdIfUnchanged.
+func (mr *MockNewsStorerMockRecorder) UpdateByIdIfUnchanged(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateByIdIfUnchanged", reflect.TypeOf((*MockNewsStorer)(nil).UpdateByIdIfUnchanged), arg0, arg1, arg2, arg3)
+}
+
+// MockAuthorizer is a mock of Authorizer interface.
+type MockAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizerMockRecorder
+}
+
+// MockAuthorizerMockRecorder is the mock recorder for MockAuthorizer.
+type MockAuthorizerMockRecorder struct {
+	mock *MockAuthorizer
+}
+
+// NewMockAuthorizer creates a new mock instance.
+func NewMockAuthorizer(ctrl *gomock.Controller) *MockAuthorizer {
+	mock := &MockAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizer) EXPECT() *MockAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockAuthorizer) Authorize(arg0 context.Context, arg1 policy.Action, arg2 news.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockAuthorizerMockRecorder) Authorize(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockAuthorizer)(nil).Authorize), arg0, arg1, arg2)
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handler/webhook.go
+
+// Package mockshandler is a generated GoMock package.
+package mockshandler
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	webhook "github.com/logeshwarann-dev/news-api-rest/internal/webhook"
+)
+
+// MockWebhookStorer is a mock of WebhookStorer interface.
+type MockWebhookStorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookStorerMockRecorder
+}
+
+// MockWebhookStorerMockRecorder is the mock recorder for MockWebhookStorer.
+type MockWebhookStorerMockRecorder struct {
+	mock *MockWebhookStorer
+}
+
+// NewMockWebhookStorer creates a new mock instance.
+func NewMockWebhookStorer(ctrl *gomock.Controller) *MockWebhookStorer {
+	mock := &MockWebhookStorer{ctrl: ctrl}
+	mock.recorder = &MockWebhookStorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookStorer) EXPECT() *MockWebhookStorerMockRecorder {
+	return m.recorder
+}
+
+// Deliveries mocks base method.
+func (m *MockWebhookStorer) Deliveries(ctx context.Context, id uuid.UUID, owner string, limit int) ([]webhook.Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", ctx, id, owner, limit)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *MockWebhookStorerMockRecorder) Deliveries(ctx, id, owner, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*MockWebhookStorer)(nil).Deliveries), ctx, id, owner, limit)
+}
+
+// Subscribe mocks base method.
+func (m *MockWebhookStorer) Subscribe(arg0 context.Context, arg1 webhook.Subscription) (webhook.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", arg0, arg1)
+	ret0, _ := ret[0].(webhook.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockWebhookStorerMockRecorder) Subscribe(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockWebhookStorer)(nil).Subscribe), arg0, arg1)
+}
+
+// Subscription mocks base method.
+func (m *MockWebhookStorer) Subscription(ctx context.Context, id uuid.UUID, owner string) (webhook.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscription", ctx, id, owner)
+	ret0, _ := ret[0].(webhook.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscription indicates an expected call of Subscription.
+func (mr *MockWebhookStorerMockRecorder) Subscription(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscription", reflect.TypeOf((*MockWebhookStorer)(nil).Subscription), ctx, id, owner)
+}
+
+// Subscriptions mocks base method.
+func (m *MockWebhookStorer) Subscriptions(ctx context.Context, owner string) ([]webhook.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscriptions", ctx, owner)
+	ret0, _ := ret[0].([]webhook.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscriptions indicates an expected call of Subscriptions.
+func (mr *MockWebhookStorerMockRecorder) Subscriptions(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscriptions", reflect.TypeOf((*MockWebhookStorer)(nil).Subscriptions), ctx, owner)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockWebhookStorer) Unsubscribe(ctx context.Context, id uuid.UUID, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, id, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockWebhookStorerMockRecorder) Unsubscribe(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockWebhookStorer)(nil).Unsubscribe), ctx, id, owner)
+}
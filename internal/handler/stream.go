@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+)
+
+// GetNewsStream pushes the news changes as server-sent events, only those of
+// news carrying the tag query parameter when set. Clients resuming with
+// Last-Event-ID get the events they missed, or a reset event telling them to
+// reload when those are no longer buffered. A comment is sent every
+// heartbeat to keep idle connections open.
+func GetNewsStream(bus *events.Bus, heartbeat time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("getnewsstream request recieved")
+		tag := r.URL.Query().Get("tag")
+		lastId := r.Header.Get("Last-Event-ID")
+
+		sub, replay, complete := bus.Subscribe(lastId)
+		if sub == nil {
+			problem.Write(w, problem.New(http.StatusServiceUnavailable, "server is shutting down"))
+			return
+		}
+		defer sub.Close()
+
+		rc := http.NewResponseController(w)
+		// the stream outlives the server write timeout
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Error("failed clearing write deadline", "error", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		send := func(event events.Event) error {
+			if tag != "" && !slices.Contains(event.Record.Tags, tag) {
+				return nil
+			}
+			data, err := json.Marshal(event.Record)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+			return err
+		}
+		flush := func() error {
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+			return nil
+		}
+
+		if lastId != "" && !complete {
+			io.WriteString(w, "event: reset\ndata: {}\n\n")
+		}
+		for _, event := range replay {
+			if err := send(event); err != nil {
+				log.Info("news stream closed", "error", err)
+				return
+			}
+		}
+		if err := flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			var err error
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					// the bus closed on shutdown or dropped a subscriber too slow to keep up
+					return
+				}
+				err = send(event)
+			case <-ticker.C:
+				_, err = io.WriteString(w, ": heartbeat\n\n")
+			}
+			if err == nil {
+				err = flush()
+			}
+			if err != nil {
+				log.Info("news stream closed", "error", err)
+				return
+			}
+		}
+	}
+}
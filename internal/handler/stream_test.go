@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openStream connects to the stream served by srv and returns a reader of its messages
+func openStream(tb testing.TB, srv *httptest.Server, query, lastId string) func() string {
+	tb.Helper()
+	req, err := http.NewRequest(http.MethodGet, srv.URL+query, nil)
+	require.NoError(tb, err)
+	if lastId != "" {
+		req.Header.Set("Last-Event-ID", lastId)
+	}
+	resp, err := srv.Client().Do(req)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { resp.Body.Close() })
+	require.Equal(tb, http.StatusOK, resp.StatusCode)
+	assert.Equal(tb, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+	return func() string {
+		var lines []string
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, scanner.Text())
+		}
+		return ""
+	}
+}
+
+func Test_GetNewsStream(t *testing.T) {
+	bus := events.NewBus(10)
+	srv := httptest.NewServer(handler.GetNewsStream(bus, time.Hour))
+	t.Cleanup(srv.Close)
+
+	all := openStream(t, srv, "", "")
+	tagged := openStream(t, srv, "?tag=go", "")
+
+	first := bus.Publish(outbox.NewsCreated, news.Record{Title: "Batman", Tags: []string{"marvel"}})
+	second := bus.Publish(outbox.NewsDeleted, news.Record{Title: "Robin", Tags: []string{"go"}})
+
+	msg := all()
+	assert.Contains(t, msg, "id: "+first.Id+"\nevent: news.created\ndata: {")
+	assert.Contains(t, msg, `"title":"Batman"`)
+	assert.Contains(t, all(), "id: "+second.Id+"\nevent: news.deleted\n")
+	assert.Contains(t, tagged(), "id: "+second.Id+"\n", "news without the tag are skipped")
+
+	t.Run("resume_from_last_event_id", func(t *testing.T) {
+		resumed := openStream(t, srv, "", first.Id)
+		assert.Contains(t, resumed(), "id: "+second.Id+"\n")
+	})
+
+	t.Run("reset_when_events_are_missing", func(t *testing.T) {
+		resumed := openStream(t, srv, "", "other-1")
+		assert.Equal(t, "event: reset\ndata: {}", resumed())
+	})
+
+	t.Run("end_when_bus_closes", func(t *testing.T) {
+		bus.Close()
+		assert.Empty(t, all())
+
+		resp, err := srv.Client().Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+}
+
+func Test_GetNewsStreamHeartbeat(t *testing.T) {
+	srv := httptest.NewServer(handler.GetNewsStream(events.NewBus(10), 10*time.Millisecond))
+	t.Cleanup(srv.Close)
+
+	stream := openStream(t, srv, "", "")
+	assert.Equal(t, ": heartbeat", stream())
+}
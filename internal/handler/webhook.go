@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/model"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/logeshwarann-dev/news-api-rest/internal/render"
+	"github.com/logeshwarann-dev/news-api-rest/internal/validator"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
+)
+
+//go:generate mockgen -source=webhook.go -destination=mocks/webhook.go -package=mockshandler
+
+// WebhookStorer represents the webhook subscription operations, an empty
+// owner stands for every owner
+type WebhookStorer interface {
+	//Subscribe to news events
+	Subscribe(context.Context, webhook.Subscription) (webhook.Subscription, error)
+	//Get all Subscriptions of an owner
+	Subscriptions(ctx context.Context, owner string) ([]webhook.Subscription, error)
+	//Get Subscription By Id
+	Subscription(ctx context.Context, id uuid.UUID, owner string) (webhook.Subscription, error)
+	//Delete Subscription By Id
+	Unsubscribe(ctx context.Context, id uuid.UUID, owner string) error
+	//Get the latest Deliveries of a Subscription
+	Deliveries(ctx context.Context, id uuid.UUID, owner string, limit int) ([]webhook.Delivery, error)
+}
+
+func PostWebhook(ws WebhookStorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("postwebhook request recieved")
+		var req model.WebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Error("request decode failed, invalid request", "error", err)
+			if p, ok := problem.TooLarge(err); ok {
+				problem.Write(w, p)
+				return
+			}
+			problem.Write(w, problem.New(http.StatusBadRequest, "invalid json body"))
+			return
+		}
+		sub, err := validator.ValidateWebhookRequest(req)
+		if err != nil {
+			log.Error("validation error, invalid request", "error", err)
+			problem.Write(w, problem.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		sub.Owner = editor(ctx)
+		created, err := ws.Subscribe(ctx, sub)
+		if err != nil {
+			log.Error("failed adding webhook subscription", "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		w.Header().Set("Location", path.Join(r.URL.Path, created.Id.String()))
+		render.JSON.Write(w, r, http.StatusCreated, created)
+	}
+}
+
+func GetWebhooks(ws WebhookStorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("getwebhooks request recieved")
+		subs, err := ws.Subscriptions(ctx, owner(ctx))
+		if err != nil {
+			log.Error("failed listing webhook subscriptions", "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		render.JSON.Write(w, r, http.StatusOK, model.AllWebhooks{Webhooks: subs})
+	}
+}
+
+func GetWebhookByID(ws WebhookStorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("getwebhookbyid request recieved")
+		id, ok := webhookId(w, r)
+		if !ok {
+			return
+		}
+		sub, err := ws.Subscription(ctx, id, owner(ctx))
+		if err != nil {
+			log.Error("failed finding webhook subscription", "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		render.JSON.Write(w, r, http.StatusOK, sub)
+	}
+}
+
+func DeleteWebhookByID(ws WebhookStorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("deletewebhookbyid request recieved")
+		id, ok := webhookId(w, r)
+		if !ok {
+			return
+		}
+		if err := ws.Unsubscribe(ctx, id, owner(ctx)); err != nil {
+			log.Error("failed deleting webhook subscription", "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func GetWebhookDeliveries(ws WebhookStorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := logger.FromContext(ctx)
+		log.Info("getwebhookdeliveries request recieved")
+		id, ok := webhookId(w, r)
+		if !ok {
+			return
+		}
+		limit, err := validator.ValidateDeliveryLimit(r.URL.Query())
+		if err != nil {
+			log.Error("invalid limit", "error", err)
+			problem.Write(w, problem.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		deliveries, err := ws.Deliveries(ctx, id, owner(ctx), limit)
+		if err != nil {
+			log.Error("failed listing webhook deliveries", "error", err)
+			writeWebhookError(w, err)
+			return
+		}
+		render.JSON.Write(w, r, http.StatusOK, model.AllDeliveries{Deliveries: deliveries})
+	}
+}
+
+// webhookId parses the id of the path and answers 400 when it is invalid
+func webhookId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("webhook_id"))
+	if err != nil {
+		problem.Write(w, problem.New(http.StatusBadRequest, "invalid webhook id"))
+		return id, false
+	}
+	return id, true
+}
+
+// owner returns the subscriptions the caller may see, those it created
+// unless it is an admin or authentication is disabled
+func owner(ctx context.Context) string {
+	p, ok := auth.FromContext(ctx)
+	if !ok || slices.Contains(p.Scopes, auth.ScopeAdmin) {
+		return ""
+	}
+	return p.Subject
+}
+
+// writeWebhookError answers 404 for unknown subscriptions, 500 otherwise
+func writeWebhookError(w http.ResponseWriter, err error) {
+	if errors.Is(err, webhook.ErrNotFound) {
+		problem.Write(w, problem.New(http.StatusNotFound, err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+}
@@ -0,0 +1,188 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	mockshandler "github.com/logeshwarann-dev/news-api-rest/internal/handler/mocks"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PostWebhook(t *testing.T) {
+	id := uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a")
+	testcases := []struct {
+		name           string
+		body           string
+		setup          func(mw *mockshandler.MockWebhookStorer)
+		expectedStatus int
+	}{
+		{
+			name:           "incorrect_request_body",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid_request",
+			body:           `{"url": "https://partner.example.com", "events": ["news.read"], "secret": "0123456789abcdef"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "db_error",
+			body: `{"url": "https://partner.example.com", "events": ["news.created"], "secret": "0123456789abcdef"}`,
+			setup: func(mw *mockshandler.MockWebhookStorer) {
+				mw.EXPECT().Subscribe(gomock.Any(), gomock.Any()).Return(webhook.Subscription{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "valid_request",
+			body: `{"url": "https://partner.example.com", "events": ["news.created"], "tag": "go", "secret": "0123456789abcdef"}`,
+			setup: func(mw *mockshandler.MockWebhookStorer) {
+				mw.EXPECT().Subscribe(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, sub webhook.Subscription) (webhook.Subscription, error) {
+					assert.Equal(t, "key-1", sub.Owner)
+					assert.Equal(t, "0123456789abcdef", sub.Secret)
+					sub.Id = id
+					return sub, nil
+				})
+			},
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mw := mockshandler.NewMockWebhookStorer(gomock.NewController(t))
+			if tc.setup != nil {
+				tc.setup(mw)
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/v1/webhooks", strings.NewReader(tc.body))
+			r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1", Scopes: []auth.Scope{auth.ScopeWebhooks}}))
+
+			handler.PostWebhook(mw)(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus == http.StatusCreated {
+				assert.Equal(t, "/v1/webhooks/"+id.String(), w.Header().Get("Location"))
+				assert.NotContains(t, w.Body.String(), "0123456789abcdef", "the secret is never sent back")
+			}
+		})
+	}
+}
+
+func Test_WebhookOwner(t *testing.T) {
+	testcases := []struct {
+		name          string
+		principal     *auth.Principal
+		expectedOwner string
+	}{
+		{name: "see_own_subscriptions", principal: &auth.Principal{Subject: "key-1", Scopes: []auth.Scope{auth.ScopeWebhooks}}, expectedOwner: "key-1"},
+		{name: "admin_sees_every_subscription", principal: &auth.Principal{Subject: "key-2", Scopes: []auth.Scope{auth.ScopeAdmin}}},
+		{name: "anonymous_sees_every_subscription_without_auth"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mw := mockshandler.NewMockWebhookStorer(gomock.NewController(t))
+			mw.EXPECT().Subscriptions(gomock.Any(), tc.expectedOwner).Return([]webhook.Subscription{{URL: "https://partner.example.com"}}, nil)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+			if tc.principal != nil {
+				r = r.WithContext(auth.CtxWithPrincipal(r.Context(), *tc.principal))
+			}
+
+			handler.GetWebhooks(mw)(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var body struct {
+				Webhooks []webhook.Subscription `json:"webhooks"`
+			}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Len(t, body.Webhooks, 1)
+		})
+	}
+}
+
+func Test_WebhookByID(t *testing.T) {
+	id := uuid.MustParse("c2f92052-348f-4372-b4bc-43dbbc88445a")
+	testcases := []struct {
+		name           string
+		method         string
+		path           string
+		handler        func(handler.WebhookStorer) http.HandlerFunc
+		setup          func(mw *mockshandler.MockWebhookStorer)
+		expectedStatus int
+	}{
+		{
+			name:           "invalid_id",
+			method:         http.MethodGet,
+			path:           "/webhooks/not-an-id",
+			handler:        handler.GetWebhookByID,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "unknown_subscription",
+			method:  http.MethodGet,
+			path:    "/webhooks/" + id.String(),
+			handler: handler.GetWebhookByID,
+			setup: func(mw *mockshandler.MockWebhookStorer) {
+				mw.EXPECT().Subscription(gomock.Any(), id, "").Return(webhook.Subscription{}, webhook.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "delete_subscription",
+			method:  http.MethodDelete,
+			path:    "/webhooks/" + id.String(),
+			handler: handler.DeleteWebhookByID,
+			setup: func(mw *mockshandler.MockWebhookStorer) {
+				mw.EXPECT().Unsubscribe(gomock.Any(), id, "").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid_delivery_limit",
+			method:         http.MethodGet,
+			path:           "/webhooks/" + id.String() + "/deliveries?limit=0",
+			handler:        handler.GetWebhookDeliveries,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "list_deliveries",
+			method:  http.MethodGet,
+			path:    "/webhooks/" + id.String() + "/deliveries?limit=5",
+			handler: handler.GetWebhookDeliveries,
+			setup: func(mw *mockshandler.MockWebhookStorer) {
+				mw.EXPECT().Deliveries(gomock.Any(), id, "", 5).Return([]webhook.Delivery{{Id: 1, Status: webhook.StatusDelivered}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mw := mockshandler.NewMockWebhookStorer(gomock.NewController(t))
+			if tc.setup != nil {
+				tc.setup(mw)
+			}
+			mux := http.NewServeMux()
+			mux.Handle(tc.method+" /webhooks/{webhook_id}", tc.handler(mw))
+			mux.Handle(tc.method+" /webhooks/{webhook_id}/deliveries", tc.handler(mw))
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
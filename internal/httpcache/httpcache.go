@@ -0,0 +1,83 @@
+// Package httpcache implements validators and conditional requests (RFC 9110 section 13).
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ETag returns a strong entity tag derived from the parts, which must
+// together identify the representation, including its format
+func ETag(parts ...any) string {
+	h := sha256.New()
+	for _, p := range parts {
+		// the separator keeps ("ab", "c") and ("a", "bc") apart
+		fmt.Fprintf(h, "%v\x00", p)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// NotModified sets the ETag and Last-Modified validators, when given, and
+// answers 304 when the client copy is still current. If-None-Match takes
+// precedence over If-Modified-Since.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		// http dates have a one second resolution
+		lastModified = lastModified.UTC().Truncate(time.Second)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" || !matchesAny(inm, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err != nil || lastModified.IsZero() || lastModified.After(since) {
+			return false
+		}
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// Matches reports whether the If-Match precondition of the request holds for
+// a resource whose representations carry the given entity tags. A request
+// without If-Match always holds; otherwise the strong comparison applies, so
+// weak tags never match.
+func Matches(r *http.Request, etags ...string) bool {
+	im := r.Header.Get("If-Match")
+	if im == "" {
+		return true
+	}
+	for _, candidate := range strings.Split(im, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || slices.Contains(etags, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny applies the weak comparison of If-None-Match to a list of entity tags
+func matchesAny(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package httpcache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/httpcache"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ETag(t *testing.T) {
+	etag := httpcache.ETag("news", "json", 42)
+
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, etag, httpcache.ETag("news", "json", 42))
+	assert.NotEqual(t, etag, httpcache.ETag("news", "xml", 42))
+	assert.NotEqual(t, httpcache.ETag("ab", "c"), httpcache.ETag("a", "bc"))
+}
+
+func Test_NotModified(t *testing.T) {
+	etag := `"abc"`
+	lastModified := time.Date(2026, 10, 2, 9, 0, 0, 500, time.UTC)
+	testcases := []struct {
+		name            string
+		method          string
+		headers         map[string]string
+		etag            string
+		lastModified    time.Time
+		expectedNotMod  bool
+		expectedHeaders map[string]string
+	}{
+		{
+			name:            "set_validators",
+			method:          http.MethodGet,
+			etag:            etag,
+			lastModified:    lastModified,
+			expectedHeaders: map[string]string{"ETag": etag, "Last-Modified": "Fri, 02 Oct 2026 09:00:00 GMT"},
+		},
+		{
+			name:           "matching_etag",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-None-Match": `"xyz", W/"abc"`},
+			etag:           etag,
+			expectedNotMod: true,
+		},
+		{
+			name:           "any_etag",
+			method:         http.MethodHead,
+			headers:        map[string]string{"If-None-Match": "*"},
+			etag:           etag,
+			expectedNotMod: true,
+		},
+		{
+			name:    "other_etag",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `"xyz"`},
+			etag:    etag,
+		},
+		{
+			name:           "not_modified_since",
+			method:         http.MethodGet,
+			headers:        map[string]string{"If-Modified-Since": "Fri, 02 Oct 2026 09:00:00 GMT"},
+			lastModified:   lastModified,
+			expectedNotMod: true,
+		},
+		{
+			name:         "modified_since",
+			method:       http.MethodGet,
+			headers:      map[string]string{"If-Modified-Since": "Fri, 02 Oct 2026 08:59:59 GMT"},
+			lastModified: lastModified,
+		},
+		{
+			name:   "etag_takes_precedence",
+			method: http.MethodGet,
+			headers: map[string]string{
+				"If-None-Match":     `"xyz"`,
+				"If-Modified-Since": "Fri, 02 Oct 2026 09:00:00 GMT",
+			},
+			etag:         etag,
+			lastModified: lastModified,
+		},
+		{
+			name:    "ignore_unsafe_methods",
+			method:  http.MethodPut,
+			headers: map[string]string{"If-None-Match": "*"},
+			etag:    etag,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/news", nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			got := httpcache.NotModified(w, r, tc.etag, tc.lastModified)
+
+			assert.Equal(t, tc.expectedNotMod, got)
+			if tc.expectedNotMod {
+				assert.Equal(t, http.StatusNotModified, w.Code)
+			}
+			for k, v := range tc.expectedHeaders {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+		})
+	}
+}
+
+func Test_Matches(t *testing.T) {
+	testcases := []struct {
+		name     string
+		ifMatch  string
+		expected bool
+	}{
+		{name: "no_precondition", expected: true},
+		{name: "any_etag", ifMatch: "*", expected: true},
+		{name: "one_of_the_etags", ifMatch: `"xyz", "def"`, expected: true},
+		{name: "other_etag", ifMatch: `"xyz"`},
+		{name: "weak_etag", ifMatch: `W/"abc"`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/news", nil)
+			if tc.ifMatch != "" {
+				r.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			assert.Equal(t, tc.expected, httpcache.Matches(r, `"abc"`, `"def"`))
+		})
+	}
+}
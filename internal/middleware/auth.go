@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+)
+
+// Authenticate resolves the bearer token of the request into a principal.
+// Requests without an Authorization header pass through unauthenticated so
+// that RequireScope can decide per route.
+func Authenticate(a auth.Authenticator, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		slogger := logger.FromContext(r.Context())
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			slogger.Info("malformed authorization header")
+			unauthorized(w)
+			return
+		}
+		principal, err := a.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				slogger.Info("invalid credentials", "error", err)
+				unauthorized(w)
+				return
+			}
+			slogger.Error("authentication failed", "error", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r = r.WithContext(auth.CtxWithPrincipal(r.Context(), principal))
+		next.ServeHTTP(w, r)
+	}
+}
+
+// RequireScope rejects requests whose principal was not granted the scope
+func RequireScope(scope auth.Scope, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		if !ok {
+			unauthorized(w)
+			return
+		}
+		if !principal.HasScope(scope) {
+			logger.FromContext(r.Context()).Info("missing scope", "subject", principal.Subject, "scope", scope)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="news-api"`)
+	w.WriteHeader(http.StatusUnauthorized)
+}
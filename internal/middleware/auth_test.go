@@ -0,0 +1,129 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Authenticate(t *testing.T) {
+	authenticator := auth.AuthenticatorFunc(func(_ context.Context, token string) (auth.Principal, error) {
+		switch token {
+		case "valid":
+			return auth.Principal{Subject: "key-1", Scopes: []auth.Scope{auth.ScopeNewsRead}}, nil
+		case "broken":
+			return auth.Principal{}, errors.New("db down")
+		}
+		return auth.Principal{}, auth.ErrInvalidCredentials
+	})
+	testcases := []struct {
+		name             string
+		header           string
+		expectedStatus   int
+		expectedSubject  string
+		expectsPrincipal bool
+	}{
+		{
+			name:           "pass_through_without_header",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "reject_non_bearer_scheme",
+			header:         "Basic dXNlcjpwYXNz",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "reject_invalid_token",
+			header:         "Bearer unknown",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "return_error_on_authenticator_failure",
+			header:         "Bearer broken",
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:             "set_principal_for_valid_token",
+			header:           "Bearer valid",
+			expectedStatus:   http.StatusOK,
+			expectedSubject:  "key-1",
+			expectsPrincipal: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotPrincipal bool
+			var gotSubject string
+			next := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				p, ok := auth.FromContext(r.Context())
+				gotPrincipal, gotSubject = ok, p.Subject
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/news", nil)
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+
+			middleware.Authenticate(authenticator, next)(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			assert.Equal(t, tc.expectsPrincipal, gotPrincipal)
+			assert.Equal(t, tc.expectedSubject, gotSubject)
+			if tc.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func Test_RequireScope(t *testing.T) {
+	testcases := []struct {
+		name           string
+		principal      *auth.Principal
+		expectedStatus int
+	}{
+		{
+			name:           "reject_anonymous_request",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "reject_missing_scope",
+			principal:      &auth.Principal{Scopes: []auth.Scope{auth.ScopeNewsRead}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "allow_granted_scope",
+			principal:      &auth.Principal{Scopes: []auth.Scope{auth.ScopeNewsDelete}},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "allow_admin",
+			principal:      &auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/news/1", nil)
+			if tc.principal != nil {
+				r = r.WithContext(auth.CtxWithPrincipal(r.Context(), *tc.principal))
+			}
+
+			middleware.RequireScope(auth.ScopeNewsDelete, next)(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+		})
+	}
+}
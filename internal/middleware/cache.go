@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+)
+
+// CachePolicy holds the Cache-Control values of successful reads, an empty value sends none
+type CachePolicy struct {
+	// Public applies to anonymous requests
+	Public string
+	// Private applies to authenticated requests
+	Private string
+}
+
+// CacheControl sets Cache-Control on responses the handler left without one.
+// Successful GET and HEAD responses, 304 included, get the public or private
+// policy depending on whether the request is authenticated, so it must run
+// after Authenticate. Other responses get no-store.
+func CacheControl(policy CachePolicy, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value := "no-store"
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			value = policy.Public
+			if _, ok := auth.FromContext(r.Context()); ok {
+				value = policy.Private
+			}
+		}
+		next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, value: value}, r)
+	}
+}
+
+// cacheControlWriter fills in Cache-Control when the status is written
+type cacheControlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (c *cacheControlWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+		h := c.Header()
+		if h.Get("Cache-Control") == "" {
+			switch {
+			case status != http.StatusNotModified && (status < 200 || status >= 300):
+				h.Set("Cache-Control", "no-store")
+			case c.value != "":
+				h.Set("Cache-Control", c.value)
+			}
+		}
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *cacheControlWriter) Write(b []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer to flush streamed responses
+func (c *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
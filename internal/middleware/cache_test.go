@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CacheControl(t *testing.T) {
+	policy := middleware.CachePolicy{Public: "public, max-age=60", Private: "private, no-cache"}
+	testcases := []struct {
+		name          string
+		policy        middleware.CachePolicy
+		method        string
+		authenticated bool
+		handler       http.HandlerFunc
+		expected      string
+	}{
+		{
+			name:     "public_read",
+			policy:   policy,
+			method:   http.MethodGet,
+			handler:  func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("{}")) },
+			expected: "public, max-age=60",
+		},
+		{
+			name:          "private_read",
+			policy:        policy,
+			method:        http.MethodGet,
+			authenticated: true,
+			handler:       func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) },
+			expected:      "private, no-cache",
+		},
+		{
+			name:          "not_modified",
+			policy:        policy,
+			method:        http.MethodHead,
+			authenticated: true,
+			handler:       func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotModified) },
+			expected:      "private, no-cache",
+		},
+		{
+			name:     "failed_read",
+			policy:   policy,
+			method:   http.MethodGet,
+			handler:  func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTooManyRequests) },
+			expected: "no-store",
+		},
+		{
+			name:     "write",
+			policy:   policy,
+			method:   http.MethodPost,
+			handler:  func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) },
+			expected: "no-store",
+		},
+		{
+			name:   "keep_handler_value",
+			policy: policy,
+			method: http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "no-cache")
+				w.WriteHeader(http.StatusOK)
+			},
+			expected: "no-cache",
+		},
+		{
+			name:    "empty_policy",
+			method:  http.MethodGet,
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) },
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/news", nil)
+			if tc.authenticated {
+				r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1"}))
+			}
+
+			middleware.CacheControl(tc.policy, tc.handler)(w, r)
+
+			assert.Equal(t, tc.expected, w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func Test_CacheControlKeepsFlusher(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/news", nil)
+
+	middleware.CacheControl(middleware.CachePolicy{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}))(w, r)
+
+	assert.True(t, w.Flushed)
+}
@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+)
+
+// compressor is a pooled response encoder
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// encodings lists the supported content codings by server preference
+var encodings = []struct {
+	name string
+	pool *sync.Pool
+}{
+	{"br", &sync.Pool{New: func() any { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }}},
+	{"zstd", &sync.Pool{New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		return zstdCompressor{w}
+	}}},
+	{"gzip", &sync.Pool{New: func() any { return gzip.NewWriter(nil) }}},
+}
+
+// zstdCompressor adapts the zstd encoder, whose Reset has no return value in compressor
+type zstdCompressor struct {
+	*zstd.Encoder
+}
+
+func (z zstdCompressor) Reset(w io.Writer) { z.Encoder.Reset(w) }
+
+// Compress encodes responses of at least minSize bytes with the best coding
+// the client accepts among br, zstd and gzip, leaving already compressed
+// media types alone. Streamed responses are compressed from their first flush.
+// It also decodes gzip request bodies, failing their reads past maxBodySize
+// decoded bytes like LimitBody does.
+func Compress(minSize int, maxBodySize int64, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch ce := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); ce {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				problem.Write(w, problem.New(http.StatusBadRequest, "invalid gzip request body"))
+				return
+			}
+			defer zr.Close()
+			r.Body = http.MaxBytesReader(w, struct {
+				io.Reader
+				io.Closer
+			}{zr, r.Body}, maxBodySize)
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+		default:
+			w.Header().Set("Accept-Encoding", "gzip")
+			problem.Write(w, problem.New(http.StatusUnsupportedMediaType, "unsupported content encoding "+ce))
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding < 0 || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, r: r, encoding: encoding, minSize: minSize}
+		next.ServeHTTP(cw, r)
+		cw.close()
+	}
+}
+
+// negotiateEncoding returns the index in encodings of the coding to use, -1 for identity
+func negotiateEncoding(header string) int {
+	best, bestQ := -1, 0.0
+	wildcard := -1.0
+	qs := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		qs[name] = q
+	}
+	for i, e := range encodings {
+		q, ok := qs[e.name]
+		if !ok {
+			q = max(wildcard, 0)
+		}
+		// ties keep the server preference
+		if q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+	return best
+}
+
+// alreadyCompressed reports media types gaining nothing from another coding
+func alreadyCompressed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "image/svg+xml":
+		return false
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "audio/"):
+		return true
+	}
+	switch mediaType {
+	case "application/gzip", "application/x-gzip", "application/zip", "application/zstd",
+		"application/x-brotli", "application/x-7z-compressed", "font/woff", "font/woff2":
+		return true
+	}
+	return false
+}
+
+// compressWriter holds the body back until it knows whether it is worth compressing
+type compressWriter struct {
+	http.ResponseWriter
+	r        *http.Request
+	encoding int
+	minSize  int
+
+	status  int
+	decided bool
+	buf     bytes.Buffer
+	enc     compressor
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	if c.status != 0 {
+		return
+	}
+	c.status = status
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		c.decide(false)
+	}
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.decided {
+		if c.enc != nil {
+			return c.enc.Write(b)
+		}
+		return c.ResponseWriter.Write(b)
+	}
+	c.buf.Write(b)
+	if c.buf.Len() >= c.minSize {
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush starts compressing a streamed response before it reaches minSize
+func (c *compressWriter) Flush() {
+	if c.status == 0 {
+		c.WriteHeader(http.StatusOK)
+	}
+	if !c.decided {
+		c.decide(true)
+	}
+	if c.enc != nil {
+		c.enc.Flush()
+	}
+	http.NewResponseController(c.ResponseWriter).Flush()
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// decide sends the headers, compressing when wanted and worthwhile, then the buffered body
+func (c *compressWriter) decide(compress bool) error {
+	c.decided = true
+	h := c.Header()
+	if compress && h.Get("Content-Encoding") == "" && !alreadyCompressed(h.Get("Content-Type")) {
+		e := encodings[c.encoding]
+		c.enc = e.pool.Get().(compressor)
+		c.enc.Reset(c.ResponseWriter)
+		h.Set("Content-Encoding", e.name)
+		h.Del("Content-Length")
+		// the compressed bytes differ, so the tag can no longer be strong
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+	}
+	c.ResponseWriter.WriteHeader(c.status)
+	if c.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if c.enc != nil {
+		_, err = c.enc.Write(c.buf.Bytes())
+	} else {
+		_, err = c.ResponseWriter.Write(c.buf.Bytes())
+	}
+	c.buf.Reset()
+	return err
+}
+
+// close sends a body smaller than minSize as is, or terminates the compressed stream
+func (c *compressWriter) close() {
+	if c.status == 0 {
+		return
+	}
+	if !c.decided {
+		c.decide(false)
+		return
+	}
+	if c.enc == nil {
+		return
+	}
+	if err := c.enc.Close(); err != nil {
+		logger.FromContext(c.r.Context()).Error("failed closing compressed response", "error", err)
+	}
+	c.enc.Reset(nil)
+	encodings[c.encoding].pool.Put(c.enc)
+}
@@ -0,0 +1,190 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(tb testing.TB, encoding string, body []byte) string {
+	tb.Helper()
+	var r io.Reader
+	switch encoding {
+	case "gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(tb, err)
+		r = zr
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		require.NoError(tb, err)
+		defer zr.Close()
+		r = zr
+	default:
+		return string(body)
+	}
+	b, err := io.ReadAll(r)
+	require.NoError(tb, err)
+	return string(b)
+}
+
+func Test_Compress(t *testing.T) {
+	large := strings.Repeat(`{"title":"breaking news"}`, 100)
+	testcases := []struct {
+		name             string
+		acceptEncoding   string
+		method           string
+		contentType      string
+		status           int
+		body             string
+		expectedEncoding string
+	}{
+		{name: "prefer_brotli", acceptEncoding: "gzip, deflate, br, zstd", body: large, expectedEncoding: "br"},
+		{name: "highest_quality", acceptEncoding: "br;q=0.5, zstd;q=0.8, gzip", body: large, expectedEncoding: "gzip"},
+		{name: "zstd_only", acceptEncoding: "zstd", body: large, expectedEncoding: "zstd"},
+		{name: "wildcard", acceptEncoding: "*", body: large, expectedEncoding: "br"},
+		{name: "refused_codings", acceptEncoding: "br;q=0, zstd;q=0, *;q=0.5", body: large, expectedEncoding: "gzip"},
+		{name: "identity_without_header", body: large},
+		{name: "unknown_codings", acceptEncoding: "deflate, compress", body: large},
+		{name: "skip_small_body", acceptEncoding: "gzip", body: `{"title":"short"}`},
+		{name: "skip_compressed_type", acceptEncoding: "gzip", contentType: "image/png", body: large},
+		{name: "skip_not_modified", acceptEncoding: "gzip", status: http.StatusNotModified},
+		{name: "skip_head", acceptEncoding: "gzip", method: http.MethodHead, body: large},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(method, "/news", nil)
+			if tc.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			h := middleware.Compress(1024, 1<<20, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType := tc.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				w.Header().Set("Content-Type", contentType)
+				w.Header().Set("ETag", `"abc"`)
+				if tc.status != 0 {
+					w.WriteHeader(tc.status)
+				}
+				// written in pieces as encoders do
+				for chunk := range slicesChunk(tc.body, 100) {
+					w.Write([]byte(chunk))
+				}
+			}))
+
+			h(w, r)
+
+			assert.Equal(t, tc.expectedEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			if tc.expectedEncoding != "" {
+				assert.Equal(t, `W/"abc"`, w.Header().Get("ETag"))
+				assert.Less(t, w.Body.Len(), len(tc.body))
+			} else {
+				assert.Equal(t, `"abc"`, w.Header().Get("ETag"))
+			}
+			if method != http.MethodHead {
+				assert.Equal(t, tc.body, decode(t, tc.expectedEncoding, w.Body.Bytes()))
+			}
+		})
+	}
+}
+
+// slicesChunk yields s in pieces of at most n bytes
+func slicesChunk(s string, n int) func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		for len(s) > 0 {
+			end := min(n, len(s))
+			if !yield(s[:end]) {
+				return
+			}
+			s = s[end:]
+		}
+	}
+}
+
+func Test_CompressStreamedResponse(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/news", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	var flushedBeforeEnd int
+
+	middleware.Compress(1024, 1<<20, http.HandlerFunc(func(cw http.ResponseWriter, r *http.Request) {
+		cw.Header().Set("Content-Type", "application/x-ndjson")
+		cw.Write([]byte("{\"n\":1}\n"))
+		assert.NoError(t, http.NewResponseController(cw).Flush())
+		flushedBeforeEnd = len(decodePartialGzip(t, w.Body.Bytes()))
+		cw.Write([]byte("{\"n\":2}\n"))
+	}))(w, r)
+
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, len("{\"n\":1}\n"), flushedBeforeEnd, "the first item reached the client before the end")
+	assert.Equal(t, "{\"n\":1}\n{\"n\":2}\n", decode(t, "gzip", w.Body.Bytes()))
+}
+
+// decodePartialGzip decodes what a client can read from an unterminated gzip stream
+func decodePartialGzip(tb testing.TB, body []byte) []byte {
+	tb.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	require.NoError(tb, err)
+	b, _ := io.ReadAll(zr)
+	return b
+}
+
+func Test_CompressDecodesRequests(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`[{"title":"bulk"}]`))
+	zw.Close()
+	testcases := []struct {
+		name            string
+		encoding        string
+		body            []byte
+		expectedStatus  int
+		expectedRequest string
+	}{
+		{name: "gzip_body", encoding: "gzip", body: gz.Bytes(), expectedStatus: http.StatusOK, expectedRequest: `[{"title":"bulk"}]`},
+		{name: "plain_body", body: []byte(`{}`), expectedStatus: http.StatusOK, expectedRequest: `{}`},
+		{name: "invalid_gzip_body", encoding: "gzip", body: []byte(`{}`), expectedStatus: http.StatusBadRequest},
+		{name: "unsupported_encoding", encoding: "br", body: []byte(`{}`), expectedStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/news", bytes.NewReader(tc.body))
+			if tc.encoding != "" {
+				r.Header.Set("Content-Encoding", tc.encoding)
+			}
+			var got string
+
+			middleware.Compress(1024, 1<<20, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Empty(t, r.Header.Get("Content-Encoding"))
+				got = string(b)
+			}))(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedRequest, got)
+		})
+	}
+}
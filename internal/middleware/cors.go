@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig describes the cross origin requests browsers are allowed to make
+type CORSConfig struct {
+	// AllowedOrigins holds exact origins, "*" for any origin or wildcard
+	// subdomains such as https://*.example.com
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+func (c CORSConfig) allowOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		// https://*.example.com matches https://a.example.com and https://a.b.example.com
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			origin = strings.ToLower(origin)
+			if strings.HasPrefix(origin, strings.ToLower(prefix)) &&
+				strings.HasSuffix(origin, strings.ToLower(suffix)) &&
+				len(origin) > len(prefix)+len(suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c CORSConfig) allowHeaders(requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if !slices.ContainsFunc(c.AllowedHeaders, func(allowed string) bool {
+			return allowed == "*" || strings.EqualFold(allowed, h)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// CORS adds the cross origin headers for allowed origins and answers the
+// preflight part of the exchange. Preflight requests still reach the router,
+// whose OPTIONS routes confirm the path and method exist.
+func CORS(cfg CORSConfig, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if !cfg.allowOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if slices.Contains(cfg.AllowedOrigins, "*") && !cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(cfg.ExposedHeaders) != 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		method := r.Header.Get("Access-Control-Request-Method")
+		requestedHeaders := r.Header.Get("Access-Control-Request-Headers")
+		if !slices.Contains(cfg.AllowedMethods, method) || !cfg.allowHeaders(requestedHeaders) {
+			// without the allow headers the browser rejects the preflight
+			next.ServeHTTP(w, r)
+			return
+		}
+		h.Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+		if requestedHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", requestedHeaders)
+		}
+		if cfg.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+		}
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,140 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CORS(t *testing.T) {
+	cfg := middleware.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodDelete},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"ETag", "RateLimit-Remaining"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	testcases := []struct {
+		name            string
+		cfg             *middleware.CORSConfig
+		method          string
+		headers         map[string]string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:            "ignore_same_origin_request",
+			method:          http.MethodGet,
+			expectedHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+		{
+			name:    "allow_exact_origin",
+			method:  http.MethodGet,
+			headers: map[string]string{"Origin": "https://app.example.com"},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "ETag, RateLimit-Remaining",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:    "allow_wildcard_subdomain",
+			method:  http.MethodGet,
+			headers: map[string]string{"Origin": "https://news.eu.example.org"},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "https://news.eu.example.org",
+			},
+		},
+		{
+			name:            "reject_wildcard_apex",
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://example.org"},
+			expectedHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:            "reject_lookalike_domain",
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "https://evilexample.org"},
+			expectedHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:            "reject_other_scheme",
+			method:          http.MethodGet,
+			headers:         map[string]string{"Origin": "http://app.example.com"},
+			expectedHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name:   "answer_preflight",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  http.MethodDelete,
+				"Access-Control-Request-Headers": "authorization",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Methods":     "GET, POST, DELETE",
+				"Access-Control-Allow-Headers":     "authorization",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:   "reject_preflight_for_disallowed_method",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://app.example.com",
+				"Access-Control-Request-Method": http.MethodPut,
+			},
+			expectedHeaders: map[string]string{"Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:   "reject_preflight_for_disallowed_header",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  http.MethodGet,
+				"Access-Control-Request-Headers": "X-Debug",
+			},
+			expectedHeaders: map[string]string{"Access-Control-Allow-Methods": "", "Access-Control-Allow-Headers": ""},
+		},
+		{
+			name:    "any_origin_without_credentials",
+			cfg:     &middleware.CORSConfig{AllowedOrigins: []string{"*"}},
+			method:  http.MethodGet,
+			headers: map[string]string{"Origin": "https://anything.test"},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := cfg
+			if tc.cfg != nil {
+				c = *tc.cfg
+			}
+			var called bool
+			h := middleware.CORS(c, http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "/news", nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			h(w, r)
+
+			assert.True(t, called, "the router answers the request")
+			for k, v := range tc.expectedHeaders {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Deprecation describes the retirement of deprecated routes
+type Deprecation struct {
+	// At is when the routes were deprecated
+	At time.Time
+	// Sunset is when they stop being served
+	Sunset time.Time
+	// Successor is the path prefix of the routes replacing them, such as /v1
+	Successor string
+}
+
+// Deprecate tells clients of deprecated routes when they were deprecated
+// (RFC 9745), when they will be retired (RFC 8594) and which route replaces
+// the one they called, whatever the response
+func Deprecate(d Deprecation, next http.Handler) http.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", d.At.Unix())
+	sunset := d.Sunset.UTC().Format(http.TimeFormat)
+	return func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Deprecation", deprecation)
+		h.Set("Sunset", sunset)
+		h.Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, d.Successor, r.URL.EscapedPath()))
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Deprecate(t *testing.T) {
+	testcases := []struct {
+		name           string
+		path           string
+		status         int
+		expectedStatus int
+		expectedLink   string
+	}{
+		{
+			name:           "link_successor_of_collection",
+			path:           "/news?limit=1",
+			status:         http.StatusOK,
+			expectedStatus: http.StatusOK,
+			expectedLink:   `</v1/news>; rel="successor-version"`,
+		},
+		{
+			name:           "annotate_failed_responses",
+			path:           "/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			status:         http.StatusNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedLink:   `</v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a>; rel="successor-version"`,
+		},
+	}
+
+	d := middleware.Deprecation{
+		At:        time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset:    time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		Successor: "/v1",
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := middleware.Deprecate(d, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+			assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			assert.Equal(t, tc.expectedLink, w.Header().Get("Link"))
+		})
+	}
+}
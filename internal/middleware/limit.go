@@ -0,0 +1,12 @@
+package middleware
+
+import "net/http"
+
+// LimitBody fails the reads of request bodies past maxSize bytes with an
+// *http.MaxBytesError, which the handlers answer with 413
+func LimitBody(maxSize int64, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+		next.ServeHTTP(w, r)
+	}
+}
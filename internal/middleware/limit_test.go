@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	mockshandler "github.com/logeshwarann-dev/news-api-rest/internal/handler/mocks"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LimitBody(t *testing.T) {
+	gzipped := func(s string) []byte {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		zw.Write([]byte(s))
+		zw.Close()
+		return b.Bytes()
+	}
+	large := `{"title":"` + strings.Repeat("a", 64<<10) + `"}`
+	// the compressed body fits the limit, only its decoded size goes over it
+	assert.Less(t, len(gzipped(large)), 1024)
+	testcases := []struct {
+		name           string
+		encoding       string
+		body           []byte
+		expectedStatus int
+	}{
+		{name: "read_small_body", body: []byte(`{}`), expectedStatus: http.StatusBadRequest},
+		{name: "read_small_gzip_body", encoding: "gzip", body: gzipped(`{}`), expectedStatus: http.StatusBadRequest},
+		{name: "reject_large_body", body: []byte(large), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "reject_large_decoded_gzip_body", encoding: "gzip", body: gzipped(large), expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			h := middleware.LimitBody(1024, middleware.Compress(1024, 1024, handler.PostNews(mockshandler.NewMockNewsStorer(ctrl))))
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/v1/news", bytes.NewReader(tc.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tc.encoding != "" {
+				r.Header.Set("Content-Encoding", tc.encoding)
+			}
+
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
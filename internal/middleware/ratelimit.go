@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/logeshwarann-dev/news-api-rest/internal/ratelimit"
+)
+
+// RateLimits holds the limits applied to each route class
+type RateLimits struct {
+	Read  ratelimit.Limit
+	Write ratelimit.Limit
+	// TrustForwardedFor identifies anonymous clients by the last X-Forwarded-For address,
+	// enable it only behind a single proxy that appends to the header
+	TrustForwardedFor bool
+}
+
+// RateLimit applies a token bucket per client and route class. Clients are
+// identified by their authenticated subject, so it must run after Authenticate,
+// and by their address otherwise.
+func RateLimit(store ratelimit.Store, limits RateLimits, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		class, limit := "write", limits.Write
+		if isRead(r.Method) {
+			class, limit = "read", limits.Read
+		}
+		key := class + ":" + clientKey(r, limits.TrustForwardedFor)
+		if take(w, r, store, key, limit) {
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// RateLimitAddress applies a token bucket per client address to every
+// request, whatever its credentials. It runs before Authenticate, so that
+// floods of invalid credentials are throttled before being checked.
+func RateLimitAddress(store ratelimit.Store, limit ratelimit.Limit, trustForwardedFor bool, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if take(w, r, store, "address:"+clientAddress(r, trustForwardedFor), limit) {
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// take spends a token of the bucket of key, setting the RateLimit headers,
+// and answers 429 when it is empty
+func take(w http.ResponseWriter, r *http.Request, store ratelimit.Store, key string, limit ratelimit.Limit) bool {
+	res, err := store.Take(r.Context(), key, limit)
+	if err != nil {
+		// fail open, an unavailable limiter must not take the api down
+		logger.FromContext(r.Context()).Error("rate limiter failed", "error", err)
+		return true
+	}
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("RateLimit-Reset", ceilSeconds(res.Reset))
+	if !res.Allowed {
+		logger.FromContext(r.Context()).Info("rate limit exceeded", "key", key)
+		w.Header().Set("Retry-After", ceilSeconds(res.RetryAfter))
+		problem.Write(w, problem.New(http.StatusTooManyRequests, "rate limit exceeded, retry later"))
+		return false
+	}
+	return true
+}
+
+func isRead(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// clientKey identifies the caller by authenticated subject or by address
+func clientKey(r *http.Request, trustForwardedFor bool) string {
+	if p, ok := auth.FromContext(r.Context()); ok {
+		return "sub:" + p.Subject
+	}
+	return "ip:" + clientAddress(r, trustForwardedFor)
+}
+
+// clientAddress returns the address of the caller, the last X-Forwarded-For
+// one when trusted: that one was appended by the proxy, the ones before it
+// are sent by the client and cannot be relied upon
+func clientAddress(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+			entries := strings.Split(xff[len(xff)-1], ",")
+			if last := strings.TrimSpace(entries[len(entries)-1]); last != "" {
+				return last
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,165 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/logeshwarann-dev/news-api-rest/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimit(t *testing.T) {
+	type request struct {
+		method     string
+		remoteAddr string
+		xff        string
+		subject    string
+	}
+	testcases := []struct {
+		name              string
+		trustForwardedFor bool
+		requests          []request
+		expectedStatus    int
+	}{
+		{
+			name: "allow_within_read_limit",
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "reject_over_read_limit",
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:5678"},
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "reads_and_writes_have_separate_buckets",
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234"},
+				{method: http.MethodPost, remoteAddr: "10.0.0.1:1234"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "reject_over_write_limit",
+			requests: []request{
+				{method: http.MethodPost, remoteAddr: "10.0.0.1:1234"},
+				{method: http.MethodDelete, remoteAddr: "10.0.0.1:1234"},
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name: "clients_have_separate_buckets",
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.2:1234"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "authenticated_callers_keyed_by_subject",
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", subject: "key-1"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.2:1234", subject: "key-1"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.3:1234", subject: "key-1"},
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:              "forwarded_for_used_when_trusted",
+			trustForwardedFor: true,
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", xff: "192.0.2.1"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", xff: "192.0.2.1, 10.0.0.9"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", xff: "192.0.2.2"},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:              "spoofed_forwarded_for_ignored",
+			trustForwardedFor: true,
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", xff: "198.51.100.1, 192.0.2.1"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", xff: "198.51.100.2, 192.0.2.1"},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", xff: "198.51.100.3, 192.0.2.1"},
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			limits := middleware.RateLimits{
+				Read:              ratelimit.Limit{Requests: 2, Period: time.Minute},
+				Write:             ratelimit.Limit{Requests: 1, Period: time.Minute},
+				TrustForwardedFor: tc.trustForwardedFor,
+			}
+			h := middleware.RateLimit(ratelimit.NewMemoryStore(), limits, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			var w *httptest.ResponseRecorder
+			for _, req := range tc.requests {
+				w = httptest.NewRecorder()
+				r := httptest.NewRequest(req.method, "/news", nil)
+				r.RemoteAddr = req.remoteAddr
+				if req.xff != "" {
+					r.Header.Set("X-Forwarded-For", req.xff)
+				}
+				if req.subject != "" {
+					r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: req.subject}))
+				}
+				h(w, r)
+			}
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			assert.NotEmpty(t, w.Header().Get("RateLimit-Limit"))
+			assert.NotEmpty(t, w.Header().Get("RateLimit-Remaining"))
+			assert.NotEmpty(t, w.Header().Get("RateLimit-Reset"))
+			if tc.expectedStatus == http.StatusTooManyRequests {
+				assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+				assert.NotEmpty(t, w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func Test_RateLimitAddressBeforeAuthenticate(t *testing.T) {
+	authenticator := auth.AuthenticatorFunc(func(context.Context, string) (auth.Principal, error) {
+		return auth.Principal{}, auth.ErrInvalidCredentials
+	})
+	store := ratelimit.NewMemoryStore()
+	h := middleware.RateLimitAddress(store, ratelimit.Limit{Requests: 3, Period: time.Minute}, false,
+		middleware.Authenticate(authenticator, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			t.Error("invalid credentials reached the handler")
+		})))
+	get := func(remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/v1/news", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("Authorization", "Bearer stolen-token")
+		h(w, r)
+		return w
+	}
+
+	var statuses []int
+	for range 5 {
+		statuses = append(statuses, get("10.0.0.1:1234").Code)
+	}
+
+	assert.Equal(t, []int{
+		http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized,
+		http.StatusTooManyRequests, http.StatusTooManyRequests,
+	}, statuses)
+	assert.Equal(t, http.StatusUnauthorized, get("10.0.0.2:1234").Code, "other addresses have their own bucket")
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+)
+
+// ReadYourWrites sends the reads of writes, and of every request a client
+// makes within the sticky window after a successful write, to the primary
+// database, so clients see their own writes despite replication lag.
+// Clients are identified as RateLimit does, so it must run after Authenticate.
+func ReadYourWrites(sticky *db.Sticky, trustForwardedFor bool, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := clientKey(r, trustForwardedFor)
+		if isRead(r.Method) {
+			if sticky.Pinned(key) {
+				r = r.WithContext(db.WithPrimary(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.WithContext(db.WithPrimary(r.Context()))
+		next.ServeHTTP(&writeWriter{ResponseWriter: w, wrote: func() { sticky.Wrote(key) }}, r)
+	}
+}
+
+// writeWriter calls wrote when a successful response starts, before the
+// client can see it and send its next request
+type writeWriter struct {
+	http.ResponseWriter
+	wrote       func()
+	wroteHeader bool
+}
+
+func (ww *writeWriter) WriteHeader(status int) {
+	if !ww.wroteHeader {
+		ww.wroteHeader = true
+		if status < http.StatusBadRequest {
+			ww.wrote()
+		}
+	}
+	ww.ResponseWriter.WriteHeader(status)
+}
+
+func (ww *writeWriter) Write(b []byte) (int, error) {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK)
+	}
+	return ww.ResponseWriter.Write(b)
+}
+
+func (ww *writeWriter) Unwrap() http.ResponseWriter {
+	return ww.ResponseWriter
+}
@@ -0,0 +1,68 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadYourWrites(t *testing.T) {
+	type request struct {
+		method          string
+		remoteAddr      string
+		status          int
+		expectedPrimary bool
+	}
+	testcases := []struct {
+		name     string
+		requests []request
+	}{
+		{
+			name: "read_from_replicas_without_writes",
+			requests: []request{
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", status: http.StatusOK},
+			},
+		},
+		{
+			name: "write_and_read_back_from_primary",
+			requests: []request{
+				{method: http.MethodPost, remoteAddr: "10.0.0.1:1234", status: http.StatusCreated, expectedPrimary: true},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:5678", status: http.StatusOK, expectedPrimary: true},
+				{method: http.MethodGet, remoteAddr: "10.0.0.2:1234", status: http.StatusOK},
+			},
+		},
+		{
+			name: "failed_write_does_not_pin",
+			requests: []request{
+				{method: http.MethodPut, remoteAddr: "10.0.0.1:1234", status: http.StatusBadRequest, expectedPrimary: true},
+				{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", status: http.StatusOK},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sticky := db.NewSticky(time.Minute)
+			for i, req := range tc.requests {
+				var usedPrimary bool
+				h := middleware.ReadYourWrites(sticky, false, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					usedPrimary = db.UsesPrimary(r.Context())
+					w.WriteHeader(req.status)
+				}))
+				r := httptest.NewRequest(req.method, "/news", nil)
+				r.RemoteAddr = req.remoteAddr
+				w := httptest.NewRecorder()
+
+				h(w, r)
+
+				assert.Equal(t, req.status, w.Code)
+				assert.Equal(t, req.expectedPrimary, usedPrimary, "request %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+)
+
+// ValidateRequest answers 400 with the list of invalid parameters and body
+// members when a request does not match the operation, before next sees it.
+// A body over the size limit is answered with 413, another unreadable one
+// with 400.
+func ValidateRequest(op *openapi.Operation, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		errs, err := op.Validate(r)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("request body reading failed", "error", err)
+			if p, ok := problem.TooLarge(err); ok {
+				problem.Write(w, p)
+				return
+			}
+			problem.Write(w, problem.New(http.StatusBadRequest, "request body could not be read"))
+			return
+		}
+		if len(errs) != 0 {
+			logger.FromContext(r.Context()).Info("request does not match the api description", "errors", len(errs))
+			p := problem.New(http.StatusBadRequest, "request does not match the api description")
+			p.Errors = errs
+			problem.Write(w, p)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
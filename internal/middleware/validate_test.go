@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidateRequest(t *testing.T) {
+	testcases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedErrors []problem.FieldError
+	}{
+		{
+			name:           "pass_valid_request",
+			body:           `{"url": "https://partner.example.com/hooks", "events": ["news.created"], "secret": "at least 16 characters"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "reject_invalid_request",
+			body:           `{"url": "https://partner.example.com/hooks", "events": [], "secret": "at least 16 characters", "enabled": true}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$.enabled", Detail: "is not allowed"},
+				{In: "body", Path: "$.events", Detail: "must not be empty"},
+			},
+		},
+	}
+
+	v, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+	op, ok := v.Operation("POST /v1/webhooks")
+	require.True(t, ok)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := middleware.ValidateRequest(op, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.body, string(body))
+				w.WriteHeader(http.StatusCreated)
+			}))
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/v1/webhooks", strings.NewReader(tc.body))
+
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			if tc.expectedErrors != nil {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				var p problem.Problem
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+				assert.Equal(t, tc.expectedErrors, p.Errors)
+			}
+		})
+	}
+}
@@ -2,22 +2,43 @@ package migration
 
 import (
 	"embed"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
 
+	"github.com/uptrace/bun/dialect"
 	"github.com/uptrace/bun/migrate"
 )
 
-var Migrations = migrate.NewMigrations()
+// dirs maps each supported dialect to the directory holding its migrations
+var dirs = map[dialect.Name]string{
+	dialect.PG:     "postgres",
+	dialect.SQLite: "sqlite",
+}
+
+var migrations = map[dialect.Name]*migrate.Migrations{}
 
-func New() *migrate.Migrations {
-	return Migrations
+// New returns the migrations of the dialect, or nil when it is not supported
+func New(name dialect.Name) *migrate.Migrations {
+	return migrations[name]
 }
 
-//go:embed *.sql
+//go:embed postgres/*.sql sqlite/*.sql
 var sqlMigration embed.FS
 
 func init() {
-	Migrations.DiscoverCaller()
-	if err := Migrations.Discover(sqlMigration); err != nil {
-		panic(err)
+	_, file, _, _ := runtime.Caller(0)
+	for name, dir := range dirs {
+		// created migrations land next to the existing ones of the dialect
+		m := migrate.NewMigrations(migrate.WithMigrationsDirectory(filepath.Join(filepath.Dir(file), dir)))
+		sub, err := fs.Sub(sqlMigration, dir)
+		if err != nil {
+			panic(err)
+		}
+		if err := m.Discover(sub); err != nil {
+			panic(fmt.Errorf("discover %s migrations: %w", dir, err))
+		}
+		migrations[name] = m
 	}
 }
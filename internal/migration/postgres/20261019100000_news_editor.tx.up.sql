@@ -0,0 +1 @@
+ALTER TABLE news ADD COLUMN IF NOT EXISTS editor TEXT;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+--bun:split
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS news_notify ON news;
+--bun:split
+DROP FUNCTION IF EXISTS notify_news_change();
@@ -0,0 +1,30 @@
+-- notifies every api-server listening on news_changes of the changes of the
+-- news, with the event type and the news id only since notifications are
+-- limited to 8000 bytes; the listeners read the record back
+CREATE OR REPLACE FUNCTION notify_news_change() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    news_id UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        event_type := 'news.deleted';
+        news_id := OLD.id;
+    ELSE
+        news_id := NEW.id;
+        IF TG_OP = 'INSERT' THEN
+            event_type := 'news.created';
+        ELSIF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL THEN
+            event_type := 'news.deleted';
+        ELSE
+            event_type := 'news.updated';
+        END IF;
+    END IF;
+    PERFORM pg_notify('news_changes', json_build_object('type', event_type, 'id', news_id)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+--bun:split
+DROP TRIGGER IF EXISTS news_notify ON news;
+--bun:split
+CREATE TRIGGER news_notify AFTER INSERT OR UPDATE OR DELETE ON news
+    FOR EACH ROW EXECUTE FUNCTION notify_news_change();
@@ -0,0 +1,24 @@
+CREATE OR REPLACE FUNCTION notify_news_change() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    news_id UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        event_type := 'news.deleted';
+        news_id := OLD.id;
+    ELSE
+        news_id := NEW.id;
+        IF TG_OP = 'INSERT' THEN
+            event_type := 'news.created';
+        ELSIF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL THEN
+            event_type := 'news.deleted';
+        ELSE
+            event_type := 'news.updated';
+        END IF;
+    END IF;
+    PERFORM pg_notify('news_changes', json_build_object('type', event_type, 'id', news_id)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+--bun:split
+DROP SEQUENCE IF EXISTS news_change_seq;
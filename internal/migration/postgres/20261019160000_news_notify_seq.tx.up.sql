@@ -0,0 +1,26 @@
+-- numbers the notified changes, so that every api-server identifies a change
+-- with the same event id and a stream may resume on any of them
+CREATE SEQUENCE IF NOT EXISTS news_change_seq;
+--bun:split
+CREATE OR REPLACE FUNCTION notify_news_change() RETURNS trigger AS $$
+DECLARE
+    event_type TEXT;
+    news_id UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        event_type := 'news.deleted';
+        news_id := OLD.id;
+    ELSE
+        news_id := NEW.id;
+        IF TG_OP = 'INSERT' THEN
+            event_type := 'news.created';
+        ELSIF NEW.deleted_at IS NOT NULL AND OLD.deleted_at IS NULL THEN
+            event_type := 'news.deleted';
+        ELSE
+            event_type := 'news.updated';
+        END IF;
+    END IF;
+    PERFORM pg_notify('news_changes', json_build_object('seq', nextval('news_change_seq'), 'type', event_type, 'id', news_id)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
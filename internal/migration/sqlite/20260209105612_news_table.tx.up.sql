@@ -0,0 +1,14 @@
+-- ids are generated by the application and tags are stored as a json array.
+-- Timestamps are utc text in the format bun writes, so they sort as text.
+CREATE TABLE IF NOT EXISTS news (
+    id TEXT PRIMARY KEY,
+    author TEXT NOT NULL,
+    title TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    content TEXT NOT NULL,
+    source TEXT NOT NULL,
+    tags TEXT NOT NULL CHECK (json_valid(tags)),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    deleted_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
@@ -0,0 +1 @@
+ALTER TABLE news ADD COLUMN owner TEXT;
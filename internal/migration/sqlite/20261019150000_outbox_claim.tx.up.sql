@@ -0,0 +1,2 @@
+-- the relay leases a batch of events before publishing them outside of a transaction
+ALTER TABLE outbox ADD COLUMN claimed_at TIMESTAMP;
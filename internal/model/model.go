@@ -1,7 +1,10 @@
 package model
 
 import (
+	"encoding/xml"
+
 	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
 )
 
 type NewsRecord struct {
@@ -15,5 +18,44 @@ type NewsRecord struct {
 }
 
 type AllNewsRecords struct {
-	NewsRecords []news.Record `json:"news,omitempty"`
+	XMLName     xml.Name      `json:"-" xml:"news_list"`
+	NewsRecords []news.Record `json:"news,omitempty" xml:"news"`
+}
+
+// Items returns the records one by one for line delimited formats
+func (a AllNewsRecords) Items() []any {
+	items := make([]any, len(a.NewsRecords))
+	for i, record := range a.NewsRecords {
+		items[i] = record
+	}
+	return items
+}
+
+// CSVHeader returns the column names of the records
+func (a AllNewsRecords) CSVHeader() []string {
+	return news.Record{}.CSVHeader()
+}
+
+// CSVRecords returns one row per record
+func (a AllNewsRecords) CSVRecords() [][]string {
+	rows := make([][]string, len(a.NewsRecords))
+	for i, record := range a.NewsRecords {
+		rows[i] = record.CSVRecord()
+	}
+	return rows
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url,omitempty"`
+	Events []string `json:"events,omitempty"`
+	Tag    string   `json:"tag,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+type AllWebhooks struct {
+	Webhooks []webhook.Subscription `json:"webhooks"`
+}
+
+type AllDeliveries struct {
+	Deliveries []webhook.Delivery `json:"deliveries"`
 }
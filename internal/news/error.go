@@ -1,5 +1,11 @@
 package news
 
+import "errors"
+
+// ErrChanged is returned by the conditional updates of a record updated
+// since the given update time
+var ErrChanged = errors.New("record changed since it was read")
+
 type CustomError struct {
 	err        error
 	httpStatus int
@@ -0,0 +1,92 @@
+package news
+
+import (
+	"iter"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// Order sets the order of listed records
+type Order int
+
+const (
+	// OldestFirst lists records by ascending creation time
+	OldestFirst Order = iota
+	// NewestFirst lists records by descending creation time
+	NewestFirst
+)
+
+// Filter narrows the records returned by list queries, zero values match everything
+type Filter struct {
+	Tag    string
+	Author string
+	// Query matches the records whose title, summary or content contain it,
+	// ignoring case
+	Query string
+	// Limit caps the number of records, 0 is unlimited
+	Limit  int
+	Offset int
+	Order  Order
+}
+
+// Records yields records one at a time along with any error ending the sequence
+type Records = iter.Seq2[Record, error]
+
+// Stats describes the records matching a filter, regardless of its limit and offset
+type Stats struct {
+	Count int
+	// LastModified is the latest update or deletion of any record, matching
+	// or not, so that records leaving the filter change it as well
+	LastModified time.Time
+}
+
+// where applies the matching conditions of the filter
+func (f Filter) where(q *bun.SelectQuery) *bun.SelectQuery {
+	if f.Tag != "" {
+		if q.Dialect().Name() == dialect.SQLite {
+			// sqlite keeps tags as a json array
+			q = q.Where("EXISTS (SELECT 1 FROM json_each(?TableAlias.tags) WHERE json_each.value = ?)", f.Tag)
+		} else {
+			q = q.Where("? = ANY(tags)", f.Tag)
+		}
+	}
+	if f.Author != "" {
+		q = q.Where("author = ?", f.Author)
+	}
+	if f.Query != "" {
+		pattern := "%" + likeEscaper.Replace(f.Query) + "%"
+		q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("lower(title) LIKE lower(?) ESCAPE '!'", pattern).
+				WhereOr("lower(summary) LIKE lower(?) ESCAPE '!'", pattern).
+				WhereOr("lower(content) LIKE lower(?) ESCAPE '!'", pattern)
+		})
+	}
+	return q
+}
+
+// likeEscaper makes the wildcards of a query match themselves in a LIKE
+// pattern escaped with '!'
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// page applies the order, limit and offset of the filter
+func (f Filter) page(q *bun.SelectQuery) *bun.SelectQuery {
+	if f.Order == NewestFirst {
+		q = q.Order("created_at DESC", "id DESC")
+	} else {
+		q = q.Order("created_at ASC", "id ASC")
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	} else if f.Offset > 0 && q.Dialect().Name() == dialect.SQLite {
+		// sqlite only takes an offset after a limit, and bun drops negative ones
+		q = q.Limit(math.MaxInt32)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+	return q
+}
@@ -1,6 +1,8 @@
 package news
 
 import (
+	"encoding/xml"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,15 +10,49 @@ import (
 )
 
 type Record struct {
-	bun.BaseModel `bun:"table:news"`
-	Id            uuid.UUID `json:"id" bun:"id,pk,type:uuid,default:uuid_generate_v4()"`
-	Author        string    `json:"author" bun:"author,nullzero,notnull"`
-	Title         string    `json:"title" bun:"title,nullzero,notnull"`
-	Summary       string    `json:"summary" bun:"summary,nullzero,notnull"`
-	Content       string    `json:"content" bun:"content,nullzero,notnull"`
-	Source        string    `json:"source" bun:"source,nullzero,notnull"`
-	Tags          []string  `json:"tags" bun:"tags,nullzero,notnull,array"`
-	CreatedAt     time.Time `json:"created_at" bun:"created_at,nullzero,notnull,default:current_timestamp"`
-	UpdatedAt     time.Time `json:"updated_at" bun:"updated_at,nullzero,notnull,default:current_timestamp"`
-	DeletedAt     time.Time `json:"deleted_at" bun:"deleted_at,nullzero,soft_delete"`
+	bun.BaseModel `bun:"table:news" xml:"-"`
+	XMLName       xml.Name  `json:"-" xml:"news" bun:"-"`
+	Id            uuid.UUID `json:"id" xml:"id" bun:"id,pk,type:uuid"`
+	Author        string    `json:"author" xml:"author" bun:"author,nullzero,notnull"`
+	Title         string    `json:"title" xml:"title" bun:"title,nullzero,notnull"`
+	Summary       string    `json:"summary" xml:"summary" bun:"summary,nullzero,notnull"`
+	Content       string    `json:"content" xml:"content" bun:"content,nullzero,notnull"`
+	Source        string    `json:"source" xml:"source" bun:"source,nullzero,notnull"`
+	Tags          []string  `json:"tags" xml:"tags>tag" bun:"tags,nullzero,notnull,array"`
+	Owner         string    `json:"owner,omitempty" xml:"owner,omitempty" bun:"owner,nullzero"`
+	Editor        string    `json:"editor,omitempty" xml:"editor,omitempty" bun:"editor,nullzero"`
+	CreatedAt     time.Time `json:"created_at" xml:"created_at" bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt     time.Time `json:"updated_at" xml:"updated_at" bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+	DeletedAt     time.Time `json:"deleted_at" xml:"deleted_at" bun:"deleted_at,nullzero,soft_delete"`
+}
+
+// CSVTagSeparator joins the tags of a record into a single csv field
+const CSVTagSeparator = ";"
+
+var csvHeader = []string{"id", "author", "title", "summary", "content", "source", "tags", "editor", "created_at", "updated_at"}
+
+// CSVHeader returns the column names of CSVRecord
+func (r Record) CSVHeader() []string {
+	return csvHeader
+}
+
+// CSVRecords returns the record as a single csv row
+func (r Record) CSVRecords() [][]string {
+	return [][]string{r.CSVRecord()}
+}
+
+// CSVRecord returns the fields of the record in the order of CSVHeader
+func (r Record) CSVRecord() []string {
+	return []string{
+		r.Id.String(),
+		r.Author,
+		r.Title,
+		r.Summary,
+		r.Content,
+		r.Source,
+		strings.Join(r.Tags, CSVTagSeparator),
+		r.Editor,
+		r.CreatedAt.Format(time.RFC3339),
+		r.UpdatedAt.Format(time.RFC3339),
+	}
 }
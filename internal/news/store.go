@@ -5,25 +5,63 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
 	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
 )
 
 type Store struct {
-	db bun.IDB
+	db     bun.IDB
+	reader Reader
 }
 
-func NewStore(db bun.IDB) *Store {
-	return &Store{
+// Reader picks the database serving the reads made with a context, such as a
+// replica of db.Cluster
+type Reader interface {
+	Reader(ctx context.Context) bun.IDB
+}
+
+// Option configures the Store
+type Option func(*Store)
+
+// WithReader sends FindAll, Stream, Stats and FindById to the database
+// picked by r, writes still go to the database of NewStore
+func WithReader(r Reader) Option {
+	return func(s *Store) {
+		s.reader = r
+	}
+}
+
+func NewStore(db bun.IDB, opts ...Option) *Store {
+	s := &Store{
 		db: db,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// read returns the database serving the reads made with ctx
+func (s Store) read(ctx context.Context) bun.IDB {
+	if s.reader == nil {
+		return s.db
+	}
+	return s.reader.Reader(ctx)
 }
 
-// create news
+// create news, ids are generated by the application on every dialect
 func (s Store) Create(ctx context.Context, news Record) (createdNews Record, err error) {
 	news.Id = uuid.New()
-	err = s.db.NewInsert().Model(&news).Returning("*").Scan(ctx, &createdNews)
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := tx.NewInsert().Model(&news).Returning("*").Scan(ctx, &createdNews); err != nil {
+			return err
+		}
+		return outbox.Add(ctx, tx, outbox.NewsCreated, createdNews.Id, createdNews)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return createdNews, NewCustomError(err, http.StatusNotFound)
@@ -33,18 +71,78 @@ func (s Store) Create(ctx context.Context, news Record) (createdNews Record, err
 	return createdNews, nil
 }
 
-// get all news
-func (s Store) FindAll(ctx context.Context) (news []Record, err error) {
-	err = s.db.NewSelect().Model(&news).Scan(ctx)
+// get all news matching the filter
+func (s Store) FindAll(ctx context.Context, f Filter) (news []Record, err error) {
+	q := s.read(ctx).NewSelect().Model(&news)
+	err = f.page(f.where(q)).Scan(ctx)
 	if err != nil {
 		return news, NewCustomError(err, http.StatusInternalServerError)
 	}
 	return news, nil
 }
 
+// stream the news matching the filter one row at a time, stopping the query when the consumer stops.
+// On sqlite the page is read before it is yielded: its database is served on a
+// single connection, which a slow consumer would hold from every other query.
+func (s Store) Stream(ctx context.Context, f Filter) Records {
+	return func(yield func(Record, error) bool) {
+		db := s.read(ctx)
+		if db.Dialect().Name() == dialect.SQLite {
+			var records []Record
+			if err := f.page(f.where(db.NewSelect().Model(&records))).Scan(ctx); err != nil {
+				yield(Record{}, NewCustomError(err, http.StatusInternalServerError))
+				return
+			}
+			for _, record := range records {
+				if !yield(record, nil) {
+					return
+				}
+			}
+			return
+		}
+		q := f.page(f.where(db.NewSelect().Model((*Record)(nil))))
+		rows, err := q.Rows(ctx)
+		if err != nil {
+			yield(Record{}, NewCustomError(err, http.StatusInternalServerError))
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var record Record
+			if err := q.DB().ScanRow(ctx, rows, &record); err != nil {
+				yield(Record{}, NewCustomError(err, http.StatusInternalServerError))
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(Record{}, NewCustomError(err, http.StatusInternalServerError))
+		}
+	}
+}
+
+// count the live news matching the filter and find the latest write among
+// all the news, deletions included: a record updated out of the filter leaves
+// it without a trace among the matching ones
+func (s Store) Stats(ctx context.Context, f Filter) (stats Stats, err error) {
+	var lastModified bun.NullTime
+	db := s.read(ctx)
+	lastWrite := db.NewSelect().Model((*Record)(nil)).WhereAllWithDeleted().
+		ColumnExpr("max(CASE WHEN deleted_at > updated_at THEN deleted_at ELSE updated_at END)")
+	q := db.NewSelect().Model((*Record)(nil)).ColumnExpr("count(*)").ColumnExpr("(?)", lastWrite)
+	err = f.where(q).Scan(ctx, &stats.Count, &lastModified)
+	if err != nil {
+		return stats, NewCustomError(err, http.StatusInternalServerError)
+	}
+	stats.LastModified = lastModified.Time
+	return stats, nil
+}
+
 // get news by id
 func (s Store) FindById(ctx context.Context, id uuid.UUID) (news Record, err error) {
-	err = s.db.NewSelect().Model(&news).Where("id = ?", id).Scan(ctx)
+	err = s.read(ctx).NewSelect().Model(&news).Where("id = ?", id).Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return news, NewCustomError(err, http.StatusNotFound)
@@ -54,25 +152,58 @@ func (s Store) FindById(ctx context.Context, id uuid.UUID) (news Record, err err
 	return news, nil
 }
 
-// update news by id
+// update news by id, its owner is kept
 func (s Store) UpdateById(ctx context.Context, id uuid.UUID, news Record) error {
-	r, err := s.db.NewUpdate().Model(&news).Where("id = ?", id).Returning("NULL").Exec(ctx)
-	if err != nil {
-		return NewCustomError(err, http.StatusInternalServerError)
-	}
-	rows, err := r.RowsAffected()
+	return s.update(ctx, id, news, nil)
+}
+
+// update news by id only while its update time is still updatedAt, failing
+// with ErrChanged when it was updated since
+func (s Store) UpdateByIdIfUnchanged(ctx context.Context, id uuid.UUID, updatedAt time.Time, news Record) error {
+	return s.update(ctx, id, news, &updatedAt)
+}
+
+func (s Store) update(ctx context.Context, id uuid.UUID, news Record, updatedAt *time.Time) error {
+	news.UpdatedAt = time.Now()
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var updated Record
+		q := tx.NewUpdate().Model(&news).ExcludeColumn("owner").Where("id = ?", id)
+		if updatedAt != nil {
+			q = q.Where("updated_at = ?", *updatedAt)
+		}
+		if err := q.Returning("*").Scan(ctx, &updated); err != nil {
+			if errors.Is(err, sql.ErrNoRows) && updatedAt != nil {
+				if exists, existsErr := tx.NewSelect().Model((*Record)(nil)).Where("id = ?", id).Exists(ctx); existsErr != nil {
+					return existsErr
+				} else if exists {
+					return ErrChanged
+				}
+			}
+			return err
+		}
+		return outbox.Add(ctx, tx, outbox.NewsUpdated, id, updated)
+	})
 	if err != nil {
+		if errors.Is(err, ErrChanged) {
+			return NewCustomError(err, http.StatusPreconditionFailed)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return NewCustomError(errors.New("record not found"), http.StatusNotFound)
+		}
 		return NewCustomError(err, http.StatusInternalServerError)
 	}
-	if rows == 0 {
-		return NewCustomError(errors.New("record not found"), http.StatusNotFound)
-	}
 	return nil
 }
 
-// delete news by id
+// delete news by id, deleting a missing or deleted record changes nothing
 func (s Store) DeleteById(ctx context.Context, id uuid.UUID) (err error) {
-	_, err = s.db.NewDelete().Model(&Record{}).Where("id = ?", id).Returning("NULL").Exec(ctx)
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var deleted Record
+		if err := tx.NewDelete().Model(&deleted).Where("id = ?", id).Returning("*").Scan(ctx, &deleted); err != nil {
+			return err
+		}
+		return outbox.Add(ctx, tx, outbox.NewsDeleted, id, deleted)
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil
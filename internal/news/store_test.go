@@ -3,23 +3,23 @@ package news_test
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/docker/go-connections/nat"
-	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
 	"github.com/logeshwarann-dev/news-api-rest/internal/news"
-	"github.com/logeshwarann-dev/news-api-rest/internal/postgres"
-	"github.com/stretchr/testify/assert"
+	"github.com/logeshwarann-dev/news-api-rest/internal/storetest"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	pgtc "github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
 	"github.com/uptrace/bun"
 )
 
-var db *bun.DB
+var testDB *bun.DB
 
 func TestMain(m *testing.M) {
 	ctx := context.Background()
@@ -27,7 +27,7 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		panic(err)
 	}
-	db = pgdb
+	testDB = pgdb
 	code := m.Run()
 	if err := cleanup(ctx); err != nil {
 		panic(err)
@@ -35,258 +35,12 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-func TestStore_Create(t *testing.T) {
-	testcases := []struct {
-		name           string
-		context        context.Context
-		record         news.Record
-		expectedErr    string
-		expectedStatus int
-	}{
-		{
-			name:    "missing_author",
-			context: context.Background(),
-			record: news.Record{
-				Title:   "Breaking NEWS",
-				Summary: "A brief summary of news",
-				Content: "Batman is a hero with super powers who saves people from devil",
-				Source:  "https://www.google.com",
-				Tags:    []string{"marvel", "test-1"},
-			},
-			expectedErr:    "not-null",
-			expectedStatus: http.StatusInternalServerError,
-		},
-		{
-			name:    "create_news_success",
-			context: context.Background(),
-			record: news.Record{
-				Author:  "Batman",
-				Title:   "Breaking NEWS",
-				Summary: "A brief summary of news",
-				Content: "Batman is a hero with super powers who saves people from devil",
-				Source:  "https://www.google.com",
-				Tags:    []string{"marvel", "test-1"},
-			},
-			expectedStatus: http.StatusCreated,
-		},
-	}
-	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			store := news.NewStore(db)
-			got, err := store.Create(tc.context, tc.record)
-			if err != nil {
-				assert.Error(t, err)
-				var storeErr *news.CustomError
-				assert.ErrorAs(t, err, &storeErr)
-				assert.Equal(t, tc.expectedStatus, storeErr.GetHttpStatus())
-			} else {
-				assert.NoError(t, err)
-				assertOnNews(t, tc.record, got)
-			}
-		})
-	}
-}
-
-func TestStore_FindById(t *testing.T) {
-	testcases := []struct {
-		name           string
-		ctx            context.Context
-		newsId         uuid.UUID
-		expectedRecord news.Record
-		expectedErr    string
-		expectedStatus int
-	}{
-		{
-			name:   "return_valid_news_record",
-			ctx:    context.Background(),
-			newsId: uuid.MustParse("67a7f4b7-2261-4121-a578-bf9da06aa0f3"),
-			expectedRecord: news.Record{
-				Author:  "Batman",
-				Title:   "Breaking NEWS",
-				Summary: "A brief summary of news",
-				Content: "Batman is a hero with super powers who saves people from devil",
-				Source:  "https://www.google.com",
-				Tags:    []string{"marvel", "sci-fi"},
-			},
-			expectedStatus: http.StatusOK,
-		},
-		{
-			name:           "return_not_found_error",
-			ctx:            context.Background(),
-			newsId:         uuid.MustParse("79a7f4b7-2261-4121-a578-bf9da06aa0f3"),
-			expectedErr:    "no rows",
-			expectedStatus: http.StatusNotFound,
-		},
-	}
-
-	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			s := news.NewStore(db)
-			got, err := s.FindById(tc.ctx, tc.newsId)
-			if tc.expectedErr != "" {
-				assert.Error(t, err)
-				assert.ErrorContains(t, err, tc.expectedErr)
-				var storeErr *news.CustomError
-				assert.ErrorAs(t, err, &storeErr)
-				assert.Equal(t, tc.expectedStatus, storeErr.GetHttpStatus())
-			} else {
-				assert.NoError(t, err)
-				assertOnNews(t, tc.expectedRecord, got)
-			}
-		})
-	}
-
-}
-
-func TestStore_FindAll(t *testing.T) {
-	testcases := []struct {
-		name            string
-		ctx             context.Context
-		expectedRecords []news.Record
-		expectedErr     string
-		expectedStatus  int
-	}{
-		{
-			name: "return_all_records",
-			ctx:  context.Background(),
-			expectedRecords: []news.Record{
-				{
-					Author:  "Batman",
-					Title:   "Breaking NEWS",
-					Summary: "A brief summary of news",
-					Content: "Batman is a hero with super powers who saves people from devil",
-					Source:  "https://www.google.com",
-					Tags:    []string{"marvel", "sci-fi"},
-				},
-				{
-					Author:  "Superman",
-					Title:   "Breaking NEWS",
-					Summary: "A brief summary of news",
-					Content: "Superman is a hero with super powers who saves people from devil",
-					Source:  "https://www.google.com",
-					Tags:    []string{"marvel", "sci-fi"},
-				},
-			},
-			expectedStatus: http.StatusOK,
-		},
-	}
-
-	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			s := news.NewStore(db)
-			got, err := s.FindAll(tc.ctx)
-			if tc.expectedErr != "" {
-				assert.Error(t, err)
-				assert.ErrorContains(t, err, tc.expectedErr)
-				var storeErr *news.CustomError
-				assert.ErrorAs(t, err, &storeErr)
-				assert.Equal(t, tc.expectedStatus, storeErr.GetHttpStatus())
-			} else {
-				assert.NoError(t, err)
-				for i, record := range tc.expectedRecords {
-					assertOnNews(t, record, got[i])
-				}
-			}
-		})
-	}
-}
-
-func TestStore_UpdateById(t *testing.T) {
-	testcases := []struct {
-		name           string
-		ctx            context.Context
-		newsId         uuid.UUID
-		updatedRecord  news.Record
-		expectedErr    string
-		expectedStatus int
-	}{
-		{
-			name:   "return_valid_news",
-			ctx:    context.Background(),
-			newsId: uuid.MustParse("67a7f4b7-2261-4121-a578-bf9da06aa0f3"),
-			updatedRecord: news.Record{
-				Author:  "Batman",
-				Title:   "Batman NEWS",
-				Summary: "A brief summary of news",
-				Content: "Batman is a hero with super powers who saves people from devil",
-				Source:  "https://www.google.com",
-				Tags:    []string{"marvel", "sci-fi"},
-			},
-			expectedStatus: http.StatusOK,
-		},
-		{
-			name:           "return_not_found_error",
-			ctx:            context.Background(),
-			newsId:         uuid.MustParse("98a7f4b7-2261-4121-a578-bf9da06aa0f3"),
-			expectedErr:    "record not found",
-			expectedStatus: http.StatusNotFound,
-		},
-	}
-
-	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			s := news.NewStore(db)
-			got, _ := s.FindById(tc.ctx, tc.newsId)
-			tc.updatedRecord.CreatedAt = got.CreatedAt
-			tc.updatedRecord.UpdatedAt = got.UpdatedAt
-			err := s.UpdateById(tc.ctx, tc.newsId, tc.updatedRecord)
-			if tc.expectedErr != "" {
-				assert.Error(t, err)
-				assert.ErrorContains(t, err, tc.expectedErr)
-				var storeErr *news.CustomError
-				assert.ErrorAs(t, err, &storeErr)
-				assert.Equal(t, tc.expectedStatus, storeErr.GetHttpStatus())
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
-func TestStore_DeleteById(t *testing.T) {
-	testcases := []struct {
-		name           string
-		ctx            context.Context
-		newsId         uuid.UUID
-		expectedErr    string
-		expectedStatus int
-	}{
-		{
-			name:           "return_no_error",
-			ctx:            context.Background(),
-			newsId:         uuid.MustParse("67a7f4b7-2261-4121-a578-bf9da06aa0f3"),
-			expectedStatus: http.StatusNoContent,
-		},
-	}
-
-	for _, tc := range testcases {
-		t.Run(tc.name, func(t *testing.T) {
-			s := news.NewStore(db)
-			err := s.DeleteById(tc.ctx, tc.newsId)
-			if tc.expectedErr != "" {
-				assert.Error(t, err)
-				assert.ErrorContains(t, err, tc.expectedErr)
-				var storeErr *news.CustomError
-				assert.ErrorAs(t, err, &storeErr)
-				assert.Equal(t, tc.expectedStatus, storeErr.GetHttpStatus())
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
-}
-
-func assertOnNews(tb testing.TB, expected, got news.Record) {
-	tb.Helper()
-	assert.Equal(tb, expected.Author, got.Author)
-	assert.Equal(tb, expected.Title, got.Title)
-	assert.Equal(tb, expected.Summary, got.Summary)
-	assert.Equal(tb, expected.Content, got.Content)
-	assert.Equal(tb, expected.Source, got.Source)
-	assert.Equal(tb, expected.Tags, got.Tags)
-	assert.NotEqual(tb, time.Time{}, got.CreatedAt)
-	assert.NotEqual(tb, time.Time{}, got.UpdatedAt)
-	assert.Equal(tb, time.Time{}, got.DeletedAt)
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) handler.NewsStorer {
+		_, err := testDB.NewTruncateTable().Model((*news.Record)(nil)).Exec(context.Background())
+		require.NoError(t, err)
+		return news.NewStore(testDB)
+	})
 }
 
 func createTestContainer(ctx context.Context) (ctr *pgtc.PostgresContainer, err error) {
@@ -327,7 +81,8 @@ func createTestDB(ctx context.Context) (*bun.DB, DBCleanup, error) {
 		return nil, nil, fmt.Errorf("map port: %w", err)
 	}
 
-	db, err := postgres.NewDB(&postgres.Config{
+	pgdb, err := db.NewDB(&db.Config{
+		Dialect:  db.Postgres,
 		DbHost:   "localhost",
 		DbPort:   port.Port(),
 		DbName:   "postgres",
@@ -338,7 +93,7 @@ func createTestDB(ctx context.Context) (*bun.DB, DBCleanup, error) {
 	})
 
 	cleanup := func(ctx context.Context) error {
-		if err := db.Close(); err != nil {
+		if err := pgdb.Close(); err != nil {
 			return fmt.Errorf("close db: %w", err)
 		}
 
@@ -348,5 +103,5 @@ func createTestDB(ctx context.Context) (*bun.DB, DBCleanup, error) {
 		return nil
 	}
 
-	return db, cleanup, nil
+	return pgdb, cleanup, nil
 }
@@ -0,0 +1,242 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// refreshInterval bounds how often an unknown key id triggers a refetch of a remote key set
+const refreshInterval = time.Minute
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS decodes the RSA and P-256 signing keys of a JSON Web Key Set, keyed by kid
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks contains no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y coordinate: %w", err)
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid coordinate length")
+		}
+		// ecdh validates that the point lies on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// ErrKeySetUnavailable is returned when a remote key set cannot be refetched,
+// a failure of the server rather than of the token
+var ErrKeySetUnavailable = errors.New("jwks unavailable")
+
+// KeySet holds the verification keys loaded from a local file or an http(s) url.
+// Remote sets are refetched when a token references an unknown key id.
+type KeySet struct {
+	source    string
+	client    *http.Client
+	group     singleflight.Group
+	refresh   time.Duration
+	m         sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// KeySetOption configures a KeySet
+type KeySetOption func(*KeySet)
+
+// WithRefreshInterval replaces the minimum time between two refetches of a
+// remote key set, a minute by default
+func WithRefreshInterval(d time.Duration) KeySetOption {
+	return func(ks *KeySet) {
+		ks.refresh = d
+	}
+}
+
+// NewKeySet loads the key set from source, a file path or an http(s) url
+func NewKeySet(ctx context.Context, source string, opts ...KeySetOption) (*KeySet, error) {
+	ks := &KeySet{
+		source:  source,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		refresh: refreshInterval,
+	}
+	for _, opt := range opts {
+		opt(ks)
+	}
+	if err := ks.load(ctx); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+func (ks *KeySet) remote() bool {
+	return strings.HasPrefix(ks.source, "https://") || strings.HasPrefix(ks.source, "http://")
+}
+
+func (ks *KeySet) load(ctx context.Context) error {
+	var data []byte
+	var err error
+	if ks.remote() {
+		data, err = ks.fetch(ctx)
+	} else {
+		data, err = os.ReadFile(ks.source)
+	}
+	if err != nil {
+		return fmt.Errorf("load jwks from %s: %w", ks.source, err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	ks.m.Lock()
+	defer ks.m.Unlock()
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	return nil
+}
+
+func (ks *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+// Key returns the key for kid, an empty kid matches a set holding a single key.
+// Keys are looked up under a read lock; an unknown kid refetches a remote set
+// outside of it, once for all the callers waiting for it.
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok, stale := ks.lookup(kid)
+	if ok {
+		return key, nil
+	}
+	if ks.remote() && stale {
+		// the fetch is shared, a caller giving up must not cancel it for the others
+		ch := ks.group.DoChan("jwks", func() (any, error) {
+			if _, _, stale := ks.lookup(kid); !stale {
+				// refetched by a caller that was just done
+				return nil, nil
+			}
+			return nil, ks.load(context.WithoutCancel(ctx))
+		})
+		select {
+		case res := <-ch:
+			if res.Err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrKeySetUnavailable, res.Err)
+			}
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ErrKeySetUnavailable, ctx.Err())
+		}
+		if key, ok, _ := ks.lookup(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// lookup finds the key for kid and tells whether the set may be refetched
+func (ks *KeySet) lookup(kid string) (key crypto.PublicKey, ok bool, stale bool) {
+	ks.m.RLock()
+	defer ks.m.RUnlock()
+	stale = time.Since(ks.fetchedAt) > ks.refresh
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true, stale
+		}
+	}
+	key, ok = ks.keys[kid]
+	return key, ok, stale
+}
@@ -0,0 +1,122 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+)
+
+// Config describes the tokens accepted by the Verifier
+type Config struct {
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim holding the caller roles, either a list or a space separated string
+	RolesClaim string
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+// Verifier authenticates RS256 and ES256 signed JWTs
+type Verifier struct {
+	keys   *KeySet
+	cfg    Config
+	parser *jwt.Parser
+}
+
+func NewVerifier(keys *KeySet, cfg Config) *Verifier {
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	return &Verifier{
+		keys: keys,
+		cfg:  cfg,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(cfg.Leeway),
+		),
+	}
+}
+
+// Authenticate verifies the token signature and claims and maps them to a principal
+func (v *Verifier) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	if strings.Count(token, ".") != 2 {
+		return auth.Principal{}, auth.ErrInvalidCredentials
+	}
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if errors.Is(err, ErrKeySetUnavailable) {
+		// the token may well be valid, it is not refused
+		return auth.Principal{}, fmt.Errorf("verify token: %w", err)
+	}
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("%w: %w", auth.ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return auth.Principal{}, fmt.Errorf("%w: missing sub claim", auth.ErrInvalidCredentials)
+	}
+	principal := auth.Principal{
+		Subject: subject,
+		Name:    stringClaim(claims, "name"),
+	}
+	if principal.Name == "" {
+		principal.Name = stringClaim(claims, "preferred_username")
+	}
+	for _, role := range listClaim(claims, v.cfg.RolesClaim) {
+		principal.Roles = append(principal.Roles, auth.Role(role))
+	}
+	principal.Scopes = auth.ScopesForRoles(principal.Roles)
+	for _, name := range listClaim(claims, "scope") {
+		if scope, err := auth.ParseScope(name); err == nil && !principal.HasScope(scope) {
+			principal.Scopes = append(principal.Scopes, scope)
+		}
+	}
+	return principal, nil
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// listClaim reads a claim holding either a list of strings or a space separated string
+func listClaim(claims jwt.MapClaims, name string) (values []string) {
+	switch v := claims[name].(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// ErrNoKeySource is returned when the verifier is configured without a jwks
+var ErrNoKeySource = errors.New("jwks source is empty")
+
+// New loads the key set from source and returns a verifier for it
+func New(ctx context.Context, source string, cfg Config) (*Verifier, error) {
+	if source == "" {
+		return nil, ErrNoKeySource
+	}
+	keys, err := NewKeySet(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return NewVerifier(keys, cfg), nil
+}
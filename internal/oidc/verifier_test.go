@@ -0,0 +1,251 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	issuer   = "https://gateway.example.com"
+	audience = "news-api"
+)
+
+type testKeys struct {
+	rsa  *rsa.PrivateKey
+	ec   *ecdsa.PrivateKey
+	jwks []byte
+}
+
+func newTestKeys(tb testing.TB) testKeys {
+	tb.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(tb, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256",
+				"n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec-1", "use": "sig", "alg": "ES256", "crv": "P-256",
+				"x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		},
+	})
+	require.NoError(tb, err)
+	return testKeys{rsa: rsaKey, ec: ecKey, jwks: jwks}
+}
+
+func sign(tb testing.TB, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	tb.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	require.NoError(tb, err)
+	return s
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   issuer,
+		"aud":   audience,
+		"sub":   "user-42",
+		"name":  "Clark Kent",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"reporter"},
+	}
+}
+
+func with(claims jwt.MapClaims, key string, value any) jwt.MapClaims {
+	claims[key] = value
+	return claims
+}
+
+func Test_VerifierAuthenticate(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keys.jwks, 0o600))
+	v, err := oidc.New(context.Background(), path, oidc.Config{Issuer: issuer, Audience: audience})
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name              string
+		token             string
+		expectedErr       bool
+		expectedPrincipal auth.Principal
+	}{
+		{
+			name:  "accept_rs256_token",
+			token: sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims()),
+			expectedPrincipal: auth.Principal{
+				Subject: "user-42",
+				Name:    "Clark Kent",
+				Roles:   []auth.Role{auth.RoleReporter},
+				Scopes:  []auth.Scope{auth.ScopeNewsRead, auth.ScopeNewsWrite, auth.ScopeNewsDelete},
+			},
+		},
+		{
+			name:  "accept_es256_token_with_scope_claim",
+			token: sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, with(with(validClaims(), "roles", "reader"), "scope", "openid admin")),
+			expectedPrincipal: auth.Principal{
+				Subject: "user-42",
+				Name:    "Clark Kent",
+				Roles:   []auth.Role{auth.RoleReader},
+				Scopes:  []auth.Scope{auth.ScopeNewsRead, auth.ScopeAdmin},
+			},
+		},
+		{
+			name:        "reject_expired_token",
+			token:       sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(validClaims(), "exp", time.Now().Add(-time.Hour).Unix())),
+			expectedErr: true,
+		},
+		{
+			name:        "reject_token_without_exp",
+			token:       sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(validClaims(), "exp", nil)),
+			expectedErr: true,
+		},
+		{
+			name:        "reject_wrong_issuer",
+			token:       sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(validClaims(), "iss", "https://evil.example.com")),
+			expectedErr: true,
+		},
+		{
+			name:        "reject_wrong_audience",
+			token:       sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(validClaims(), "aud", "billing-api")),
+			expectedErr: true,
+		},
+		{
+			name:        "reject_missing_subject",
+			token:       sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, with(validClaims(), "sub", "")),
+			expectedErr: true,
+		},
+		{
+			name:        "reject_unknown_key_id",
+			token:       sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, validClaims()),
+			expectedErr: true,
+		},
+		{
+			name:        "reject_hs256_token",
+			token:       sign(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), validClaims()),
+			expectedErr: true,
+		},
+		{
+			name:        "reject_non_jwt_token",
+			token:       "nak_abcdef",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := v.Authenticate(context.Background(), tc.token)
+			if tc.expectedErr {
+				assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPrincipal, got)
+		})
+	}
+}
+
+func Test_NewKeySetFromURL(t *testing.T) {
+	keys := newTestKeys(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(keys.jwks)
+	}))
+	defer srv.Close()
+
+	v, err := oidc.New(context.Background(), srv.URL, oidc.Config{Issuer: issuer, Audience: audience})
+	require.NoError(t, err)
+	p, err := v.Authenticate(context.Background(), sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, "user-42", p.Subject)
+}
+
+func Test_ParseJWKS(t *testing.T) {
+	_, err := oidc.ParseJWKS([]byte(`{"keys":[{"kty":"oct","kid":"k","k":"c2VjcmV0"}]}`))
+	assert.ErrorContains(t, err, "unsupported key type")
+
+	_, err = oidc.ParseJWKS([]byte(`{"keys":[]}`))
+	assert.ErrorContains(t, err, "no signing keys")
+}
+
+func Test_KeySetRefetchDoesNotBlockKnownKeys(t *testing.T) {
+	keys := newTestKeys(t)
+	refetching, release := make(chan struct{}), make(chan struct{})
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		if fetches > 1 {
+			close(refetching)
+			<-release
+		}
+		w.Write(keys.jwks)
+	}))
+	defer srv.Close()
+	ks, err := oidc.NewKeySet(context.Background(), srv.URL, oidc.WithRefreshInterval(0))
+	require.NoError(t, err)
+	v := oidc.NewVerifier(ks, oidc.Config{Issuer: issuer, Audience: audience})
+
+	unknown := make(chan error)
+	go func() {
+		_, err := v.Authenticate(context.Background(), sign(t, jwt.SigningMethodES256, "rotated", keys.ec, validClaims()))
+		unknown <- err
+	}()
+	<-refetching
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p, err := v.Authenticate(ctx, sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims()))
+	close(release)
+
+	assert.NoError(t, err, "a known key waited for the refetch")
+	assert.Equal(t, "user-42", p.Subject)
+	assert.ErrorIs(t, <-unknown, auth.ErrInvalidCredentials)
+}
+
+func Test_KeySetUnavailable(t *testing.T) {
+	keys := newTestKeys(t)
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		if fetches > 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write(keys.jwks)
+	}))
+	defer srv.Close()
+	ks, err := oidc.NewKeySet(context.Background(), srv.URL, oidc.WithRefreshInterval(0))
+	require.NoError(t, err)
+	v := oidc.NewVerifier(ks, oidc.Config{Issuer: issuer, Audience: audience})
+
+	_, err = v.Authenticate(context.Background(), sign(t, jwt.SigningMethodES256, "rotated", keys.ec, validClaims()))
+
+	// the token is not refused, the server fails
+	assert.ErrorIs(t, err, oidc.ErrKeySetUnavailable)
+	assert.NotErrorIs(t, err, auth.ErrInvalidCredentials)
+}
@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed docs.html
+var docsTemplate string
+
+// docs renders the page once, from the embedded document
+var docs = sync.OnceValues(func() ([]byte, error) {
+	return renderDocs(spec)
+})
+
+// methods lists the operations of a path in the order they are documented
+var methods = []string{"get", "head", "post", "put", "patch", "delete"}
+
+// docsSchema is the part of a schema the page describes
+type docsSchema struct {
+	Ref         string                 `json:"$ref"`
+	Type        string                 `json:"type"`
+	Format      string                 `json:"format"`
+	Description string                 `json:"description"`
+	Enum        []any                  `json:"enum"`
+	Items       *docsSchema            `json:"items"`
+	Properties  map[string]*docsSchema `json:"properties"`
+	Required    []string               `json:"required"`
+}
+
+type docsParameter struct {
+	Ref         string      `json:"$ref"`
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required"`
+	Schema      *docsSchema `json:"schema"`
+}
+
+type docsContent map[string]struct {
+	Schema *docsSchema `json:"schema"`
+}
+
+type docsResponse struct {
+	Ref         string      `json:"$ref"`
+	Description string      `json:"description"`
+	Content     docsContent `json:"content"`
+}
+
+type docsOperation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary"`
+	Description string                 `json:"description"`
+	Tags        []string               `json:"tags"`
+	Security    *[]map[string][]string `json:"security"`
+	Parameters  []*docsParameter       `json:"parameters"`
+	RequestBody *struct {
+		Content docsContent `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]*docsResponse `json:"responses"`
+}
+
+type docsDocument struct {
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Tags []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"tags"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas    map[string]*docsSchema    `json:"schemas"`
+		Parameters map[string]*docsParameter `json:"parameters"`
+		Responses  map[string]*docsResponse  `json:"responses"`
+	} `json:"components"`
+}
+
+// page is what docs.html renders
+type page struct {
+	Title, Version string
+	Description    []string
+	Tags           []pageTag
+	Schemas        []pageSchema
+}
+
+type pageTag struct {
+	Name, Description string
+	Operations        []pageOperation
+}
+
+type pageOperation struct {
+	ID, Method, Path, Summary string
+	Description               []string
+	// Scopes is empty for public operations
+	Scopes     []string
+	Parameters []pageField
+	Body       []pageMedia
+	Responses  []pageResponse
+}
+
+type pageField struct {
+	Name, In, Type, Description string
+	Required                    bool
+}
+
+type pageMedia struct {
+	MediaType, Type string
+}
+
+type pageResponse struct {
+	Status, Description string
+	Content             []pageMedia
+}
+
+type pageSchema struct {
+	Name        string
+	Description []string
+	Properties  []pageField
+}
+
+// renderDocs renders the documentation page of the OpenAPI document spec
+func renderDocs(spec []byte) ([]byte, error) {
+	var doc docsDocument
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+	p := page{Title: doc.Info.Title, Version: doc.Info.Version, Description: paragraphs(doc.Info.Description)}
+	tags := map[string]int{}
+	for _, tag := range doc.Tags {
+		tags[tag.Name] = len(p.Tags)
+		p.Tags = append(p.Tags, pageTag{Name: tag.Name, Description: tag.Description})
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		var shared []*docsParameter
+		if raw, ok := doc.Paths[path]["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("parse parameters of %s: %w", path, err)
+			}
+		}
+		for _, method := range methods {
+			raw, ok := doc.Paths[path][method]
+			if !ok {
+				continue
+			}
+			var op docsOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("parse %s %s: %w", method, path, err)
+			}
+			po, err := doc.operation(strings.ToUpper(method), path, shared, &op)
+			if err != nil {
+				return nil, err
+			}
+			for _, tag := range op.Tags {
+				i, ok := tags[tag]
+				if !ok {
+					return nil, fmt.Errorf("%s %s: unknown tag %q", method, path, tag)
+				}
+				p.Tags[i].Operations = append(p.Tags[i].Operations, po)
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		s := doc.Components.Schemas[name]
+		p.Schemas = append(p.Schemas, pageSchema{Name: name, Description: paragraphs(s.Description), Properties: properties(s)})
+	}
+
+	t, err := template.New("docs").Parse(docsTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// operation resolves the references of op into the page
+func (doc *docsDocument) operation(method, path string, shared []*docsParameter, op *docsOperation) (pageOperation, error) {
+	po := pageOperation{ID: op.OperationID, Method: method, Path: path, Summary: op.Summary, Description: paragraphs(op.Description)}
+	if op.Security != nil {
+		for _, requirement := range *op.Security {
+			for _, scopes := range requirement {
+				po.Scopes = append(po.Scopes, scopes...)
+			}
+		}
+	}
+	for _, param := range append(slices.Clone(shared), op.Parameters...) {
+		if param.Ref != "" {
+			resolved, ok := doc.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+			if !ok {
+				return po, fmt.Errorf("%s %s: unresolved %s", method, path, param.Ref)
+			}
+			param = resolved
+		}
+		po.Parameters = append(po.Parameters, pageField{
+			Name: param.Name, In: param.In, Type: typeName(param.Schema), Description: param.Description, Required: param.Required,
+		})
+	}
+	if op.RequestBody != nil {
+		po.Body = media(op.RequestBody.Content)
+	}
+	for _, status := range slices.Sorted(maps.Keys(op.Responses)) {
+		res := op.Responses[status]
+		if res.Ref != "" {
+			resolved, ok := doc.Components.Responses[strings.TrimPrefix(res.Ref, "#/components/responses/")]
+			if !ok {
+				return po, fmt.Errorf("%s %s: unresolved %s", method, path, res.Ref)
+			}
+			res = resolved
+		}
+		po.Responses = append(po.Responses, pageResponse{Status: status, Description: res.Description, Content: media(res.Content)})
+	}
+	return po, nil
+}
+
+func media(content docsContent) []pageMedia {
+	var m []pageMedia
+	for _, mediaType := range slices.Sorted(maps.Keys(content)) {
+		m = append(m, pageMedia{MediaType: mediaType, Type: typeName(content[mediaType].Schema)})
+	}
+	return m
+}
+
+func properties(s *docsSchema) []pageField {
+	var fields []pageField
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		prop := s.Properties[name]
+		fields = append(fields, pageField{
+			Name: name, Type: typeName(prop), Description: prop.Description, Required: slices.Contains(s.Required, name),
+		})
+	}
+	return fields
+}
+
+// typeName describes a schema in a few words, such as "array of News"
+func typeName(s *docsSchema) string {
+	switch {
+	case s == nil:
+		return ""
+	case s.Ref != "":
+		return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+	case s.Type == "array":
+		return "array of " + typeName(s.Items)
+	case len(s.Enum) > 0:
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprint(v)
+			if str, ok := v.(string); ok {
+				values[i] = strconv.Quote(str)
+			}
+		}
+		return s.Type + " (" + strings.Join(values, ", ") + ")"
+	case s.Format != "":
+		return s.Type + " (" + s.Format + ")"
+	}
+	return s.Type
+}
+
+// paragraphs splits a description on its blank lines
+func paragraphs(text string) []string {
+	var ps []string
+	for _, p := range strings.Split(text, "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
@@ -0,0 +1,23 @@
+// Package openapi embeds the OpenAPI 3.1 description of the api and renders
+// it as browsable documentation.
+package openapi
+
+import (
+	_ "embed"
+)
+
+// spec must describe every route of router.New, its tests fail otherwise
+//
+//go:embed openapi.json
+var spec []byte
+
+// Spec returns the OpenAPI document as json
+func Spec() []byte {
+	return spec
+}
+
+// Docs returns an html page describing the document served on /openapi.json.
+// It is rendered on the server, so the page loads no script.
+func Docs() ([]byte, error) {
+	return docs()
+}
@@ -0,0 +1,120 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/model"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func spec(tb testing.TB) map[string]any {
+	tb.Helper()
+	var spec map[string]any
+	require.NoError(tb, json.Unmarshal(openapi.Spec(), &spec))
+	return spec
+}
+
+func Test_SpecVersion(t *testing.T) {
+	assert.Equal(t, "3.1.0", spec(t)["openapi"])
+}
+
+func Test_SpecReferencesResolve(t *testing.T) {
+	doc := spec(t)
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				var target any = doc
+				for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+					m, _ := target.(map[string]any)
+					target = m[part]
+				}
+				assert.NotNil(t, target, "unresolved %s", ref)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+}
+
+// jsonFields returns the names of the json fields of v
+func jsonFields(v any) (fields []string) {
+	typ := reflect.TypeOf(v)
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || f.Anonymous || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+func Test_SpecSchemasMatchModels(t *testing.T) {
+	schemas := spec(t)["components"].(map[string]any)["schemas"].(map[string]any)
+	testcases := []struct {
+		schema string
+		model  any
+	}{
+		{schema: "NewsRequest", model: model.NewsRecord{}},
+		{schema: "NewsRecord", model: news.Record{}},
+		{schema: "WebhookRequest", model: model.WebhookRequest{}},
+		{schema: "Webhook", model: webhook.Subscription{}},
+		{schema: "Delivery", model: webhook.Delivery{}},
+		{schema: "Problem", model: problem.Problem{}},
+		{schema: "FieldError", model: problem.FieldError{}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.schema, func(t *testing.T) {
+			schema, ok := schemas[tc.schema].(map[string]any)
+			require.True(t, ok, "missing schema")
+			var properties []string
+			for name := range schema["properties"].(map[string]any) {
+				properties = append(properties, name)
+			}
+			assert.ElementsMatch(t, jsonFields(tc.model), properties)
+		})
+	}
+}
+
+func Test_Docs(t *testing.T) {
+	docs, err := openapi.Docs()
+	require.NoError(t, err)
+
+	page := string(docs)
+	for path, operations := range spec(t)["paths"].(map[string]any) {
+		for method, op := range operations.(map[string]any) {
+			if method == "parameters" {
+				continue
+			}
+			id := op.(map[string]any)["operationId"].(string)
+			assert.Contains(t, page, `id="`+id+`"`, "%s %s is not documented", method, path)
+		}
+	}
+	for name := range spec(t)["components"].(map[string]any)["schemas"].(map[string]any) {
+		assert.Contains(t, page, `id="schema-`+name+`"`)
+	}
+	// descriptions are escaped, not interpreted
+	assert.Contains(t, page, "&lt;token&gt;")
+	assert.NotContains(t, page, "<script")
+}
@@ -0,0 +1,369 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+)
+
+// schema is the subset of JSON Schema the document uses
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []any              `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+}
+
+type parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type operation struct {
+	Parameters  []*parameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool `json:"required"`
+		Content  map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+}
+
+type document struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas    map[string]*schema    `json:"schemas"`
+		Parameters map[string]*parameter `json:"parameters"`
+	} `json:"components"`
+}
+
+// Validator checks requests against the operations of an OpenAPI document
+type Validator struct {
+	operations map[string]*Operation
+}
+
+// Operation checks the requests of one operation
+type Operation struct {
+	params       []*parameter
+	body         *schema
+	bodyRequired bool
+}
+
+// NewValidator compiles the operations of the OpenAPI document spec
+func NewValidator(spec []byte) (*Validator, error) {
+	var doc document
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+	c := compiler{doc: &doc, resolved: map[*schema]bool{}}
+	v := &Validator{operations: map[string]*Operation{}}
+	for path, item := range doc.Paths {
+		var shared []*parameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("%s: parameters: %w", path, err)
+			}
+		}
+		for method, raw := range item {
+			if method == "parameters" {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			compiled := &Operation{}
+			for _, p := range slices.Concat(shared, op.Parameters) {
+				p, err := c.parameter(p)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", method, path, err)
+				}
+				compiled.params = append(compiled.params, p)
+			}
+			if body := op.RequestBody; body != nil {
+				if content, ok := jsonContent(body.Content); ok {
+					s, err := c.schema(content.Schema)
+					if err != nil {
+						return nil, fmt.Errorf("%s %s: request body: %w", method, path, err)
+					}
+					compiled.body = s
+					compiled.bodyRequired = body.Required
+				}
+			}
+			v.operations[strings.ToUpper(method)+" "+path] = compiled
+		}
+	}
+	return v, nil
+}
+
+// Operation returns the operation of a route pattern such as "GET /news/{news_id}"
+func (v *Validator) Operation(pattern string) (*Operation, bool) {
+	op, ok := v.operations[pattern]
+	return op, ok
+}
+
+// jsonContent returns the content of a json media type, such as
+// application/json or application/merge-patch+json
+func jsonContent[T any](content map[string]T) (T, bool) {
+	for mediaType, c := range content {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return c, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// compiler resolves the references of the document
+type compiler struct {
+	doc      *document
+	resolved map[*schema]bool
+}
+
+func (c *compiler) parameter(p *parameter) (*parameter, error) {
+	if p.Ref != "" {
+		name, _ := strings.CutPrefix(p.Ref, "#/components/parameters/")
+		target, ok := c.doc.Components.Parameters[name]
+		if !ok {
+			return nil, fmt.Errorf("unresolved %s", p.Ref)
+		}
+		p = target
+	}
+	s, err := c.schema(p.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+	}
+	p.Schema = s
+	return p, nil
+}
+
+// schema returns s with its references, and those of its subschemas, resolved
+func (c *compiler) schema(s *schema) (*schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.Ref != "" {
+		name, _ := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		target, ok := c.doc.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unresolved %s", s.Ref)
+		}
+		s = target
+	}
+	if c.resolved[s] {
+		return s, nil
+	}
+	c.resolved[s] = true
+	for name, property := range s.Properties {
+		resolved, err := c.schema(property)
+		if err != nil {
+			return nil, err
+		}
+		s.Properties[name] = resolved
+	}
+	items, err := c.schema(s.Items)
+	if err != nil {
+		return nil, err
+	}
+	s.Items = items
+	return s, nil
+}
+
+// Validate reports the path and query parameters and the json body members
+// of r that do not match the operation. Unknown query parameters are
+// allowed, unknown body members are not when the schema says so. The body
+// is read and put back for the handler; err reports a body that could not
+// be read.
+func (op *Operation) Validate(r *http.Request) (errs []problem.FieldError, err error) {
+	query := r.URL.Query()
+	for _, p := range op.params {
+		var value string
+		switch p.In {
+		case "path":
+			value = r.PathValue(p.Name)
+		case "query":
+			value = query.Get(p.Name)
+		default:
+			continue
+		}
+		if value == "" {
+			if p.Required {
+				errs = append(errs, problem.FieldError{In: p.In, Path: p.Name, Detail: "is required"})
+			}
+			continue
+		}
+		errs = p.Schema.validate(errs, p.In, p.Name, parseParam(p.Schema, value))
+	}
+
+	if op.body == nil {
+		return errs, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return errs, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.bodyRequired {
+			errs = append(errs, problem.FieldError{In: "body", Path: "$", Detail: "is required"})
+		}
+		return errs, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return append(errs, problem.FieldError{In: "body", Path: "$", Detail: "must be valid json"}), nil
+	}
+	return op.body.validate(errs, "body", "$", v), nil
+}
+
+// parseParam converts a parameter value to the json value its schema expects,
+// leaving it a string when it does not parse so that validate reports it
+func parseParam(s *schema, value string) any {
+	switch s.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validate appends to errs the problems of the value v found at path
+func (s *schema) validate(errs []problem.FieldError, in, path string, v any) []problem.FieldError {
+	if s == nil {
+		return errs
+	}
+	fail := func(format string, args ...any) []problem.FieldError {
+		return append(errs, problem.FieldError{In: in, Path: path, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fail("must be an object")
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, problem.FieldError{In: in, Path: path + "." + name, Detail: "is required"})
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(obj)) {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					errs = append(errs, problem.FieldError{In: in, Path: path + "." + name, Detail: "is not allowed"})
+				}
+				continue
+			}
+			errs = property.validate(errs, in, path+"."+name, obj[name])
+		}
+		return errs
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return fail("must be an array")
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			if *s.MinItems == 1 {
+				errs = fail("must not be empty")
+			} else {
+				errs = fail("must have at least %d items", *s.MinItems)
+			}
+		}
+		for i, item := range arr {
+			errs = s.Items.validate(errs, in, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+		return errs
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if s.MinLength != nil && len([]rune(str)) < *s.MinLength {
+			if *s.MinLength == 1 {
+				return fail("must not be empty")
+			}
+			return fail("must be at least %d characters long", *s.MinLength)
+		}
+		if detail := checkFormat(s.Format, str); detail != "" {
+			return fail("%s", detail)
+		}
+	case "integer", "number":
+		num, ok := v.(json.Number)
+		f, err := num.Float64()
+		if !ok || err != nil {
+			return fail("must be a number")
+		}
+		if s.Type == "integer" && strings.ContainsAny(num.String(), ".eE") {
+			return fail("must be an integer")
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fail("must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fail("must be a boolean")
+		}
+	}
+
+	if len(s.Enum) != 0 && !slices.Contains(s.Enum, v) {
+		var values []string
+		for _, e := range s.Enum {
+			values = append(values, fmt.Sprint(e))
+		}
+		return fail("must be one of %s", strings.Join(values, ", "))
+	}
+	return errs
+}
+
+// checkFormat describes how str fails the format, or returns "" when it does not
+func checkFormat(format, str string) string {
+	switch format {
+	case "uuid":
+		if _, err := uuid.Parse(str); err != nil {
+			return "must be a uuid"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "uri":
+		if u, err := url.Parse(str); err != nil || !u.IsAbs() {
+			return "must be an absolute uri"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package openapi_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validNews = `{"author": "Batman", "title": "Gotham", "summary": "Night", "content": "Long night", "source": "https://gotham.example.com", "created_at": "2026-10-19T13:00:00Z", "tags": ["dc"]}`
+
+func Test_OperationValidate(t *testing.T) {
+	testcases := []struct {
+		name           string
+		pattern        string
+		target         string
+		pathValues     map[string]string
+		body           string
+		expectedErrors []problem.FieldError
+	}{
+		{
+			name:    "accept_valid_news",
+			pattern: "POST /v1/news",
+			target:  "/v1/news",
+			body:    validNews,
+		},
+		{
+			name:    "reject_unknown_field",
+			pattern: "POST /v1/news",
+			target:  "/v1/news",
+			body:    strings.Replace(validNews, `{`, `{"foo": 1, `, 1),
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$.foo", Detail: "is not allowed"},
+			},
+		},
+		{
+			name:    "reject_missing_and_mistyped_fields",
+			pattern: "POST /v1/news",
+			target:  "/v1/news",
+			body:    `{"author": "", "title": 42, "summary": "Night", "content": "Long night", "source": "gotham", "created_at": "yesterday", "tags": ["dc", 7]}`,
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$.author", Detail: "must not be empty"},
+				{In: "body", Path: "$.created_at", Detail: "must be an RFC 3339 date-time"},
+				{In: "body", Path: "$.source", Detail: "must be an absolute uri"},
+				{In: "body", Path: "$.tags[1]", Detail: "must be a string"},
+				{In: "body", Path: "$.title", Detail: "must be a string"},
+			},
+		},
+		{
+			name:    "reject_missing_required_field",
+			pattern: "PUT /v1/news/{news_id}",
+			target:  "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			pathValues: map[string]string{
+				"news_id": "c2f92052-348f-4372-b4bc-43dbbc88445a",
+			},
+			body: `{"author": "Batman", "title": "Gotham", "summary": "Night", "content": "Long night", "source": "https://gotham.example.com", "created_at": "2026-10-19T13:00:00Z"}`,
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$.tags", Detail: "is required"},
+			},
+		},
+		{
+			name:       "validate_merge_patch",
+			pattern:    "PATCH /v1/news/{news_id}",
+			target:     "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			pathValues: map[string]string{"news_id": "c2f92052-348f-4372-b4bc-43dbbc88445a"},
+			body:       `{"title": null, "editor": "Robin"}`,
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$.editor", Detail: "is not allowed"},
+				{In: "body", Path: "$.title", Detail: "must be a string"},
+			},
+		},
+		{
+			name:    "reject_invalid_json",
+			pattern: "POST /v1/news",
+			target:  "/v1/news",
+			body:    `{"author": `,
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$", Detail: "must be valid json"},
+			},
+		},
+		{
+			name:    "reject_missing_body",
+			pattern: "POST /v1/news",
+			target:  "/v1/news",
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$", Detail: "is required"},
+			},
+		},
+		{
+			name:       "reject_invalid_path_param",
+			pattern:    "GET /v1/news/{news_id}",
+			target:     "/v1/news/42",
+			pathValues: map[string]string{"news_id": "42"},
+			expectedErrors: []problem.FieldError{
+				{In: "path", Path: "news_id", Detail: "must be a uuid"},
+			},
+		},
+		{
+			name:    "accept_valid_query_params",
+			pattern: "GET /v1/news",
+			target:  "/v1/news?limit=10&offset=0&sort=newest&unknown=1",
+		},
+		{
+			name:    "reject_invalid_query_params",
+			pattern: "GET /v1/news",
+			target:  "/v1/news?limit=0&offset=ten&sort=random&format=pdf",
+			expectedErrors: []problem.FieldError{
+				{In: "query", Path: "limit", Detail: "must be at least 1"},
+				{In: "query", Path: "offset", Detail: "must be a number"},
+				{In: "query", Path: "sort", Detail: "must be one of oldest, newest"},
+				{In: "query", Path: "format", Detail: "must be one of json, ndjson, csv, xml"},
+			},
+		},
+		{
+			name:    "reject_fractional_limit",
+			pattern: "GET /v1/news",
+			target:  "/v1/news?limit=1.5",
+			expectedErrors: []problem.FieldError{
+				{In: "query", Path: "limit", Detail: "must be an integer"},
+			},
+		},
+		{
+			name:    "reject_invalid_webhook",
+			pattern: "POST /v1/webhooks",
+			target:  "/v1/webhooks",
+			body:    `{"url": "https://partner.example.com/hooks", "events": ["news.created", "news.read"], "secret": "short"}`,
+			expectedErrors: []problem.FieldError{
+				{In: "body", Path: "$.events[1]", Detail: "must be one of news.created, news.updated, news.deleted"},
+				{In: "body", Path: "$.secret", Detail: "must be at least 16 characters long"},
+			},
+		},
+	}
+
+	v, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			op, ok := v.Operation(tc.pattern)
+			require.True(t, ok)
+			method, _, _ := strings.Cut(tc.pattern, " ")
+			r := httptest.NewRequest(method, tc.target, strings.NewReader(tc.body))
+			for name, value := range tc.pathValues {
+				r.SetPathValue(name, value)
+			}
+
+			errs, err := op.Validate(r)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedErrors, errs)
+			// the body is left for the handler
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(body))
+		})
+	}
+}
+
+func Test_ValidatorOperations(t *testing.T) {
+	v, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+
+	_, ok := v.Operation("GET /v1/webhooks/{webhook_id}/deliveries")
+	assert.True(t, ok)
+	_, ok = v.Operation(http.MethodPatch + " /v1/news")
+	assert.False(t, ok)
+
+	_, err = openapi.NewValidator([]byte(`{"paths": {"/news": {"get": {"parameters": [{"$ref": "#/components/parameters/Missing"}]}}}}`))
+	assert.ErrorContains(t, err, "unresolved #/components/parameters/Missing")
+}
@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Types of the news change events
+const (
+	NewsCreated = "news.created"
+	NewsUpdated = "news.updated"
+	NewsDeleted = "news.deleted"
+)
+
+// Event is a change of a record, stored in the outbox table until the relay
+// published it
+type Event struct {
+	bun.BaseModel `bun:"table:outbox" json:"-"`
+	Id            int64           `json:"id" bun:"id,pk,autoincrement"`
+	Type          string          `json:"type" bun:"type,notnull"`
+	RecordId      uuid.UUID       `json:"record_id" bun:"record_id,type:uuid,notnull"`
+	Payload       json.RawMessage `json:"payload" bun:"payload,type:jsonb,notnull"`
+	OccurredAt    time.Time       `json:"occurred_at" bun:"occurred_at,nullzero,notnull,default:current_timestamp"`
+	PublishedAt   time.Time       `json:"-" bun:"published_at,nullzero"`
+	ClaimedAt     time.Time       `json:"-" bun:"claimed_at,nullzero"`
+	Attempts      int             `json:"-" bun:"attempts,notnull"`
+	LastError     string          `json:"-" bun:"last_error,nullzero"`
+}
+
+// Add writes an event with the json of payload, pass the transaction of the
+// change so that the event exists if and only if the change was committed
+func Add(ctx context.Context, tx bun.IDB, typ string, recordId uuid.UUID, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s payload: %w", typ, err)
+	}
+	event := Event{
+		Type:       typ,
+		RecordId:   recordId,
+		Payload:    b,
+		OccurredAt: time.Now().UTC(),
+	}
+	if _, err := tx.NewInsert().Model(&event).Exec(ctx); err != nil {
+		return fmt.Errorf("insert %s event: %w", typ, err)
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+)
+
+// lockKey is the postgres advisory lock held while claiming a batch, so that
+// api-servers claim one after the other
+const lockKey = 7_240_043
+
+// Relay publishes the pending events to a sink in id order. A batch is
+// claimed for a lease in a short transaction, so that only one api-server
+// publishes at a time and the events keep their order, then published
+// without holding a transaction. An event is marked published once the sink
+// accepted it; when the sink fails, the relay retries the same event with
+// backoff before moving on, so that no event overtakes an earlier one.
+type Relay struct {
+	db         *bun.DB
+	sink       Sink
+	interval   time.Duration
+	batchSize  int
+	lease      time.Duration
+	maxBackoff time.Duration
+	retention  time.Duration
+	now        func() time.Time
+}
+
+// Option configures the Relay
+type Option func(*Relay)
+
+// WithInterval sets how often the relay looks for pending events
+func WithInterval(interval time.Duration) Option {
+	return func(r *Relay) {
+		r.interval = interval
+	}
+}
+
+// WithBatchSize sets how many events are published per round
+func WithBatchSize(size int) Option {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// WithLease sets how long a claimed batch is reserved to the relay, another
+// one takes over the events left when it expires
+func WithLease(lease time.Duration) Option {
+	return func(r *Relay) {
+		r.lease = lease
+	}
+}
+
+// WithMaxBackoff caps the wait between retries of a failing event
+func WithMaxBackoff(backoff time.Duration) Option {
+	return func(r *Relay) {
+		r.maxBackoff = backoff
+	}
+}
+
+// WithRetention sets how long published events are kept, 0 keeps them forever
+func WithRetention(retention time.Duration) Option {
+	return func(r *Relay) {
+		r.retention = retention
+	}
+}
+
+// WithClock replaces time.Now, for tests
+func WithClock(now func() time.Time) Option {
+	return func(r *Relay) {
+		r.now = now
+	}
+}
+
+func NewRelay(db *bun.DB, sink Sink, opts ...Option) *Relay {
+	r := &Relay{
+		db:         db,
+		sink:       sink,
+		interval:   time.Second,
+		batchSize:  100,
+		lease:      time.Minute,
+		maxBackoff: time.Minute,
+		retention:  7 * 24 * time.Hour,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run relays until ctx is done. Full batches are followed by the next one at
+// once, sink failures double the wait up to the max backoff.
+func (r *Relay) Run(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	var (
+		failures int
+		pruned   time.Time
+	)
+	for {
+		wait := r.interval
+		n, err := r.Flush(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			failures++
+			wait = min(r.interval<<min(failures, 20), r.maxBackoff)
+			log.Warn("relaying news events failed", "error", err, "retry_in", wait)
+		default:
+			failures = 0
+			if n == r.batchSize {
+				wait = 0
+			}
+		}
+		if r.retention > 0 && r.now().Sub(pruned) >= time.Hour {
+			if err := r.Prune(ctx); err != nil {
+				log.Warn("pruning published news events failed", "error", err)
+			}
+			pruned = r.now()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Flush publishes up to a batch of pending events and returns how many were
+// published. It stops at the first event the sink rejects, records the
+// failure on it and returns the error of the sink. It publishes nothing while
+// another relay holds the lease of the oldest pending events, and stops when
+// its own lease expires.
+func (r *Relay) Flush(ctx context.Context) (published int, err error) {
+	events, claimedAt, err := r.claim(ctx)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	for i, event := range events {
+		if !r.now().Before(claimedAt.Add(r.lease)) {
+			// the events left may already be claimed by another relay
+			return published, nil
+		}
+		if err := r.sink.Publish(ctx, event); err != nil {
+			return published, r.fail(ctx, events[i:], claimedAt, err)
+		}
+		_, err := r.db.NewUpdate().Model((*Event)(nil)).
+			Set("published_at = ?", r.now().UTC()).
+			Set("claimed_at = NULL").
+			Where("id = ?", event.Id).
+			Exec(ctx)
+		if err != nil {
+			return published, fmt.Errorf("mark event %d published: %w", event.Id, err)
+		}
+		published++
+	}
+	return published, nil
+}
+
+// claim leases the oldest batch of pending events to the relay, unless
+// another relay holds an unexpired lease on any of them
+func (r *Relay) claim(ctx context.Context) (events []Event, claimedAt time.Time, err error) {
+	err = r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if tx.Dialect().Name() == dialect.PG {
+			var locked bool
+			if err := tx.NewRaw("SELECT pg_try_advisory_xact_lock(?)", lockKey).Scan(ctx, &locked); err != nil {
+				return fmt.Errorf("lock outbox: %w", err)
+			}
+			if !locked {
+				return nil
+			}
+		}
+		err := tx.NewSelect().Model(&events).Where("published_at IS NULL").OrderExpr("id").Limit(r.batchSize).Scan(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("select pending events: %w", err)
+		}
+		// postgres keeps microseconds, the claim is matched on its time
+		claimedAt = r.now().UTC().Truncate(time.Microsecond)
+		ids := make([]int64, 0, len(events))
+		for _, event := range events {
+			if !event.ClaimedAt.IsZero() && claimedAt.Before(event.ClaimedAt.Add(r.lease)) {
+				events = nil
+				return nil
+			}
+			ids = append(ids, event.Id)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		_, err = tx.NewUpdate().Model((*Event)(nil)).
+			Set("claimed_at = ?", claimedAt).
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("claim pending events: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return events, claimedAt, nil
+}
+
+// fail records the failure of the first of the events and releases the
+// claim on all of them, so that the next round retries them at once
+func (r *Relay) fail(ctx context.Context, events []Event, claimedAt time.Time, sinkErr error) error {
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.Id)
+	}
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model((*Event)(nil)).
+			Set("attempts = attempts + 1").
+			Set("last_error = ?", sinkErr.Error()).
+			Where("id = ?", events[0].Id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*Event)(nil)).
+			Set("claimed_at = NULL").
+			Where("id IN (?)", bun.In(ids)).
+			Where("claimed_at = ?", claimedAt).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("record failure of event %d: %w", events[0].Id, err)
+	}
+	return &publishError{event: events[0].Id, err: sinkErr}
+}
+
+// publishError is a failure of the sink, as opposed to one of the database
+type publishError struct {
+	event int64
+	err   error
+}
+
+func (e *publishError) Error() string {
+	return fmt.Sprintf("publish event %d: %v", e.event, e.err)
+}
+
+func (e *publishError) Unwrap() error {
+	return e.err
+}
+
+// Prune deletes the events published longer than the retention ago
+func (r *Relay) Prune(ctx context.Context) error {
+	_, err := r.db.NewDelete().Model((*Event)(nil)).
+		Where("published_at < ?", r.now().Add(-r.retention).UTC()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("delete published events: %w", err)
+	}
+	return nil
+}
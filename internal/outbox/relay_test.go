@@ -0,0 +1,279 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/migration"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/logeshwarann-dev/news-api-rest/internal/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/migrate"
+)
+
+// setupDB opens a migrated sqlite database in memory
+func setupDB(tb testing.TB) *bun.DB {
+	tb.Helper()
+	ctx := context.Background()
+	sqlite, err := db.NewDB(&db.Config{Dialect: db.SQLite, Path: ":memory:"})
+	require.NoError(tb, err)
+	tb.Cleanup(func() { sqlite.Close() })
+
+	m := migrate.NewMigrator(sqlite, migration.New(dialect.SQLite), migrate.WithMarkAppliedOnSuccess(true))
+	require.NoError(tb, m.Init(ctx))
+	_, err = m.Migrate(ctx)
+	require.NoError(tb, err)
+	return sqlite
+}
+
+// events returns every event of the outbox in id order
+func events(tb testing.TB, sqlite *bun.DB) (events []outbox.Event) {
+	tb.Helper()
+	require.NoError(tb, sqlite.NewSelect().Model(&events).OrderExpr("id").Scan(context.Background()))
+	return events
+}
+
+// recorder is a sink remembering the published events, failing while err is set
+type recorder struct {
+	published []outbox.Event
+	err       error
+}
+
+func (r *recorder) Publish(_ context.Context, event outbox.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.published = append(r.published, event)
+	return nil
+}
+
+func (r *recorder) types() (types []string) {
+	for _, e := range r.published {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestStore_WritesEvents(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := news.NewStore(sqlite)
+
+	created, err := s.Create(ctx, storetest.Record("Batman", time.Time{}, "dc"))
+	require.NoError(t, err)
+	update := created
+	update.Title = "Gotham at night"
+	require.NoError(t, s.UpdateById(ctx, created.Id, update))
+	require.NoError(t, s.DeleteById(ctx, created.Id))
+
+	// changes of nothing write no event
+	assert.Error(t, s.UpdateById(ctx, uuid.New(), update))
+	require.NoError(t, s.DeleteById(ctx, created.Id))
+	// nor do failed changes
+	_, err = s.Create(ctx, news.Record{Author: "Robin"})
+	require.Error(t, err)
+
+	got := events(t, sqlite)
+	require.Len(t, got, 3)
+	for i, typ := range []string{outbox.NewsCreated, outbox.NewsUpdated, outbox.NewsDeleted} {
+		assert.Equal(t, typ, got[i].Type)
+		assert.Equal(t, created.Id, got[i].RecordId)
+		assert.False(t, got[i].OccurredAt.IsZero())
+		assert.True(t, got[i].PublishedAt.IsZero())
+	}
+	var payload news.Record
+	require.NoError(t, json.Unmarshal(got[1].Payload, &payload))
+	assert.Equal(t, "Gotham at night", payload.Title)
+	require.NoError(t, json.Unmarshal(got[2].Payload, &payload))
+	assert.False(t, payload.DeletedAt.IsZero())
+}
+
+func TestRelay_Flush(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := news.NewStore(sqlite)
+	for _, author := range []string{"Batman", "Superman", "Flash"} {
+		_, err := s.Create(ctx, storetest.Record(author, time.Time{}, "dc"))
+		require.NoError(t, err)
+	}
+	sink := &recorder{err: errors.New("connection refused")}
+	relay := outbox.NewRelay(sqlite, sink, outbox.WithBatchSize(2))
+
+	n, err := relay.Flush(ctx)
+	assert.ErrorContains(t, err, "publish event 1: connection refused")
+	assert.Zero(t, n)
+	n, err = relay.Flush(ctx)
+	assert.Error(t, err)
+	assert.Zero(t, n)
+	got := events(t, sqlite)
+	assert.Equal(t, 2, got[0].Attempts, "the first event is retried")
+	assert.Equal(t, "connection refused", got[0].LastError)
+	assert.Zero(t, got[1].Attempts, "later events wait for the first one")
+
+	sink.err = nil
+	n, err = relay.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = relay.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = relay.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "published events are not published again")
+
+	require.Len(t, sink.published, 3)
+	for i, e := range sink.published {
+		assert.Equal(t, int64(i+1), e.Id)
+		var payload news.Record
+		require.NoError(t, json.Unmarshal(e.Payload, &payload))
+		assert.Equal(t, e.RecordId, payload.Id)
+	}
+	for _, e := range events(t, sqlite) {
+		assert.False(t, e.PublishedAt.IsZero())
+	}
+}
+
+func TestRelay_PublishesOutsideTransactions(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := news.NewStore(sqlite)
+	for _, author := range []string{"Batman", "Superman"} {
+		_, err := s.Create(ctx, storetest.Record(author, time.Time{}, "dc"))
+		require.NoError(t, err)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	slow := outbox.SinkFunc(func(context.Context, outbox.Event) error {
+		once.Do(func() { close(started) })
+		<-release
+		return nil
+	})
+	relay := outbox.NewRelay(sqlite, slow)
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		n, err := relay.Flush(ctx)
+		done <- result{n, err}
+	}()
+	<-started
+
+	// the single sqlite connection is free while the sink publishes
+	_, err := s.Create(ctx, storetest.Record("Flash", time.Time{}, "dc"))
+	require.NoError(t, err)
+	other := &recorder{}
+	n, err := outbox.NewRelay(sqlite, other).Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "the claimed events are left to the first relay")
+	assert.Empty(t, other.published)
+
+	close(release)
+	got := <-done
+	require.NoError(t, got.err)
+	assert.Equal(t, 2, got.n)
+	n, err = outbox.NewRelay(sqlite, other).Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{outbox.NewsCreated}, other.types())
+}
+
+func TestRelay_TakesOverExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := news.NewStore(sqlite)
+	_, err := s.Create(ctx, storetest.Record("Batman", time.Time{}, "dc"))
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	// the first relay claims and stalls past its lease before publishing
+	var calls int
+	stalled := outbox.NewRelay(sqlite, &recorder{}, outbox.WithClock(func() time.Time {
+		calls++
+		if calls > 1 {
+			return now.Add(2 * time.Minute)
+		}
+		return now
+	}))
+	n, err := stalled.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	sink := &recorder{}
+	n, err = outbox.NewRelay(sqlite, sink, outbox.WithClock(func() time.Time { return now.Add(30 * time.Second) })).Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "the lease is still held")
+	n, err = outbox.NewRelay(sqlite, sink, outbox.WithClock(func() time.Time { return now.Add(time.Minute) })).Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "the expired lease is taken over")
+	got := events(t, sqlite)
+	assert.False(t, got[0].PublishedAt.IsZero())
+	assert.True(t, got[0].ClaimedAt.IsZero())
+}
+
+func TestRelay_Prune(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := news.NewStore(sqlite)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	sink := &recorder{}
+	relay := outbox.NewRelay(sqlite, sink, outbox.WithRetention(time.Hour), outbox.WithClock(func() time.Time { return now }))
+
+	_, err := s.Create(ctx, storetest.Record("Batman", time.Time{}, "dc"))
+	require.NoError(t, err)
+	_, err = relay.Flush(ctx)
+	require.NoError(t, err)
+	now = now.Add(30 * time.Minute)
+	_, err = s.Create(ctx, storetest.Record("Superman", time.Time{}, "dc"))
+	require.NoError(t, err)
+	_, err = relay.Flush(ctx)
+	require.NoError(t, err)
+	_, err = s.Create(ctx, storetest.Record("Flash", time.Time{}, "dc"))
+	require.NoError(t, err)
+
+	now = now.Add(45 * time.Minute)
+	require.NoError(t, relay.Prune(ctx))
+
+	got := events(t, sqlite)
+	require.Len(t, got, 2, "only events published over an hour ago are deleted")
+	assert.Equal(t, int64(2), got[0].Id)
+	assert.True(t, got[1].PublishedAt.IsZero())
+}
+
+func TestRelay_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sqlite := setupDB(t)
+	s := news.NewStore(sqlite)
+	published := make(chan outbox.Event)
+	sink := outbox.SinkFunc(func(ctx context.Context, event outbox.Event) error {
+		published <- event
+		return nil
+	})
+	relay := outbox.NewRelay(sqlite, sink, outbox.WithInterval(time.Millisecond))
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	created, err := s.Create(context.Background(), storetest.Record("Batman", time.Time{}, "dc"))
+	require.NoError(t, err)
+	select {
+	case event := <-published:
+		assert.Equal(t, created.Id, event.RecordId)
+		assert.Equal(t, outbox.NewsCreated, event.Type)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not published")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
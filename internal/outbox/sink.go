@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+)
+
+// Sink publishes events. An event may be published more than once when the
+// relay stops between publishing it and marking it published.
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(ctx context.Context, event Event) error
+
+func (f SinkFunc) Publish(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// Sinks publishes to every sink in turn, an event failing on one of them is
+// published again to those before it on the retry
+func Sinks(sinks ...Sink) Sink {
+	return SinkFunc(func(ctx context.Context, event Event) error {
+		for _, sink := range sinks {
+			if err := sink.Publish(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LogSink logs the events with the logger of the context
+func LogSink() Sink {
+	return SinkFunc(func(ctx context.Context, event Event) error {
+		logger.FromContext(ctx).Info("news event", "event_id", event.Id, "type", event.Type, "record_id", event.RecordId)
+		return nil
+	})
+}
+
+// WebhookSink posts every event as json to a url
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink posts the events to url with client, http.DefaultClient when nil
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookSink{url: url, client: client}
+}
+
+// Publish succeeds when the endpoint answers with a 2xx status. The event id
+// is sent as Idempotency-Key so that the endpoint can drop redeliveries.
+func (s *WebhookSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strconv.FormatInt(event.Id, 10))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// FileSink appends every event as a json line to a file
+type FileSink struct {
+	m sync.Mutex
+	f *os.File
+}
+
+// NewFileSink opens path for appending, creating it when needed
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open event file: %w", err)
+	}
+	return &FileSink{f: f}, nil
+}
+
+// Publish succeeds once the line is synced to disk
+func (s *FileSink) Publish(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if _, err := s.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("sync event file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
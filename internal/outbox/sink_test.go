@@ -0,0 +1,87 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(id int64) outbox.Event {
+	return outbox.Event{
+		Id:         id,
+		Type:       outbox.NewsCreated,
+		RecordId:   uuid.MustParse("1a2b3c4d-0000-4000-8000-000000000001"),
+		Payload:    json.RawMessage(`{"author":"Batman"}`),
+		OccurredAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	testcases := []struct {
+		name        string
+		status      int
+		expectedErr string
+	}{
+		{name: "accept_2xx", status: http.StatusAccepted},
+		{name: "reject_other_status", status: http.StatusServiceUnavailable, expectedErr: "webhook answered 503 Service Unavailable"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				header http.Header
+				body   []byte
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			err := outbox.NewWebhookSink(srv.URL, nil).Publish(context.Background(), testEvent(42))
+
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "application/json", header.Get("Content-Type"))
+			assert.Equal(t, "42", header.Get("Idempotency-Key"))
+			assert.JSONEq(t, `{"id":42,"type":"news.created","record_id":"1a2b3c4d-0000-4000-8000-000000000001","payload":{"author":"Batman"},"occurred_at":"2024-01-01T09:00:00Z"}`, string(body))
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"id\":1}\n"), 0o644))
+
+	sink, err := outbox.NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Publish(context.Background(), testEvent(2)))
+	require.NoError(t, sink.Publish(context.Background(), testEvent(3)))
+	require.NoError(t, sink.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	require.Len(t, lines, 3, "events are appended")
+	assert.Equal(t, `{"id":1}`, lines[0])
+	var event outbox.Event
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+	assert.Equal(t, int64(3), event.Id)
+	assert.Equal(t, outbox.NewsCreated, event.Type)
+}
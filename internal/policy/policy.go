@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+)
+
+// Action is an operation performed on a news record
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionAttribute changes the author of a record to someone else
+	ActionAttribute Action = "attribute"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// actionScopes maps actions to the scope required from principals without roles, such as api keys
+var actionScopes = map[Action]auth.Scope{
+	ActionRead:      auth.ScopeNewsRead,
+	ActionCreate:    auth.ScopeNewsWrite,
+	ActionUpdate:    auth.ScopeNewsWrite,
+	ActionDelete:    auth.ScopeNewsDelete,
+	ActionAttribute: auth.ScopeNewsWrite,
+}
+
+// Rule grants a role some actions, optionally only on records the caller created
+type Rule struct {
+	Role    auth.Role
+	Actions []Action
+	OwnedBy bool
+}
+
+func (r Rule) allows(p auth.Principal, action Action, record news.Record) bool {
+	if !p.HasRole(r.Role) || !slices.Contains(r.Actions, action) {
+		return false
+	}
+	return !r.OwnedBy || AuthoredBy(p, record)
+}
+
+// AuthoredBy reports whether the principal created the record, by its
+// subject: the author is a display name chosen by the client
+func AuthoredBy(p auth.Principal, record news.Record) bool {
+	return record.Owner != "" && record.Owner == p.Subject
+}
+
+// DefaultRules lets readers read, reporters modify only their own news and
+// editors modify and attribute anything
+var DefaultRules = []Rule{
+	{Role: auth.RoleReader, Actions: []Action{ActionRead}},
+	{Role: auth.RoleReporter, Actions: []Action{ActionRead, ActionCreate}},
+	{Role: auth.RoleReporter, Actions: []Action{ActionUpdate, ActionDelete}, OwnedBy: true},
+	{Role: auth.RoleEditor, Actions: []Action{ActionRead, ActionCreate, ActionUpdate, ActionDelete, ActionAttribute}},
+	{Role: auth.RoleAdmin, Actions: []Action{ActionRead, ActionCreate, ActionUpdate, ActionDelete, ActionAttribute}},
+}
+
+// Policy authorizes actions on news records against a list of rules
+type Policy struct {
+	rules []Rule
+}
+
+func New(rules ...Rule) *Policy {
+	return &Policy{
+		rules: rules,
+	}
+}
+
+// Authorize returns an error wrapping ErrForbidden unless a rule allows the caller the action on the record.
+// Principals without roles are authorized by the scope matching the action.
+func (p *Policy) Authorize(ctx context.Context, action Action, record news.Record) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: unauthenticated caller may not %s news", ErrForbidden, action)
+	}
+	if principal.HasScope(auth.ScopeAdmin) {
+		return nil
+	}
+	if len(principal.Roles) == 0 {
+		if principal.HasScope(actionScopes[action]) {
+			return nil
+		}
+	}
+	for _, rule := range p.rules {
+		if rule.allows(principal, action, record) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s may not %s news %s", ErrForbidden, principal.Subject, action, record.Id)
+}
+
+// AllowAll authorizes every action, it is used when authentication is disabled
+type AllowAll struct{}
+
+func (AllowAll) Authorize(context.Context, Action, news.Record) error {
+	return nil
+}
@@ -0,0 +1,109 @@
+package policy_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Authorize(t *testing.T) {
+	own := news.Record{Author: "Clark Kent", Owner: "u2"}
+	other := news.Record{Author: "Lois Lane", Owner: "u6"}
+	reader := &auth.Principal{Subject: "u1", Name: "Jimmy", Roles: []auth.Role{auth.RoleReader}}
+	reporter := &auth.Principal{Subject: "u2", Name: "Clark Kent", Roles: []auth.Role{auth.RoleReporter}}
+	editor := &auth.Principal{Subject: "u3", Name: "Perry", Roles: []auth.Role{auth.RoleEditor}}
+
+	testcases := []struct {
+		name      string
+		principal *auth.Principal
+		action    policy.Action
+		record    news.Record
+		allowed   bool
+	}{
+		{name: "deny_anonymous", action: policy.ActionRead, record: own},
+		{name: "reader_may_read", principal: reader, action: policy.ActionRead, record: other, allowed: true},
+		{name: "reader_may_not_update", principal: reader, action: policy.ActionUpdate, record: other},
+		{name: "reader_may_not_delete", principal: reader, action: policy.ActionDelete, record: other},
+		{name: "reporter_may_create", principal: reporter, action: policy.ActionCreate, record: news.Record{}, allowed: true},
+		{name: "reporter_may_update_own", principal: reporter, action: policy.ActionUpdate, record: own, allowed: true},
+		{name: "reporter_may_delete_own", principal: reporter, action: policy.ActionDelete, record: own, allowed: true},
+		{name: "reporter_may_not_update_others", principal: reporter, action: policy.ActionUpdate, record: other},
+		{name: "reporter_may_not_delete_others", principal: reporter, action: policy.ActionDelete, record: other},
+		{
+			name:      "reporter_named_like_author_does_not_own",
+			principal: &auth.Principal{Subject: "u7", Name: "Lois Lane", Roles: []auth.Role{auth.RoleReporter}},
+			action:    policy.ActionDelete,
+			record:    other,
+		},
+		{
+			name:      "reporter_subject_like_author_does_not_own",
+			principal: &auth.Principal{Subject: "Lois Lane", Roles: []auth.Role{auth.RoleReporter}},
+			action:    policy.ActionDelete,
+			record:    other,
+		},
+		{
+			name:      "nobody_owns_unowned_record",
+			principal: &auth.Principal{Subject: "", Roles: []auth.Role{auth.RoleReporter}},
+			action:    policy.ActionDelete,
+			record:    news.Record{Author: "Lois Lane"},
+		},
+		{name: "reporter_may_not_attribute_own", principal: reporter, action: policy.ActionAttribute, record: own},
+		{name: "editor_may_update_others", principal: editor, action: policy.ActionUpdate, record: other, allowed: true},
+		{name: "editor_may_delete_others", principal: editor, action: policy.ActionDelete, record: other, allowed: true},
+		{name: "editor_may_attribute_others", principal: editor, action: policy.ActionAttribute, record: other, allowed: true},
+		{
+			name:      "unknown_role_denied",
+			principal: &auth.Principal{Subject: "u4", Roles: []auth.Role{"intern"}},
+			action:    policy.ActionRead,
+			record:    other,
+		},
+		{
+			name:      "admin_scope_allowed",
+			principal: &auth.Principal{Subject: "u5", Roles: []auth.Role{auth.RoleReader}, Scopes: []auth.Scope{auth.ScopeAdmin}},
+			action:    policy.ActionDelete,
+			record:    other,
+			allowed:   true,
+		},
+		{
+			name:      "api_key_with_delete_scope_allowed",
+			principal: &auth.Principal{Subject: "key-1", Scopes: []auth.Scope{auth.ScopeNewsDelete}},
+			action:    policy.ActionDelete,
+			record:    other,
+			allowed:   true,
+		},
+		{
+			name:      "api_key_without_write_scope_denied",
+			principal: &auth.Principal{Subject: "key-1", Scopes: []auth.Scope{auth.ScopeNewsRead}},
+			action:    policy.ActionUpdate,
+			record:    other,
+		},
+	}
+
+	p := policy.New(policy.DefaultRules...)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = auth.CtxWithPrincipal(ctx, *tc.principal)
+			}
+			err := p.Authorize(ctx, tc.action, tc.record)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, policy.ErrForbidden)
+			}
+		})
+	}
+}
+
+func Test_CustomRules(t *testing.T) {
+	p := policy.New(policy.Rule{Role: auth.RoleEditor, Actions: []policy.Action{policy.ActionRead}})
+	ctx := auth.CtxWithPrincipal(context.Background(), auth.Principal{Subject: "u3", Roles: []auth.Role{auth.RoleEditor}})
+
+	assert.NoError(t, p.Authorize(ctx, policy.ActionRead, news.Record{}))
+	assert.ErrorIs(t, p.Authorize(ctx, policy.ActionDelete, news.Record{}), policy.ErrForbidden)
+}
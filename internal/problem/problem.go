@@ -0,0 +1,64 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ContentType is the media type of problem details bodies
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Errors lists the invalid parts of a rejected request
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError points at an invalid part of a request: a path or query
+// parameter by its name, or a json body member by its JSON path such as
+// $.tags[0]
+type FieldError struct {
+	In     string `json:"in"`
+	Path   string `json:"path"`
+	Detail string `json:"detail"`
+}
+
+// New returns a problem titled after the http status
+func New(status int, detail string) Problem {
+	return Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// TooLarge returns the 413 problem answering err when it comes from reading
+// a request body past its size limit
+func TooLarge(err error) (Problem, bool) {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return Problem{}, false
+	}
+	return New(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", maxErr.Limit)), true
+}
+
+func (p Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// Write sends the problem as the response
+func Write(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
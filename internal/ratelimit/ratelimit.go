@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket holding Requests tokens, refilled evenly over Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result describes the bucket after a token was requested
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available when the request was denied
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. The in memory store is per process, a shared
+// backend can implement Store to enforce limits across replicas.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// sweepInterval is how often idle buckets are dropped from the memory store
+const sweepInterval = time.Minute
+
+// MemoryStore is an in process Store
+type MemoryStore struct {
+	m         sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// Option configures the MemoryStore
+type Option func(*MemoryStore)
+
+// WithClock replaces time.Now, it is meant for tests
+func WithClock(now func() time.Time) Option {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+func NewMemoryStore(opts ...Option) *MemoryStore {
+	s := &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.lastSweep = s.now()
+	return s
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Requests), last: now, limit: limit}
+		s.buckets[key] = b
+	}
+	rate := limit.rate()
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((float64(limit.Requests) - b.tokens) / rate)
+	return res, nil
+}
+
+// sweep drops the buckets that have refilled completely, they are equivalent to new ones
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.limit.rate() >= float64(b.limit.Requests) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,43 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MemoryStoreTake(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	s := ratelimit.NewMemoryStore(ratelimit.WithClock(func() time.Time { return now }))
+	limit := ratelimit.Limit{Requests: 2, Period: 2 * time.Second}
+	ctx := context.Background()
+
+	res, _ := s.Take(ctx, "client-a", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	assert.Equal(t, 2, res.Limit)
+
+	res, _ = s.Take(ctx, "client-a", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 2*time.Second, res.Reset)
+
+	res, _ = s.Take(ctx, "client-a", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Second, res.RetryAfter)
+
+	res, _ = s.Take(ctx, "client-b", limit)
+	assert.True(t, res.Allowed, "buckets are per key")
+
+	now = now.Add(time.Second)
+	res, _ = s.Take(ctx, "client-a", limit)
+	assert.True(t, res.Allowed, "one token refilled after a second")
+
+	now = now.Add(time.Hour)
+	res, _ = s.Take(ctx, "client-a", limit)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining, "refill is capped at the bucket size")
+}
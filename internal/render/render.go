@@ -0,0 +1,270 @@
+// Package render encodes response bodies in the media type negotiated with the client.
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/logeshwarann-dev/news-api-rest/internal/problem"
+)
+
+// ErrNotAcceptable is returned when no encoder matches the request
+var ErrNotAcceptable = errors.New("no acceptable representation")
+
+// Encoder writes values in one media type
+type Encoder struct {
+	// Format selects the encoder with the format query parameter
+	Format string
+	// MediaTypes are matched against the Accept header, the first one is sent as Content-Type
+	MediaTypes []string
+	Encode     func(w io.Writer, v any) error
+	// stream frames items written one at a time, nil when the format cannot be streamed
+	stream func(w io.Writer, name string) itemWriter
+}
+
+// CanStream reports whether Stream supports the encoder
+func (e Encoder) CanStream() bool {
+	return e.stream != nil
+}
+
+// ContentType returns the media type sent with encoded responses
+func (e Encoder) ContentType() string {
+	return e.MediaTypes[0]
+}
+
+// Write encodes v and sends it with the status, a failing encoding results in a 500
+func (e Encoder) Write(w http.ResponseWriter, r *http.Request, status int, v any) {
+	var buf bytes.Buffer
+	if err := e.Encode(&buf, v); err != nil {
+		logger.FromContext(r.Context()).Error("failed encoding response", "format", e.Format, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", e.ContentType())
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// Collection is implemented by values holding a list of items, NDJSON writes one line per item
+type Collection interface {
+	Items() []any
+}
+
+// Table is implemented by values that can be written as CSV
+type Table interface {
+	CSVHeader() []string
+	CSVRecords() [][]string
+}
+
+// JSON writes a single json document
+var JSON = Encoder{
+	Format:     "json",
+	MediaTypes: []string{"application/json"},
+	Encode: func(w io.Writer, v any) error {
+		return json.NewEncoder(w).Encode(v)
+	},
+	stream: func(w io.Writer, name string) itemWriter {
+		return &jsonArrayWriter{w: w, name: name}
+	},
+}
+
+// NDJSON writes every item of a Collection as a json line
+var NDJSON = Encoder{
+	Format:     "ndjson",
+	MediaTypes: []string{"application/x-ndjson", "application/jsonl"},
+	Encode: func(w io.Writer, v any) error {
+		enc := json.NewEncoder(w)
+		c, ok := v.(Collection)
+		if !ok {
+			return enc.Encode(v)
+		}
+		for _, item := range c.Items() {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	stream: func(w io.Writer, _ string) itemWriter {
+		return ndjsonWriter{enc: json.NewEncoder(w)}
+	},
+}
+
+// CSV writes a Table with its header row
+var CSV = Encoder{
+	Format:     "csv",
+	MediaTypes: []string{"text/csv; charset=utf-8"},
+	Encode: func(w io.Writer, v any) error {
+		t, ok := v.(Table)
+		if !ok {
+			return fmt.Errorf("csv: %T is not a table", v)
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.CSVHeader()); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(t.CSVRecords()); err != nil {
+			return err
+		}
+		return cw.Error()
+	},
+}
+
+// XML writes an xml document
+var XML = Encoder{
+	Format:     "xml",
+	MediaTypes: []string{"application/xml", "text/xml"},
+	Encode: func(w io.Writer, v any) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	},
+}
+
+// Registry picks an encoder for a request among the registered ones
+type Registry struct {
+	encoders []Encoder
+}
+
+// NewRegistry returns a registry preferring the encoders in the given order,
+// the first one is used when the client does not state a preference
+func NewRegistry(encoders ...Encoder) *Registry {
+	return &Registry{encoders: encoders}
+}
+
+// Default serves JSON unless the client asks for NDJSON, CSV or XML
+var Default = NewRegistry(JSON, NDJSON, CSV, XML)
+
+// Negotiate selects the encoder named by the format query parameter, or else
+// the one best matching the Accept header
+func (reg *Registry) Negotiate(r *http.Request) (Encoder, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		for _, e := range reg.encoders {
+			if strings.EqualFold(e.Format, format) {
+				return e, nil
+			}
+		}
+		return Encoder{}, fmt.Errorf("%w: unknown format %q", ErrNotAcceptable, format)
+	}
+
+	ranges := parseAccept(r.Header.Values("Accept"))
+	if len(ranges) == 0 {
+		return reg.encoders[0], nil
+	}
+	// a q of 0 on an exact media type refuses it even when a wildcard matches
+	refused := func(mediaType string) bool {
+		return slices.ContainsFunc(ranges, func(rng mediaRange) bool {
+			return rng.q == 0 && rng.typ != "*" && rng.subtype != "*" && rng.matches(mediaType)
+		})
+	}
+	for _, rng := range ranges {
+		if rng.q == 0 {
+			break
+		}
+		for _, e := range reg.encoders {
+			if slices.ContainsFunc(e.MediaTypes, rng.matches) && !slices.ContainsFunc(e.MediaTypes, refused) {
+				return e, nil
+			}
+		}
+	}
+	return Encoder{}, fmt.Errorf("%w: none of %s", ErrNotAcceptable, strings.Join(reg.contentTypes(), ", "))
+}
+
+// Select negotiates the encoder and answers 406 when none is acceptable
+func (reg *Registry) Select(w http.ResponseWriter, r *http.Request) (Encoder, bool) {
+	w.Header().Add("Vary", "Accept")
+	e, err := reg.Negotiate(r)
+	if err != nil {
+		logger.FromContext(r.Context()).Info("response not acceptable", "accept", r.Header.Get("Accept"), "error", err)
+		problem.Write(w, problem.New(http.StatusNotAcceptable, err.Error()))
+		return Encoder{}, false
+	}
+	return e, true
+}
+
+// Select negotiates with the Default registry
+func Select(w http.ResponseWriter, r *http.Request) (Encoder, bool) {
+	return Default.Select(w, r)
+}
+
+// Formats returns the format names of the registered encoders
+func (reg *Registry) Formats() []string {
+	formats := make([]string, 0, len(reg.encoders))
+	for _, e := range reg.encoders {
+		formats = append(formats, e.Format)
+	}
+	return formats
+}
+
+func (reg *Registry) contentTypes() (types []string) {
+	for _, e := range reg.encoders {
+		types = append(types, e.ContentType())
+	}
+	return types
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func (m mediaRange) matches(mediaType string) bool {
+	typ, subtype, _ := strings.Cut(strings.TrimSpace(strings.Split(mediaType, ";")[0]), "/")
+	return (m.typ == "*" || strings.EqualFold(m.typ, typ)) &&
+		(m.subtype == "*" || strings.EqualFold(m.subtype, subtype))
+}
+
+// parseAccept returns the media ranges of the Accept headers by decreasing quality,
+// ranges of equal quality keep the client order
+func parseAccept(values []string) (ranges []mediaRange) {
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err != nil {
+				continue
+			}
+			typ, subtype, ok := strings.Cut(mediaType, "/")
+			if !ok {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil || q < 0 || q > 1 {
+					continue
+				}
+			}
+			ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b mediaRange) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+	return ranges
+}
@@ -0,0 +1,89 @@
+package render_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Negotiate(t *testing.T) {
+	testcases := []struct {
+		name           string
+		target         string
+		accept         []string
+		expectedFormat string
+		expectedErr    bool
+	}{
+		{name: "default_without_accept", target: "/news", expectedFormat: "json"},
+		{name: "any_type", target: "/news", accept: []string{"*/*"}, expectedFormat: "json"},
+		{name: "exact_type", target: "/news", accept: []string{"text/csv"}, expectedFormat: "csv"},
+		{name: "alias_type", target: "/news", accept: []string{"text/xml"}, expectedFormat: "xml"},
+		{name: "type_wildcard", target: "/news", accept: []string{"text/*"}, expectedFormat: "csv"},
+		{name: "highest_quality_wins", target: "/news", accept: []string{"application/json;q=0.5, application/xml"}, expectedFormat: "xml"},
+		{name: "client_order_on_equal_quality", target: "/news", accept: []string{"application/x-ndjson, application/json"}, expectedFormat: "ndjson"},
+		{name: "multiple_headers", target: "/news", accept: []string{"application/pdf", "text/csv;q=0.1"}, expectedFormat: "csv"},
+		{name: "refused_type_skipped_by_wildcard", target: "/news", accept: []string{"application/json;q=0, */*"}, expectedFormat: "ndjson"},
+		{name: "format_overrides_accept", target: "/news?format=CSV", accept: []string{"application/json"}, expectedFormat: "csv"},
+		{name: "unknown_format", target: "/news?format=yaml", expectedErr: true},
+		{name: "unsupported_type", target: "/news", accept: []string{"application/pdf"}, expectedErr: true},
+		{name: "only_refused_types", target: "/news", accept: []string{"*/*;q=0"}, expectedErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			for _, v := range tc.accept {
+				r.Header.Add("Accept", v)
+			}
+
+			enc, err := render.Default.Negotiate(r)
+
+			if tc.expectedErr {
+				assert.ErrorIs(t, err, render.ErrNotAcceptable)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedFormat, enc.Format)
+		})
+	}
+}
+
+type table struct{}
+
+func (table) CSVHeader() []string { return []string{"name", "note"} }
+
+func (table) CSVRecords() [][]string {
+	return [][]string{{"a", "plain"}, {"b", "has, comma"}}
+}
+
+func (table) Items() []any { return []any{map[string]int{"n": 1}, map[string]int{"n": 2}} }
+
+func Test_Encoders(t *testing.T) {
+	testcases := []struct {
+		name           string
+		encoder        render.Encoder
+		value          any
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "csv_quotes_fields", encoder: render.CSV, value: table{}, expectedStatus: http.StatusCreated, expectedBody: "name,note\na,plain\nb,\"has, comma\"\n"},
+		{name: "csv_rejects_non_table", encoder: render.CSV, value: 42, expectedStatus: http.StatusInternalServerError},
+		{name: "ndjson_line_per_item", encoder: render.NDJSON, value: table{}, expectedStatus: http.StatusCreated, expectedBody: "{\"n\":1}\n{\"n\":2}\n"},
+		{name: "ndjson_single_value", encoder: render.NDJSON, value: map[string]int{"n": 3}, expectedStatus: http.StatusCreated, expectedBody: "{\"n\":3}\n"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			tc.encoder.Write(w, r, http.StatusCreated, tc.value)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package render
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+)
+
+// FlushEvery is the number of streamed items sent to the client at once
+const FlushEvery = 100
+
+// itemWriter frames the items of a streamed response
+type itemWriter interface {
+	begin() error
+	item(v any) error
+	end() error
+}
+
+// Stream writes the items of seq as they are produced: a json document holding
+// them in an array under name, or one NDJSON line per item. The encoder must
+// support streaming.
+//
+// An error yielded before the first item is returned with nothing written, so
+// the caller can still answer with an error status. Once the response has
+// started, an error is logged and the connection aborted so that clients do
+// not mistake the truncated body for a complete one.
+func Stream[T any](e Encoder, w http.ResponseWriter, r *http.Request, status int, name string, seq iter.Seq2[T, error]) error {
+	log := logger.FromContext(r.Context())
+	rc := http.NewResponseController(w)
+	bw := bufio.NewWriter(w)
+	iw := e.stream(bw, name)
+	n := 0
+	abort := func(err error) {
+		// a write error usually means the client went away, leaving the
+		// range ends the sequence and releases the query
+		log.Error("streaming interrupted", "items", n, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+	flush := func() {
+		if err := bw.Flush(); err != nil {
+			abort(err)
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			abort(err)
+		}
+	}
+	start := func() {
+		w.Header().Set("Content-Type", e.ContentType())
+		w.WriteHeader(status)
+		if err := iw.begin(); err != nil {
+			abort(err)
+		}
+	}
+
+	for item, err := range seq {
+		if err != nil {
+			if n == 0 {
+				return err
+			}
+			abort(err)
+		}
+		if n == 0 {
+			start()
+		}
+		if err := iw.item(item); err != nil {
+			abort(err)
+		}
+		if n++; n%FlushEvery == 0 {
+			flush()
+		}
+	}
+	if n == 0 {
+		start()
+	}
+	if err := iw.end(); err != nil {
+		abort(err)
+	}
+	flush()
+	return nil
+}
+
+// jsonArrayWriter writes {"name":[item,item]}
+type jsonArrayWriter struct {
+	w     io.Writer
+	name  string
+	count int
+}
+
+func (j *jsonArrayWriter) begin() error {
+	key, err := json.Marshal(j.name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(j.w, "{"+string(key)+":[")
+	return err
+}
+
+func (j *jsonArrayWriter) item(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.count++
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonArrayWriter) end() error {
+	_, err := io.WriteString(j.w, "]}\n")
+	return err
+}
+
+// ndjsonWriter writes one item per line
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n ndjsonWriter) begin() error { return nil }
+
+func (n ndjsonWriter) item(v any) error { return n.enc.Encode(v) }
+
+func (n ndjsonWriter) end() error { return nil }
@@ -0,0 +1,124 @@
+package render_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	N int `json:"n"`
+}
+
+// items yields n items, then err when it is not nil
+func items(n int, err error) func(yield func(item, error) bool) {
+	return func(yield func(item, error) bool) {
+		for i := range n {
+			if !yield(item{N: i}, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(item{}, err)
+		}
+	}
+}
+
+func Test_Stream(t *testing.T) {
+	testcases := []struct {
+		name                string
+		encoder             render.Encoder
+		count               int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "json_array",
+			encoder:             render.JSON,
+			count:               3,
+			expectedContentType: "application/json",
+			expectedBody:        `{"items":[{"n":0},{"n":1},{"n":2}]}` + "\n",
+		},
+		{
+			name:                "empty_json_array",
+			encoder:             render.JSON,
+			expectedContentType: "application/json",
+			expectedBody:        `{"items":[]}` + "\n",
+		},
+		{
+			name:                "ndjson_lines",
+			encoder:             render.NDJSON,
+			count:               2,
+			expectedContentType: "application/x-ndjson",
+			expectedBody:        "{\"n\":0}\n{\"n\":1}\n",
+		},
+		{
+			name:                "empty_ndjson",
+			encoder:             render.NDJSON,
+			expectedContentType: "application/x-ndjson",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			err := render.Stream(tc.encoder, w, r, http.StatusOK, "items", items(tc.count, nil))
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func Test_StreamFlushesPeriodically(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	err := render.Stream(render.NDJSON, w, r, http.StatusOK, "items", items(2*render.FlushEvery+1, nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, w.flushes)
+	assert.Equal(t, 2*render.FlushEvery+1, strings.Count(w.Body.String(), "\n"))
+}
+
+func Test_StreamReturnsErrorBeforeWriting(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	queryErr := errors.New("db error")
+
+	err := render.Stream(render.JSON, w, r, http.StatusOK, "items", items(0, queryErr))
+
+	assert.ErrorIs(t, err, queryErr)
+	assert.False(t, w.Flushed)
+	assert.Empty(t, w.Body.String())
+	assert.Empty(t, w.Header().Get("Content-Type"))
+}
+
+func Test_StreamAbortsAfterStarting(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	assert.PanicsWithError(t, http.ErrAbortHandler.Error(), func() {
+		render.Stream(render.JSON, w, r, http.StatusOK, "items", items(1, errors.New("connection reset")))
+	})
+}
+
+// flushRecorder counts the flushes reaching the client
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushes++
+	f.ResponseRecorder.Flush()
+}
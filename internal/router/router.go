@@ -1,25 +1,307 @@
 package router
 
 import (
+	"expvar"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/feed"
 	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/middleware"
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+	"github.com/logeshwarann-dev/news-api-rest/internal/policy"
 )
 
-func New(ns handler.NewsStorer) *http.ServeMux {
+// V1 is the path prefix of the first version of the api
+const V1 = "/v1"
+
+// Option configures the router
+type Option func(*options)
+
+type options struct {
+	requireScopes bool
+	authorizer    handler.Authorizer
+	debugVars     bool
+	webhooks      handler.WebhookStorer
+	bus           *events.Bus
+	heartbeat     time.Duration
+	validator     *openapi.Validator
+	legacy        *middleware.Deprecation
+	versions      []version
+}
+
+// version is a later version of the api served under its prefix
+type version struct {
+	prefix string
+	routes []Route
+}
+
+// WithScopes enforces the scope declared by each route. Requests are
+// expected to go through middleware.Authenticate before reaching the router.
+func WithScopes() Option {
+	return func(o *options) {
+		o.requireScopes = true
+	}
+}
+
+// WithPolicy sets the authorizer consulted by handlers modifying existing news
+func WithPolicy(az handler.Authorizer) Option {
+	return func(o *options) {
+		o.authorizer = az
+	}
+}
+
+// WithDebugVars serves the expvar variables, such as the cache metrics, on
+// /debug/vars to callers with the admin scope
+func WithDebugVars() Option {
+	return func(o *options) {
+		o.debugVars = true
+	}
+}
+
+// WithWebhooks serves the webhook subscriptions of ws on /webhooks
+func WithWebhooks(ws handler.WebhookStorer) Option {
+	return func(o *options) {
+		o.webhooks = ws
+	}
+}
+
+// WithStream serves the news changes published on bus as server-sent events
+// on /news/stream, with a heartbeat sent every heartbeat
+func WithStream(bus *events.Bus, heartbeat time.Duration) Option {
+	return func(o *options) {
+		o.bus = bus
+		o.heartbeat = heartbeat
+	}
+}
+
+// WithValidator rejects the requests of the routes v describes when their
+// parameters or body do not match the description, after the scope check
+func WithValidator(v *openapi.Validator) Option {
+	return func(o *options) {
+		o.validator = v
+	}
+}
+
+// WithLegacyAliases also serves the routes of V1 on their unversioned paths,
+// such as /news, with headers announcing when they were deprecated and when
+// they will be retired
+func WithLegacyAliases(deprecation, sunset time.Time) Option {
+	return func(o *options) {
+		o.legacy = &middleware.Deprecation{At: deprecation, Sunset: sunset, Successor: V1}
+	}
+}
+
+// WithVersion also serves the api under prefix, such as "/v2", with the
+// routes of the previous version, V1 for the first one added, except those
+// replaced by routes of the same unprefixed pattern, such as
+// "GET /news/{news_id}"; routes with other patterns are added. A version
+// changing the response model of some routes thus only lists their handlers,
+// built on the same store.
+func WithVersion(prefix string, routes ...Route) Option {
+	return func(o *options) {
+		o.versions = append(o.versions, version{prefix: prefix, routes: routes})
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{authorizer: policy.AllowAll{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o options) scoped(scope auth.Scope, h http.Handler) http.Handler {
+	if !o.requireScopes || scope == "" {
+		return h
+	}
+	return middleware.RequireScope(scope, h)
+}
+
+// route is a handler registered under a method specific pattern along with
+// the scope it requires, none for public routes
+type route struct {
+	pattern string
+	scope   auth.Scope
+	handler http.Handler
+}
+
+// Route describes a route served by New
+type Route struct {
+	Pattern string
+	Scope   auth.Scope
+	// Handler serves the route, it is left out by Routes
+	Handler http.Handler
+}
+
+// Routes lists the routes New serves with the options, in registration
+// order, leaving out the legacy aliases and the OPTIONS routes answered for
+// every path
+func Routes(opts ...Option) []Route {
+	var routes []Route
+	for _, rt := range newOptions(opts).routes(nil) {
+		routes = append(routes, Route{Pattern: rt.pattern, Scope: rt.scope})
+	}
+	return routes
+}
+
+// routes returns the routes served with the options: those describing and
+// monitoring the api, then the api routes of each version under its prefix
+func (o options) routes(ns handler.NewsStorer) []route {
+	routes := []route{
+		//Description of the API
+		{"GET /openapi.json", "", handler.GetOpenAPI()},
+		{"GET /docs", "", handler.GetDocs()},
+	}
+	if o.debugVars {
+		//Runtime and cache metrics
+		routes = append(routes, route{"GET /debug/vars", auth.ScopeAdmin, expvar.Handler()})
+	}
+
+	api := o.v1(ns)
+	routes = append(routes, prefixed(V1, api)...)
+	for _, v := range o.versions {
+		api = replaced(api, v.routes)
+		routes = append(routes, prefixed(v.prefix, api)...)
+	}
+	return routes
+}
+
+// v1 returns the unprefixed api routes of V1
+func (o options) v1(ns handler.NewsStorer) []route {
+	routes := []route{
+		//Create News
+		{"POST /news", auth.ScopeNewsWrite, handler.PostNews(ns)},
+		//Get all News
+		{"GET /news", auth.ScopeNewsRead, handler.GetAllNews(ns)},
+	}
+
+	if o.bus != nil {
+		//Live stream of the News changes
+		routes = append(routes, route{"GET /news/stream", auth.ScopeNewsRead, handler.GetNewsStream(o.bus, o.heartbeat)})
+	}
+
+	routes = append(routes, []route{
+		//Get News By Id
+		{"GET /news/{news_id}", auth.ScopeNewsRead, handler.GetNewsByID(ns)},
+		//Update News By Id
+		{"PUT /news/{news_id}", auth.ScopeNewsWrite, handler.UpdateNewsByID(ns, o.authorizer)},
+		//Patch News By Id
+		{"PATCH /news/{news_id}", auth.ScopeNewsWrite, handler.PatchNewsByID(ns, o.authorizer)},
+		//Delete News By Id
+		{"DELETE /news/{news_id}", auth.ScopeNewsDelete, handler.DeleteNewsByID(ns, o.authorizer)},
+		//Feeds of the newest News
+		{"GET /feeds/rss.xml", auth.ScopeNewsRead, handler.GetFeed(ns, feed.RSS)},
+		{"GET /feeds/atom.xml", auth.ScopeNewsRead, handler.GetFeed(ns, feed.Atom)},
+		{"GET /feeds/feed.json", auth.ScopeNewsRead, handler.GetFeed(ns, feed.JSON)},
+		{"GET /feeds/tags/{tag}/rss.xml", auth.ScopeNewsRead, handler.GetFeed(ns, feed.RSS)},
+		{"GET /feeds/tags/{tag}/atom.xml", auth.ScopeNewsRead, handler.GetFeed(ns, feed.Atom)},
+		{"GET /feeds/tags/{tag}/feed.json", auth.ScopeNewsRead, handler.GetFeed(ns, feed.JSON)},
+		{"GET /feeds/authors/{author}/rss.xml", auth.ScopeNewsRead, handler.GetFeed(ns, feed.RSS)},
+		{"GET /feeds/authors/{author}/atom.xml", auth.ScopeNewsRead, handler.GetFeed(ns, feed.Atom)},
+		{"GET /feeds/authors/{author}/feed.json", auth.ScopeNewsRead, handler.GetFeed(ns, feed.JSON)},
+	}...)
+
+	if ws := o.webhooks; ws != nil {
+		routes = append(routes,
+			//Subscribe to news events
+			route{"POST /webhooks", auth.ScopeWebhooks, handler.PostWebhook(ws)},
+			//Get all Subscriptions
+			route{"GET /webhooks", auth.ScopeWebhooks, handler.GetWebhooks(ws)},
+			//Get Subscription By Id
+			route{"GET /webhooks/{webhook_id}", auth.ScopeWebhooks, handler.GetWebhookByID(ws)},
+			//Delete Subscription By Id
+			route{"DELETE /webhooks/{webhook_id}", auth.ScopeWebhooks, handler.DeleteWebhookByID(ws)},
+			//Delivery log of a Subscription
+			route{"GET /webhooks/{webhook_id}/deliveries", auth.ScopeWebhooks, handler.GetWebhookDeliveries(ws)},
+		)
+	}
+
+	return routes
+}
+
+// prefixed returns the routes with prefix inserted before their paths
+func prefixed(prefix string, routes []route) []route {
+	out := make([]route, 0, len(routes))
+	for _, rt := range routes {
+		method, path, _ := strings.Cut(rt.pattern, " ")
+		out = append(out, route{method + " " + prefix + path, rt.scope, rt.handler})
+	}
+	return out
+}
+
+// replaced returns the routes with those of changes replacing the ones of the
+// same pattern, the others being appended
+func replaced(routes []route, changes []Route) []route {
+	routes = slices.Clone(routes)
+	for _, c := range changes {
+		rt := route{c.Pattern, c.Scope, c.Handler}
+		if i := slices.IndexFunc(routes, func(r route) bool { return r.pattern == c.Pattern }); i >= 0 {
+			routes[i] = rt
+		} else {
+			routes = append(routes, rt)
+		}
+	}
+	return routes
+}
+
+func New(ns handler.NewsStorer, opts ...Option) *http.ServeMux {
+	o := newOptions(opts)
+
 	//Setup new server mux
 	r := http.NewServeMux()
 
-	//Create News
-	r.HandleFunc("POST /news", handler.PostNews(ns))
-	//Get all News
-	r.HandleFunc("GET /news", handler.GetAllNews(ns))
-	//Get News By Id
-	r.HandleFunc("GET /news/{news_id}", handler.GetNewsByID(ns))
-	//Update News By Id
-	r.HandleFunc("PUT /news/{news_id}", handler.UpdateNewsByID(ns))
-	//Delete News By Id
-	r.HandleFunc("DELETE /news/{news_id}", handler.DeleteNewsByID(ns))
+	var paths []string
+	methods := map[string][]string{}
+	handle := func(pattern string, h http.Handler) {
+		r.Handle(pattern, h)
+		method, path, _ := strings.Cut(pattern, " ")
+		if _, ok := methods[path]; !ok {
+			paths = append(paths, path)
+		}
+		methods[path] = append(methods[path], method)
+	}
+	for _, rt := range o.routes(ns) {
+		h := rt.handler
+		if o.validator != nil {
+			if op, ok := o.validator.Operation(rt.pattern); ok {
+				h = middleware.ValidateRequest(op, h)
+			}
+		}
+		h = o.scoped(rt.scope, h)
+		handle(rt.pattern, h)
+		//Legacy unversioned alias of a V1 route
+		if method, path, _ := strings.Cut(rt.pattern, " "); o.legacy != nil && strings.HasPrefix(path, V1+"/") {
+			handle(method+" "+strings.TrimPrefix(path, V1), middleware.Deprecate(*o.legacy, h))
+		}
+	}
+	//Answer OPTIONS, including CORS preflights, for every path
+	for _, path := range paths {
+		r.Handle("OPTIONS "+path, allow(methods[path]))
+	}
 
 	return r
 }
+
+// allow answers OPTIONS requests with the methods registered for a path
+func allow(methods []string) http.HandlerFunc {
+	methods = slices.Clone(methods)
+	if slices.Contains(methods, http.MethodGet) {
+		methods = append(methods, http.MethodHead)
+	}
+	methods = append(methods, http.MethodOptions)
+	allowHeader := strings.Join(methods, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allowHeader)
+		if m := r.Header.Get("Access-Control-Request-Method"); m != "" && !slices.Contains(methods, m) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,515 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/auth"
+	"github.com/logeshwarann-dev/news-api-rest/internal/events"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	mockshandler "github.com/logeshwarann-dev/news-api-rest/internal/handler/mocks"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/openapi"
+	"github.com/logeshwarann-dev/news-api-rest/internal/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewWithScopes(t *testing.T) {
+	testcases := []struct {
+		name           string
+		method         string
+		path           string
+		scopes         []auth.Scope
+		setup          func(mh *mockshandler.MockNewsStorer)
+		expectedStatus int
+	}{
+		{
+			name:           "reject_anonymous_read",
+			method:         http.MethodGet,
+			path:           "/v1/news",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "allow_read_scope",
+			method: http.MethodGet,
+			path:   "/v1/news",
+			scopes: []auth.Scope{auth.ScopeNewsRead},
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().Stats(gomock.Any(), gomock.Any()).Return(news.Stats{}, nil)
+				mh.EXPECT().Stream(gomock.Any(), gomock.Any()).Return(func(func(news.Record, error) bool) {})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "allow_anonymous_openapi",
+			method:         http.MethodGet,
+			path:           "/openapi.json",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "allow_anonymous_docs",
+			method:         http.MethodGet,
+			path:           "/docs",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "reject_delete_with_write_scope",
+			method:         http.MethodDelete,
+			path:           "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			scopes:         []auth.Scope{auth.ScopeNewsRead, auth.ScopeNewsWrite},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "allow_delete_for_admin",
+			method: http.MethodDelete,
+			path:   "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			scopes: []auth.Scope{auth.ScopeAdmin},
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+				mh.EXPECT().DeleteById(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			if tc.setup != nil {
+				tc.setup(mh)
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.scopes != nil {
+				r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1", Scopes: tc.scopes}))
+			}
+
+			router.New(mh, router.WithScopes()).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func Test_NewAnswersOptions(t *testing.T) {
+	testcases := []struct {
+		name           string
+		path           string
+		requestMethod  string
+		expectedStatus int
+		expectedAllow  string
+	}{
+		{
+			name:           "list_methods_for_collection",
+			path:           "/v1/news",
+			expectedStatus: http.StatusNoContent,
+			expectedAllow:  "POST, GET, HEAD, OPTIONS",
+		},
+		{
+			name:           "list_methods_for_item",
+			path:           "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			expectedStatus: http.StatusNoContent,
+			expectedAllow:  "GET, PUT, PATCH, DELETE, HEAD, OPTIONS",
+		},
+		{
+			name:           "accept_preflight_for_registered_method",
+			path:           "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			requestMethod:  http.MethodDelete,
+			expectedStatus: http.StatusNoContent,
+			expectedAllow:  "GET, PUT, PATCH, DELETE, HEAD, OPTIONS",
+		},
+		{
+			name:           "reject_preflight_for_unregistered_method",
+			path:           "/v1/news",
+			requestMethod:  http.MethodDelete,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedAllow:  "POST, GET, HEAD, OPTIONS",
+		},
+		{
+			name:           "unknown_path",
+			path:           "/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodOptions, tc.path, nil)
+			if tc.requestMethod != "" {
+				r.Header.Set("Access-Control-Request-Method", tc.requestMethod)
+			}
+
+			router.New(mockshandler.NewMockNewsStorer(gomock.NewController(t)), router.WithScopes()).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			assert.Equal(t, tc.expectedAllow, w.Header().Get("Allow"))
+		})
+	}
+}
+
+func Test_NewWithWebhooks(t *testing.T) {
+	testcases := []struct {
+		name           string
+		scopes         []auth.Scope
+		webhooks       bool
+		expectedStatus int
+	}{
+		{
+			name:           "not_served_by_default",
+			scopes:         []auth.Scope{auth.ScopeAdmin},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "reject_news_scopes",
+			scopes:         []auth.Scope{auth.ScopeNewsRead, auth.ScopeNewsWrite, auth.ScopeNewsDelete},
+			webhooks:       true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "allow_webhooks_scope",
+			scopes:         []auth.Scope{auth.ScopeWebhooks},
+			webhooks:       true,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			opts := []router.Option{router.WithScopes()}
+			if tc.webhooks {
+				mw := mockshandler.NewMockWebhookStorer(ctrl)
+				mw.EXPECT().Subscriptions(gomock.Any(), "key-1").Return(nil, nil).MaxTimes(1)
+				opts = append(opts, router.WithWebhooks(mw))
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/v1/webhooks", nil)
+			r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1", Scopes: tc.scopes}))
+
+			router.New(mockshandler.NewMockNewsStorer(ctrl), opts...).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func Test_NewWithStream(t *testing.T) {
+	testcases := []struct {
+		name           string
+		scopes         []auth.Scope
+		stream         bool
+		expectedStatus int
+	}{
+		{
+			name:           "not_served_by_default",
+			scopes:         []auth.Scope{auth.ScopeNewsRead},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "reject_other_scopes",
+			scopes:         []auth.Scope{auth.ScopeWebhooks},
+			stream:         true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "allow_news_read_scope",
+			scopes:         []auth.Scope{auth.ScopeNewsRead},
+			stream:         true,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			opts := []router.Option{router.WithScopes()}
+			if tc.stream {
+				// a closed bus answers at once instead of streaming
+				bus := events.NewBus(0)
+				bus.Close()
+				opts = append(opts, router.WithStream(bus, time.Second))
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/v1/news/stream", nil)
+			r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1", Scopes: tc.scopes}))
+
+			router.New(mockshandler.NewMockNewsStorer(ctrl), opts...).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func Test_RoutesDocumented(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(openapi.Spec(), &spec))
+	routes := router.Routes(
+		router.WithScopes(),
+		router.WithWebhooks(mockshandler.NewMockWebhookStorer(gomock.NewController(t))),
+		router.WithStream(events.NewBus(0), time.Second),
+		router.WithDebugVars(),
+	)
+
+	served := map[string]bool{}
+	for _, rt := range routes {
+		method, path, _ := strings.Cut(rt.Pattern, " ")
+		served[strings.ToLower(method)+" "+path] = true
+		raw, ok := spec.Paths[path][strings.ToLower(method)]
+		if !assert.True(t, ok, "%s is not described in the openapi document", rt.Pattern) {
+			continue
+		}
+		var operation struct {
+			Security []map[string][]auth.Scope `json:"security"`
+		}
+		require.NoError(t, json.Unmarshal(raw, &operation))
+		expected := []map[string][]auth.Scope{}
+		if rt.Scope != "" {
+			expected = append(expected, map[string][]auth.Scope{"bearerAuth": {rt.Scope}})
+		}
+		assert.Equal(t, expected, operation.Security, "security of %s", rt.Pattern)
+	}
+
+	for path, item := range spec.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			assert.True(t, served[method+" "+path], "%s %s is described but not served", method, path)
+		}
+	}
+}
+
+func Test_NewWithValidator(t *testing.T) {
+	testcases := []struct {
+		name           string
+		scopes         []auth.Scope
+		body           string
+		setup          func(mh *mockshandler.MockNewsStorer)
+		expectedStatus int
+	}{
+		{
+			name:           "check_scope_first",
+			scopes:         []auth.Scope{auth.ScopeNewsRead},
+			body:           `{"foo": 1}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "reject_unknown_field",
+			scopes:         []auth.Scope{auth.ScopeNewsWrite},
+			body:           `{"author": "Batman", "title": "Gotham", "summary": "Night", "content": "Long night", "source": "https://gotham.example.com", "created_at": "2026-10-19T13:00:00Z", "tags": ["dc"], "foo": 1}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "pass_valid_news",
+			scopes: []auth.Scope{auth.ScopeNewsWrite},
+			body:   `{"author": "Batman", "title": "Gotham", "summary": "Night", "content": "Long night", "source": "https://gotham.example.com", "created_at": "2026-10-19T13:00:00Z", "tags": ["dc"]}`,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().Create(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	v, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			if tc.setup != nil {
+				tc.setup(mh)
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/v1/news", strings.NewReader(tc.body))
+			r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1", Scopes: tc.scopes}))
+
+			router.New(mh, router.WithScopes(), router.WithValidator(v)).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func Test_NewWithLegacyAliases(t *testing.T) {
+	testcases := []struct {
+		name                string
+		method              string
+		path                string
+		legacy              bool
+		setup               func(mh *mockshandler.MockNewsStorer)
+		expectedStatus      int
+		expectedDeprecation string
+		expectedLink        string
+	}{
+		{
+			name:           "not_served_by_default",
+			method:         http.MethodGet,
+			path:           "/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "serve_v1_without_deprecation",
+			method: http.MethodGet,
+			path:   "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			legacy: true,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "serve_alias_with_deprecation",
+			method: http.MethodGet,
+			path:   "/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			legacy: true,
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedDeprecation: "@1792368000",
+			expectedLink:        `</v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a>; rel="successor-version"`,
+		},
+		{
+			name:                "check_scope_of_alias",
+			method:              http.MethodDelete,
+			path:                "/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			legacy:              true,
+			expectedStatus:      http.StatusForbidden,
+			expectedDeprecation: "@1792368000",
+			expectedLink:        `</v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a>; rel="successor-version"`,
+		},
+		{
+			name:           "keep_docs_unversioned",
+			method:         http.MethodGet,
+			path:           "/openapi.json",
+			legacy:         true,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			if tc.setup != nil {
+				tc.setup(mh)
+			}
+			opts := []router.Option{router.WithScopes()}
+			if tc.legacy {
+				opts = append(opts, router.WithLegacyAliases(
+					time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+					time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+				))
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1", Scopes: []auth.Scope{auth.ScopeNewsRead}}))
+
+			router.New(mh, opts...).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			assert.Equal(t, tc.expectedDeprecation, w.Header().Get("Deprecation"))
+			assert.Equal(t, tc.expectedLink, w.Header().Get("Link"))
+			if tc.expectedDeprecation != "" {
+				assert.Equal(t, "Mon, 19 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			}
+		})
+	}
+}
+
+func Test_NewWithVersion(t *testing.T) {
+	// v2 renames the fields of a single news and adds a route, inheriting the others
+	getNewsByID := func(ns handler.NewsStorer) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			record, err := ns.FindById(r.Context(), uuid.MustParse(r.PathValue("news_id")))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"headline": record.Title})
+		}
+	}
+	ping := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	testcases := []struct {
+		name           string
+		method         string
+		path           string
+		setup          func(mh *mockshandler.MockNewsStorer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "serve_replaced_route",
+			method: http.MethodGet,
+			path:   "/v2/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{Title: "Gotham"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"headline":"Gotham"}`,
+		},
+		{
+			name:   "keep_v1_route",
+			method: http.MethodGet,
+			path:   "/v1/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{Title: "Gotham"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"title":"Gotham"`,
+		},
+		{
+			name:   "inherit_other_routes",
+			method: http.MethodDelete,
+			path:   "/v2/news/c2f92052-348f-4372-b4bc-43dbbc88445a",
+			setup: func(mh *mockshandler.MockNewsStorer) {
+				mh.EXPECT().FindById(gomock.Any(), gomock.Any()).Return(news.Record{}, nil)
+				mh.EXPECT().DeleteById(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "serve_added_route",
+			method:         http.MethodGet,
+			path:           "/v2/ping",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "add_route_to_v2_only",
+			method:         http.MethodGet,
+			path:           "/v1/ping",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mh := mockshandler.NewMockNewsStorer(gomock.NewController(t))
+			if tc.setup != nil {
+				tc.setup(mh)
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			r = r.WithContext(auth.CtxWithPrincipal(r.Context(), auth.Principal{Subject: "key-1", Scopes: []auth.Scope{auth.ScopeAdmin}}))
+
+			router.New(mh, router.WithScopes(), router.WithVersion("/v2",
+				router.Route{Pattern: "GET /news/{news_id}", Scope: auth.ScopeNewsRead, Handler: getNewsByID(mh)},
+				router.Route{Pattern: "GET /ping", Scope: auth.ScopeNewsRead, Handler: ping},
+			)).ServeHTTP(w, r)
+
+			assert.Equal(t, tc.expectedStatus, w.Result().StatusCode)
+			assert.Contains(t, w.Body.String(), tc.expectedBody)
+		})
+	}
+}
@@ -1,71 +1,278 @@
+// Package store provides an in-memory news store with the behaviour of the
+// postgres store, for running the api without a database.
 package store
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
 )
 
+// Store keeps news records in memory. Deleted records are kept with their
+// DeletedAt set and hidden from every read, as the postgres store does.
 type Store struct {
-	m     sync.Mutex
-	store []News
+	m       sync.RWMutex
+	records []news.Record
+	now     func() time.Time
 }
 
-func New() *Store {
+var _ handler.NewsStorer = (*Store)(nil)
+
+type options struct {
+	now func() time.Time
+}
+
+// Option configures the Store
+type Option func(*options)
+
+// WithClock replaces time.Now for the creation, update and deletion times
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+func New(opts ...Option) *Store {
+	o := options{now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return &Store{
-		m:     sync.Mutex{},
-		store: []News{},
+		now: func() time.Time {
+			// postgres keeps microseconds
+			return o.now().UTC().Truncate(time.Microsecond)
+		},
 	}
 }
-func (s *Store) Create(newsRecord News) (News, error) {
+
+// create news
+func (s *Store) Create(ctx context.Context, record news.Record) (news.Record, error) {
+	if err := ctx.Err(); err != nil {
+		return news.Record{}, news.NewCustomError(err, http.StatusInternalServerError)
+	}
+	if err := notNull(record); err != nil {
+		return news.Record{}, news.NewCustomError(err, http.StatusInternalServerError)
+	}
 	s.m.Lock()
 	defer s.m.Unlock()
-	id := uuid.New()
-	newsRecord.Id = id
-	s.store = append(s.store, newsRecord)
-	return newsRecord, nil
+	now := s.now()
+	record.Id = uuid.New()
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = now
+	}
+	if record.UpdatedAt.IsZero() {
+		record.UpdatedAt = now
+	}
+	record.Tags = slices.Clone(record.Tags)
+	s.records = append(s.records, record)
+	return clone(record), nil
+}
+
+// get all news matching the filter
+func (s *Store) FindAll(ctx context.Context, f news.Filter) ([]news.Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, news.NewCustomError(err, http.StatusInternalServerError)
+	}
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return page(f, s.match(f)), nil
+}
+
+// stream the news matching the filter, read from a snapshot taken on the first iteration
+func (s *Store) Stream(ctx context.Context, f news.Filter) news.Records {
+	return func(yield func(news.Record, error) bool) {
+		records, err := s.FindAll(ctx, f)
+		if err != nil {
+			yield(news.Record{}, err)
+			return
+		}
+		for _, record := range records {
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// count the live news matching the filter and find the latest write among
+// all the news, as the postgres store does
+func (s *Store) Stats(ctx context.Context, f news.Filter) (stats news.Stats, err error) {
+	if err := ctx.Err(); err != nil {
+		return stats, news.NewCustomError(err, http.StatusInternalServerError)
+	}
+	s.m.RLock()
+	defer s.m.RUnlock()
+	for _, record := range s.records {
+		if record.DeletedAt.IsZero() && matches(record, f) {
+			stats.Count++
+		}
+		changed := record.UpdatedAt
+		if record.DeletedAt.After(changed) {
+			changed = record.DeletedAt
+		}
+		if changed.After(stats.LastModified) {
+			stats.LastModified = changed
+		}
+	}
+	return stats, nil
+}
+
+// get news by id
+func (s *Store) FindById(ctx context.Context, id uuid.UUID) (news.Record, error) {
+	if err := ctx.Err(); err != nil {
+		return news.Record{}, news.NewCustomError(err, http.StatusInternalServerError)
+	}
+	s.m.RLock()
+	defer s.m.RUnlock()
+	i := s.index(id)
+	if i < 0 {
+		return news.Record{}, news.NewCustomError(sql.ErrNoRows, http.StatusNotFound)
+	}
+	return clone(s.records[i]), nil
+}
+
+// update news by id, replacing every field but the id and deletion time
+func (s *Store) UpdateById(ctx context.Context, id uuid.UUID, record news.Record) error {
+	return s.update(ctx, id, record, nil)
+}
+
+// update news by id only while its update time is still updatedAt
+func (s *Store) UpdateByIdIfUnchanged(ctx context.Context, id uuid.UUID, updatedAt time.Time, record news.Record) error {
+	return s.update(ctx, id, record, &updatedAt)
 }
 
-func (s *Store) FindAll() ([]News, error) {
+func (s *Store) update(ctx context.Context, id uuid.UUID, record news.Record, updatedAt *time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return news.NewCustomError(err, http.StatusInternalServerError)
+	}
+	if err := notNull(record); err != nil {
+		return news.NewCustomError(err, http.StatusInternalServerError)
+	}
 	s.m.Lock()
 	defer s.m.Unlock()
-	return s.store, nil
+	i := s.index(id)
+	if i < 0 {
+		return news.NewCustomError(errors.New("record not found"), http.StatusNotFound)
+	}
+	if updatedAt != nil && !s.records[i].UpdatedAt.Equal(*updatedAt) {
+		return news.NewCustomError(news.ErrChanged, http.StatusPreconditionFailed)
+	}
+	record.Id = id
+	record.Owner = s.records[i].Owner
+	record.Tags = slices.Clone(record.Tags)
+	record.UpdatedAt = s.now()
+	record.DeletedAt = time.Time{}
+	s.records[i] = record
+	return nil
 }
 
-func (s *Store) FindById(id uuid.UUID) (News, error) {
+// delete news by id, deleting a missing record is not an error
+func (s *Store) DeleteById(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return news.NewCustomError(err, http.StatusInternalServerError)
+	}
 	s.m.Lock()
 	defer s.m.Unlock()
-	for _, news := range s.store {
-		if news.Id == id {
-			return news, nil
+	if i := s.index(id); i >= 0 {
+		s.records[i].DeletedAt = s.now()
+	}
+	return nil
+}
+
+// index returns the position of the live record with the id, or -1
+func (s *Store) index(id uuid.UUID) int {
+	return slices.IndexFunc(s.records, func(r news.Record) bool {
+		return r.Id == id && r.DeletedAt.IsZero()
+	})
+}
+
+// match returns copies of the live records matching the conditions of the filter
+func (s *Store) match(f news.Filter) []news.Record {
+	var matched []news.Record
+	for _, record := range s.records {
+		if record.DeletedAt.IsZero() && matches(record, f) {
+			matched = append(matched, clone(record))
 		}
 	}
-	return News{}, errors.New("record not found")
+	return matched
 }
 
-func (s *Store) UpdateById(id uuid.UUID, newsRecord News) (News, error) {
-	s.m.Lock()
-	defer s.m.Unlock()
-	for index, news := range s.store {
-		if news.Id == id {
-			newsRecord.Id = id
-			s.store = append(s.store[:index], s.store[index+1:]...)
-			s.store = append(s.store, newsRecord)
-			return newsRecord, nil
+// matches reports whether the record meets the conditions of the filter,
+// whether it is deleted or not
+func matches(record news.Record, f news.Filter) bool {
+	if f.Tag != "" && !slices.Contains(record.Tags, f.Tag) {
+		return false
+	}
+	if f.Author != "" && record.Author != f.Author {
+		return false
+	}
+	return f.Query == "" || containsFold(f.Query, record.Title, record.Summary, record.Content)
+}
+
+// containsFold reports whether any of the fields contains query, ignoring case
+func containsFold(query string, fields ...string) bool {
+	query = strings.ToLower(query)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
 		}
 	}
-	return News{}, errors.New("update failed, record not found")
+	return false
 }
 
-func (s *Store) DeleteById(id uuid.UUID) error {
-	s.m.Lock()
-	defer s.m.Unlock()
-	for index, news := range s.store {
-		if news.Id == id {
-			s.store = append(s.store[:index], s.store[index+1:]...)
-			return nil
+// page sorts the records by creation time then id, as postgres orders uuids,
+// and applies the limit and offset of the filter
+func page(f news.Filter, records []news.Record) []news.Record {
+	slices.SortFunc(records, func(a, b news.Record) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
 		}
+		return bytes.Compare(a.Id[:], b.Id[:])
+	})
+	if f.Order == news.NewestFirst {
+		slices.Reverse(records)
+	}
+	records = records[min(f.Offset, len(records)):]
+	if f.Limit > 0 {
+		records = records[:min(f.Limit, len(records))]
 	}
-	return errors.New("delete failed, record not found")
+	return records
+}
+
+// notNull mirrors the not null constraints of the news table
+func notNull(record news.Record) error {
+	columns := []struct {
+		name  string
+		empty bool
+	}{
+		{"author", record.Author == ""},
+		{"title", record.Title == ""},
+		{"summary", record.Summary == ""},
+		{"content", record.Content == ""},
+		{"source", record.Source == ""},
+		{"tags", record.Tags == nil},
+	}
+	for _, column := range columns {
+		if column.empty {
+			return fmt.Errorf("null value in column %q violates not-null constraint", column.name)
+		}
+	}
+	return nil
+}
+
+// clone copies a record so callers cannot change the stored tags
+func clone(record news.Record) news.Record {
+	record.Tags = slices.Clone(record.Tags)
+	return record
 }
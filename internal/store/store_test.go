@@ -0,0 +1,49 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/store"
+	"github.com/logeshwarann-dev/news-api-rest/internal/storetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) handler.NewsStorer {
+		return store.New()
+	})
+}
+
+func TestStore_ReturnCopies(t *testing.T) {
+	s := store.New()
+	created, err := s.Create(context.Background(), storetest.Record("Batman", time.Time{}, "marvel"))
+	require.NoError(t, err)
+
+	got, err := s.FindById(context.Background(), created.Id)
+	require.NoError(t, err)
+	got.Tags[0] = "changed"
+
+	again, err := s.FindById(context.Background(), created.Id)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"marvel"}, again.Tags)
+}
+
+func TestStore_WithClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 999, time.UTC)
+	s := store.New(store.WithClock(func() time.Time { return now }))
+
+	created, err := s.Create(context.Background(), storetest.Record("Batman", time.Time{}, "marvel"))
+	require.NoError(t, err)
+	assert.Equal(t, now.Truncate(time.Microsecond), created.CreatedAt, "timestamps are kept to the microsecond")
+
+	now = now.Add(time.Hour)
+	require.NoError(t, s.UpdateById(context.Background(), created.Id, created))
+	stats, err := s.Stats(context.Background(), news.Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, now.Truncate(time.Microsecond), stats.LastModified)
+}
@@ -0,0 +1,684 @@
+// Package storetest provides a conformance suite for handler.NewsStorer
+// implementations, so every backend is held to the same behaviour.
+package storetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/handler"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty store. It is called once per test, which may
+// register cleanups on t.
+type Factory func(t *testing.T) handler.NewsStorer
+
+// Run runs every test of the suite against stores created by newStore
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(*testing.T, Factory)
+	}{
+		{"Create", testCreate},
+		{"FindById", testFindById},
+		{"FindAll", testFindAll},
+		{"FindAllOrdersTiesById", testFindAllOrdersTiesById},
+		{"Stream", testStream},
+		{"Stats", testStats},
+		{"UpdateById", testUpdateById},
+		{"UpdateByIdIfUnchanged", testUpdateByIdIfUnchanged},
+		{"DeleteById", testDeleteById},
+		{"Concurrency", testConcurrency},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newStore)
+		})
+	}
+}
+
+// epoch is the creation time of the first seeded record, far enough in the
+// past for updates to be later and truncated to the precision of postgres
+var epoch = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// Record returns a valid record by author
+func Record(author string, createdAt time.Time, tags ...string) news.Record {
+	return news.Record{
+		Author:    author,
+		Title:     "Breaking NEWS",
+		Summary:   "A brief summary of news",
+		Content:   author + " is a hero with super powers who saves people from devil",
+		Source:    "https://www.google.com",
+		Tags:      tags,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+// seed creates Batman, Superman and Spiderman an hour apart and deletes Spiderman
+func seed(tb testing.TB, s handler.NewsStorer) map[string]news.Record {
+	tb.Helper()
+	created := map[string]news.Record{}
+	for i, r := range []news.Record{
+		Record("Batman", epoch, "marvel", "sci-fi", "test-1"),
+		Record("Superman", epoch.Add(time.Hour), "marvel", "sci-fi"),
+		Record("Spiderman", epoch.Add(2*time.Hour), "marvel", "sci-fi"),
+	} {
+		got, err := s.Create(context.Background(), r)
+		require.NoError(tb, err, "seed record %d", i)
+		created[r.Author] = got
+	}
+	require.NoError(tb, s.DeleteById(context.Background(), created["Spiderman"].Id))
+	return created
+}
+
+func authors(records []news.Record) (authors []string) {
+	for _, r := range records {
+		authors = append(authors, r.Author)
+	}
+	return authors
+}
+
+// notNull matches the not null violations of every backend
+const notNull = `(?i)not[- ]null`
+
+// assertErr checks the message of err against a regular expression
+func assertErr(tb testing.TB, expected string, err error) {
+	tb.Helper()
+	if assert.Error(tb, err) {
+		assert.Regexp(tb, expected, err.Error())
+	}
+}
+
+func assertStatus(tb testing.TB, expectedStatus int, err error) {
+	tb.Helper()
+	var storeErr *news.CustomError
+	if assert.ErrorAs(tb, err, &storeErr) {
+		assert.Equal(tb, expectedStatus, storeErr.GetHttpStatus())
+	}
+}
+
+func assertTime(tb testing.TB, expected, got time.Time, field string) {
+	tb.Helper()
+	assert.True(tb, expected.Equal(got), "%s: expected %s, got %s", field, expected, got)
+}
+
+// assertRecord compares the stored fields of two records
+func assertRecord(tb testing.TB, expected, got news.Record) {
+	tb.Helper()
+	assert.Equal(tb, expected.Id, got.Id)
+	assert.Equal(tb, expected.Author, got.Author)
+	assert.Equal(tb, expected.Title, got.Title)
+	assert.Equal(tb, expected.Summary, got.Summary)
+	assert.Equal(tb, expected.Content, got.Content)
+	assert.Equal(tb, expected.Source, got.Source)
+	assert.Equal(tb, expected.Tags, got.Tags)
+	assert.Equal(tb, expected.Owner, got.Owner)
+	assert.Equal(tb, expected.Editor, got.Editor)
+	assertTime(tb, expected.CreatedAt, got.CreatedAt, "created_at")
+	assertTime(tb, expected.UpdatedAt, got.UpdatedAt, "updated_at")
+	assert.True(tb, got.DeletedAt.IsZero(), "deleted_at is not set")
+}
+
+func testCreate(t *testing.T, newStore Factory) {
+	testcases := []struct {
+		name           string
+		record         news.Record
+		expectedErr    string
+		expectedStatus int
+	}{
+		{
+			name:   "create_news_success",
+			record: Record("Batman", epoch, "marvel", "test-1"),
+		},
+		{
+			name:   "ignore_given_id",
+			record: func() news.Record { r := Record("Batman", epoch, "marvel"); r.Id = uuid.New(); return r }(),
+		},
+		{
+			name:   "keep_editor",
+			record: func() news.Record { r := Record("Batman", epoch, "marvel"); r.Editor = "Alfred"; return r }(),
+		},
+		{
+			name:   "keep_owner",
+			record: func() news.Record { r := Record("Batman", epoch, "marvel"); r.Owner = "bruce"; return r }(),
+		},
+		{
+			name:           "missing_author",
+			record:         Record("", epoch, "marvel"),
+			expectedErr:    notNull,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "missing_tags",
+			record:         Record("Batman", epoch),
+			expectedErr:    notNull,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newStore(t)
+			got, err := s.Create(context.Background(), tc.record)
+			if tc.expectedErr != "" {
+				assertErr(t, tc.expectedErr, err)
+				assertStatus(t, tc.expectedStatus, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEqual(t, uuid.Nil, got.Id)
+			assert.NotEqual(t, tc.record.Id, got.Id)
+			expected := tc.record
+			expected.Id = got.Id
+			assertRecord(t, expected, got)
+
+			found, err := s.FindById(context.Background(), got.Id)
+			require.NoError(t, err)
+			assertRecord(t, expected, found)
+		})
+	}
+
+	t.Run("default_timestamps_to_now", func(t *testing.T) {
+		s := newStore(t)
+		got, err := s.Create(context.Background(), Record("Batman", time.Time{}, "marvel"))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), got.CreatedAt, time.Minute)
+		assert.WithinDuration(t, time.Now(), got.UpdatedAt, time.Minute)
+	})
+}
+
+func testFindById(t *testing.T, newStore Factory) {
+	s := newStore(t)
+	created := seed(t, s)
+	testcases := []struct {
+		name           string
+		newsId         uuid.UUID
+		expectedRecord news.Record
+		expectedStatus int
+	}{
+		{
+			name:           "return_valid_news_record",
+			newsId:         created["Batman"].Id,
+			expectedRecord: created["Batman"],
+		},
+		{
+			name:           "return_not_found_error",
+			newsId:         uuid.New(),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "hide_deleted_record",
+			newsId:         created["Spiderman"].Id,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.FindById(context.Background(), tc.newsId)
+			if tc.expectedStatus != 0 {
+				assertStatus(t, tc.expectedStatus, err)
+				return
+			}
+			require.NoError(t, err)
+			assertRecord(t, tc.expectedRecord, got)
+		})
+	}
+}
+
+func testFindAll(t *testing.T, newStore Factory) {
+	s := newStore(t)
+	seed(t, s)
+	testcases := []struct {
+		name            string
+		filter          news.Filter
+		expectedAuthors []string
+	}{
+		{
+			name:            "return_all_records_oldest_first",
+			expectedAuthors: []string{"Batman", "Superman"},
+		},
+		{
+			name:            "return_all_records_newest_first",
+			filter:          news.Filter{Order: news.NewestFirst},
+			expectedAuthors: []string{"Superman", "Batman"},
+		},
+		{
+			name:            "filter_by_author",
+			filter:          news.Filter{Author: "Superman"},
+			expectedAuthors: []string{"Superman"},
+		},
+		{
+			name:            "filter_by_tag",
+			filter:          news.Filter{Tag: "test-1"},
+			expectedAuthors: []string{"Batman"},
+		},
+		{
+			name:            "search_ignoring_case",
+			filter:          news.Filter{Query: "SUPERMAN"},
+			expectedAuthors: []string{"Superman"},
+		},
+		{
+			name:            "search_title_summary_and_content",
+			filter:          news.Filter{Query: "super powers", Order: news.NewestFirst},
+			expectedAuthors: []string{"Superman", "Batman"},
+		},
+		{
+			name:   "search_wildcards_literally",
+			filter: news.Filter{Query: "%_"},
+		},
+		{
+			name:   "filter_by_unknown_tag",
+			filter: news.Filter{Tag: "dc"},
+		},
+		{
+			name:            "page_newest_first",
+			filter:          news.Filter{Tag: "sci-fi", Order: news.NewestFirst, Limit: 1},
+			expectedAuthors: []string{"Superman"},
+		},
+		{
+			name:            "page_with_offset",
+			filter:          news.Filter{Tag: "sci-fi", Offset: 1},
+			expectedAuthors: []string{"Superman"},
+		},
+		{
+			name:   "page_past_the_end",
+			filter: news.Filter{Offset: 2},
+		},
+		{
+			name:   "exclude_deleted",
+			filter: news.Filter{Author: "Spiderman"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.FindAll(context.Background(), tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAuthors, authors(got))
+		})
+	}
+}
+
+func testFindAllOrdersTiesById(t *testing.T, newStore Factory) {
+	s := newStore(t)
+	var ids []uuid.UUID
+	for _, author := range []string{"Batman", "Superman", "Wonder Woman"} {
+		got, err := s.Create(context.Background(), Record(author, epoch, "marvel"))
+		require.NoError(t, err)
+		ids = append(ids, got.Id)
+	}
+	// uuids compare byte by byte, as their canonical strings do
+	sortedIds := func(order news.Order) (sorted []string) {
+		for _, id := range ids {
+			sorted = append(sorted, id.String())
+		}
+		slices.Sort(sorted)
+		if order == news.NewestFirst {
+			slices.Reverse(sorted)
+		}
+		return sorted
+	}
+
+	for _, order := range []news.Order{news.OldestFirst, news.NewestFirst} {
+		t.Run(fmt.Sprintf("order_%d", order), func(t *testing.T) {
+			got, err := s.FindAll(context.Background(), news.Filter{Order: order})
+			require.NoError(t, err)
+			var gotIds []string
+			for _, r := range got {
+				gotIds = append(gotIds, r.Id.String())
+			}
+			assert.Equal(t, sortedIds(order), gotIds)
+		})
+	}
+}
+
+func testStream(t *testing.T, newStore Factory) {
+	s := newStore(t)
+	seed(t, s)
+
+	t.Run("yield_the_records_of_find_all", func(t *testing.T) {
+		for _, filter := range []news.Filter{
+			{},
+			{Tag: "sci-fi", Order: news.NewestFirst},
+			{Author: "Spiderman"},
+			{Limit: 1, Offset: 1},
+		} {
+			expected, err := s.FindAll(context.Background(), filter)
+			require.NoError(t, err)
+			var got []news.Record
+			for record, err := range s.Stream(context.Background(), filter) {
+				require.NoError(t, err)
+				got = append(got, record)
+			}
+			assert.Equal(t, authors(expected), authors(got), "filter %+v", filter)
+		}
+	})
+
+	t.Run("stop_early", func(t *testing.T) {
+		count := 0
+		for _, err := range s.Stream(context.Background(), news.Filter{}) {
+			assert.NoError(t, err)
+			count++
+			break
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	// a consumer writing the records to a slow client must not hold up the
+	// other requests
+	t.Run("serve_other_queries_while_consumed", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for record, err := range s.Stream(context.Background(), news.Filter{}) {
+			require.NoError(t, err)
+			_, err = s.FindById(ctx, record.Id)
+			require.NoError(t, err)
+			_, err = s.Stats(ctx, news.Filter{})
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("return_error_on_cancelled_context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		count := 0
+		for _, err := range s.Stream(ctx, news.Filter{}) {
+			assertStatus(t, http.StatusInternalServerError, err)
+			count++
+		}
+		assert.Equal(t, 1, count)
+	})
+}
+
+func testStats(t *testing.T, newStore Factory) {
+	s := newStore(t)
+	created := seed(t, s)
+	testcases := []struct {
+		name          string
+		filter        news.Filter
+		expectedCount int
+	}{
+		{
+			name:          "count_matching_records_regardless_of_page",
+			filter:        news.Filter{Tag: "marvel", Limit: 1, Offset: 1},
+			expectedCount: 2,
+		},
+		{
+			name:          "filter_by_tag",
+			filter:        news.Filter{Tag: "test-1"},
+			expectedCount: 1,
+		},
+		{
+			name:   "count_no_deleted_records",
+			filter: news.Filter{Author: "Spiderman"},
+		},
+		{
+			name:   "empty_for_no_records",
+			filter: news.Filter{Author: "Joker"},
+		},
+	}
+
+	all, err := s.Stats(context.Background(), news.Filter{})
+	require.NoError(t, err)
+	// the deletion of Spiderman is the last write, made at an unknown time
+	assert.False(t, all.LastModified.Before(created["Spiderman"].UpdatedAt), "last_modified: expected from %s, got %s", created["Spiderman"].UpdatedAt, all.LastModified)
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.Stats(context.Background(), tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCount, got.Count)
+			// every filter shares the time of the last write
+			assertTime(t, all.LastModified, got.LastModified, "last_modified")
+		})
+	}
+
+	t.Run("move_forward_when_a_record_leaves_the_filter", func(t *testing.T) {
+		filter := news.Filter{Tag: "test-1"}
+		before, err := s.Stats(context.Background(), filter)
+		require.NoError(t, err)
+		batman, err := s.FindById(context.Background(), created["Batman"].Id)
+		require.NoError(t, err)
+		batman.Tags = []string{"dc"}
+		require.NoError(t, s.UpdateById(context.Background(), batman.Id, batman))
+
+		got, err := s.Stats(context.Background(), filter)
+		require.NoError(t, err)
+		assert.Zero(t, got.Count)
+		assert.True(t, got.LastModified.After(before.LastModified), "last_modified: expected after %s, got %s", before.LastModified, got.LastModified)
+	})
+
+	t.Run("zero_for_an_empty_store", func(t *testing.T) {
+		got, err := newStore(t).Stats(context.Background(), news.Filter{})
+		require.NoError(t, err)
+		assert.Zero(t, got.Count)
+		assert.True(t, got.LastModified.IsZero())
+	})
+}
+
+func testUpdateById(t *testing.T, newStore Factory) {
+	testcases := []struct {
+		name           string
+		author         string
+		update         func(news.Record) news.Record
+		expectedErr    string
+		expectedStatus int
+	}{
+		{
+			name:   "return_valid_news",
+			author: "Batman",
+			update: func(r news.Record) news.Record {
+				r.Title = "Batman NEWS"
+				r.Tags = []string{"dc"}
+				r.Editor = "Alfred"
+				return r
+			},
+		},
+		{
+			name:   "never_update_owner",
+			author: "Batman",
+			update: func(r news.Record) news.Record {
+				r.Owner = "joker"
+				return r
+			},
+		},
+		{
+			name:           "return_not_found_error",
+			update:         func(r news.Record) news.Record { return r },
+			expectedErr:    "record not found",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "return_not_found_for_deleted_record",
+			author:         "Spiderman",
+			update:         func(r news.Record) news.Record { return r },
+			expectedErr:    "record not found",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "missing_title",
+			author: "Batman",
+			update: func(r news.Record) news.Record {
+				r.Title = ""
+				return r
+			},
+			expectedErr:    notNull,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newStore(t)
+			created := seed(t, s)
+			original, ok := created[tc.author]
+			if !ok {
+				original = Record("Nobody", epoch, "marvel")
+				original.Id = uuid.New()
+			}
+			updated := tc.update(original)
+			err := s.UpdateById(context.Background(), original.Id, updated)
+			if tc.expectedErr != "" {
+				assertErr(t, tc.expectedErr, err)
+				assertStatus(t, tc.expectedStatus, err)
+				return
+			}
+			require.NoError(t, err)
+			got, err := s.FindById(context.Background(), original.Id)
+			require.NoError(t, err)
+			assert.True(t, got.UpdatedAt.After(original.UpdatedAt), "updated_at is bumped")
+			updated.UpdatedAt = got.UpdatedAt
+			updated.Owner = original.Owner
+			assertRecord(t, updated, got)
+		})
+	}
+}
+
+func testUpdateByIdIfUnchanged(t *testing.T, newStore Factory) {
+	s := newStore(t)
+	created := seed(t, s)
+	ctx := context.Background()
+
+	t.Run("update_the_version_read", func(t *testing.T) {
+		found, err := s.FindById(ctx, created["Batman"].Id)
+		require.NoError(t, err)
+		found.Title = "Batman NEWS"
+		require.NoError(t, s.UpdateByIdIfUnchanged(ctx, found.Id, found.UpdatedAt, found))
+		got, err := s.FindById(ctx, found.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "Batman NEWS", got.Title)
+	})
+
+	t.Run("refuse_a_version_updated_since", func(t *testing.T) {
+		read, err := s.FindById(ctx, created["Superman"].Id)
+		require.NoError(t, err)
+		concurrent := read
+		concurrent.Title = "Superman NEWS"
+		require.NoError(t, s.UpdateById(ctx, read.Id, concurrent))
+
+		read.Summary = "lost update"
+		err = s.UpdateByIdIfUnchanged(ctx, read.Id, read.UpdatedAt, read)
+		assert.ErrorIs(t, err, news.ErrChanged)
+		assertStatus(t, http.StatusPreconditionFailed, err)
+		got, err := s.FindById(ctx, read.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "Superman NEWS", got.Title)
+		assert.NotEqual(t, "lost update", got.Summary)
+	})
+
+	t.Run("return_not_found_for_deleted_record", func(t *testing.T) {
+		spiderman := created["Spiderman"]
+		err := s.UpdateByIdIfUnchanged(ctx, spiderman.Id, spiderman.UpdatedAt, spiderman)
+		assertStatus(t, http.StatusNotFound, err)
+	})
+
+	t.Run("return_not_found_error", func(t *testing.T) {
+		nobody := Record("Nobody", epoch, "marvel")
+		err := s.UpdateByIdIfUnchanged(ctx, uuid.New(), epoch, nobody)
+		assertStatus(t, http.StatusNotFound, err)
+	})
+}
+
+func testDeleteById(t *testing.T, newStore Factory) {
+	s := newStore(t)
+	created := seed(t, s)
+	batman := created["Batman"]
+
+	require.NoError(t, s.DeleteById(context.Background(), batman.Id))
+
+	t.Run("hide_from_find_by_id", func(t *testing.T) {
+		_, err := s.FindById(context.Background(), batman.Id)
+		assertStatus(t, http.StatusNotFound, err)
+	})
+	t.Run("hide_from_find_all", func(t *testing.T) {
+		got, err := s.FindAll(context.Background(), news.Filter{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Superman"}, authors(got))
+	})
+	t.Run("hide_from_stream", func(t *testing.T) {
+		var got []news.Record
+		for record, err := range s.Stream(context.Background(), news.Filter{Tag: "test-1"}) {
+			require.NoError(t, err)
+			got = append(got, record)
+		}
+		assert.Empty(t, got)
+	})
+	t.Run("hide_from_stats_count", func(t *testing.T) {
+		got, err := s.Stats(context.Background(), news.Filter{Author: "Batman"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.Count)
+		assert.False(t, got.LastModified.Before(batman.UpdatedAt), "the deletion is the last modification")
+	})
+	t.Run("refuse_update", func(t *testing.T) {
+		err := s.UpdateById(context.Background(), batman.Id, batman)
+		assertStatus(t, http.StatusNotFound, err)
+	})
+	t.Run("ignore_deleted_record", func(t *testing.T) {
+		assert.NoError(t, s.DeleteById(context.Background(), batman.Id))
+	})
+	t.Run("ignore_missing_record", func(t *testing.T) {
+		assert.NoError(t, s.DeleteById(context.Background(), uuid.New()))
+	})
+}
+
+func testConcurrency(t *testing.T, newStore Factory) {
+	const writers = 20
+	s := newStore(t)
+	ctx := context.Background()
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = map[uuid.UUID]bool{}
+	)
+	for i := range writers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			created, err := s.Create(ctx, Record(fmt.Sprintf("Author %d", i), epoch.Add(time.Duration(i)*time.Minute), "marvel"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			ids[created.Id] = true
+			mu.Unlock()
+			created.Title = "Updated NEWS"
+			assert.NoError(t, s.UpdateById(ctx, created.Id, created))
+			if i%2 == 0 {
+				assert.NoError(t, s.DeleteById(ctx, created.Id))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := s.FindAll(ctx, news.Filter{Tag: "marvel"})
+			assert.NoError(t, err)
+			for _, err := range s.Stream(ctx, news.Filter{Order: news.NewestFirst}) {
+				assert.NoError(t, err)
+			}
+			_, err = s.Stats(ctx, news.Filter{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, ids, writers, "every created record has its own id")
+	stats, err := s.Stats(ctx, news.Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, writers/2, stats.Count)
+	got, err := s.FindAll(ctx, news.Filter{})
+	require.NoError(t, err)
+	require.Len(t, got, writers/2)
+	for i, record := range got {
+		assert.Equal(t, fmt.Sprintf("Author %d", 2*i+1), record.Author)
+		assert.Equal(t, "Updated NEWS", record.Title)
+	}
+}
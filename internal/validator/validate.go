@@ -4,11 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/logeshwarann-dev/news-api-rest/internal/model"
 	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
 )
 
 func ValidateNewsRequest(newsRecord model.NewsRecord) (result news.Record, errs error) {
@@ -58,3 +61,76 @@ func ValidateNewsId(id string) (newsId uuid.UUID, err error) {
 	}
 	return newsId, nil
 }
+
+// maxLimit caps the page size clients may request
+const maxLimit = 1000
+
+// ValidateFilter reads the tag, author, q, limit, offset and sort query parameters
+func ValidateFilter(query url.Values) (filter news.Filter, errs error) {
+	filter.Tag = query.Get("tag")
+	filter.Author = query.Get("author")
+	filter.Query = query.Get("q")
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLimit {
+			errs = errors.Join(errs, fmt.Errorf("limit must be between 1 and %d: %s", maxLimit, v))
+		}
+		filter.Limit = limit
+	}
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			errs = errors.Join(errs, fmt.Errorf("offset must be a non negative integer: %s", v))
+		}
+		filter.Offset = offset
+	}
+	switch v := query.Get("sort"); v {
+	case "", "oldest":
+		filter.Order = news.OldestFirst
+	case "newest":
+		filter.Order = news.NewestFirst
+	default:
+		errs = errors.Join(errs, fmt.Errorf("sort must be oldest or newest: %s", v))
+	}
+	return filter, errs
+}
+
+// minSecretLength keeps webhook secrets hard to guess
+const minSecretLength = 16
+
+func ValidateWebhookRequest(req model.WebhookRequest) (result webhook.Subscription, errs error) {
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = errors.Join(errs, fmt.Errorf("url must be an absolute http(s) url: %s", req.URL))
+	}
+	if len(req.Events) == 0 {
+		errs = errors.Join(errs, fmt.Errorf("events are empty"))
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(webhook.Events, event) {
+			errs = errors.Join(errs, fmt.Errorf("unknown event: %s", event))
+		}
+	}
+	if len(req.Secret) < minSecretLength {
+		errs = errors.Join(errs, fmt.Errorf("secret must be at least %d characters", minSecretLength))
+	}
+	result = webhook.Subscription{
+		URL:    req.URL,
+		Events: slices.Compact(slices.Sorted(slices.Values(req.Events))),
+		Tag:    req.Tag,
+		Secret: req.Secret,
+	}
+	return result, errs
+}
+
+// ValidateDeliveryLimit reads the limit query parameter of the delivery log
+func ValidateDeliveryLimit(query url.Values) (limit int, err error) {
+	v := query.Get("limit")
+	if v == "" {
+		return 100, nil
+	}
+	limit, err = strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d: %s", maxLimit, v)
+	}
+	return limit, nil
+}
@@ -1,12 +1,14 @@
 package validator_test
 
 import (
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/logeshwarann-dev/news-api-rest/internal/model"
 	"github.com/logeshwarann-dev/news-api-rest/internal/news"
 	"github.com/logeshwarann-dev/news-api-rest/internal/validator"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -151,3 +153,104 @@ func Test_ValidateNewNewsRequest(t *testing.T) {
 		})
 	}
 }
+
+func Test_ValidateFilter(t *testing.T) {
+	testcases := []struct {
+		name           string
+		query          url.Values
+		expectedFilter news.Filter
+		expectedErr    []string
+	}{
+		{
+			name:           "match_everything_by_default",
+			query:          url.Values{},
+			expectedFilter: news.Filter{},
+		},
+		{
+			name:           "read_every_parameter",
+			query:          url.Values{"tag": {"go"}, "author": {"Batman"}, "q": {"gotham"}, "limit": {"10"}, "offset": {"20"}, "sort": {"newest"}},
+			expectedFilter: news.Filter{Tag: "go", Author: "Batman", Query: "gotham", Limit: 10, Offset: 20, Order: news.NewestFirst},
+		},
+		{
+			name:  "return_every_error",
+			query: url.Values{"limit": {"0"}, "offset": {"-1"}, "sort": {"popular"}},
+			expectedErr: []string{
+				"limit must be between 1 and 1000: 0",
+				"offset must be a non negative integer: -1",
+				"sort must be oldest or newest: popular",
+			},
+		},
+		{
+			name:        "return_error_for_large_limit",
+			query:       url.Values{"limit": {"5000"}},
+			expectedErr: []string{"limit must be between 1 and 1000"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := validator.ValidateFilter(tc.query)
+			if len(tc.expectedErr) != 0 {
+				for _, msg := range tc.expectedErr {
+					assert.ErrorContains(t, err, msg)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedFilter, filter)
+		})
+	}
+}
+
+func Test_ValidateWebhookRequest(t *testing.T) {
+	testcases := []struct {
+		name        string
+		request     model.WebhookRequest
+		expectedSub webhook.Subscription
+		expectedErr []string
+	}{
+		{
+			name: "valid_request",
+			request: model.WebhookRequest{
+				URL:    "https://partner.example.com/hooks",
+				Events: []string{"news.updated", "news.created", "news.updated"},
+				Tag:    "go",
+				Secret: "0123456789abcdef",
+			},
+			expectedSub: webhook.Subscription{
+				URL:    "https://partner.example.com/hooks",
+				Events: []string{"news.created", "news.updated"},
+				Tag:    "go",
+				Secret: "0123456789abcdef",
+			},
+		},
+		{
+			name:    "return_every_error",
+			request: model.WebhookRequest{URL: "partner.example.com/hooks", Events: []string{"news.read"}, Secret: "short"},
+			expectedErr: []string{
+				"url must be an absolute http(s) url: partner.example.com/hooks",
+				"unknown event: news.read",
+				"secret must be at least 16 characters",
+			},
+		},
+		{
+			name:        "return_error_for_empty_events",
+			request:     model.WebhookRequest{URL: "https://partner.example.com/hooks", Secret: "0123456789abcdef"},
+			expectedErr: []string{"events are empty"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, err := validator.ValidateWebhookRequest(tc.request)
+			if len(tc.expectedErr) != 0 {
+				for _, msg := range tc.expectedErr {
+					assert.ErrorContains(t, err, msg)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedSub, sub)
+		})
+	}
+}
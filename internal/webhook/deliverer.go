@@ -0,0 +1,335 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/logger"
+	"github.com/uptrace/bun"
+	"golang.org/x/sync/errgroup"
+)
+
+var errPrivateAddress = errors.New("webhook url resolves to a private address")
+
+// NewClient returns the http client posting the deliveries. Redirects are not
+// followed and, unless allowPrivate, loopback, private and link-local
+// addresses are refused so that subscriptions cannot reach internal services.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		// a proxy would connect on our behalf, past the check
+		transport.Proxy = nil
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			ip := addrPort.Addr().Unmap()
+			if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Deliverer posts the pending deliveries to their subscriptions. A failed
+// delivery is retried with exponential backoff until it ran out of attempts,
+// and a subscription failing too many deliveries in a row is disabled.
+type Deliverer struct {
+	db           bun.IDB
+	client       *http.Client
+	interval     time.Duration
+	batchSize    int
+	concurrency  int
+	maxAttempts  int
+	disableAfter int
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	retention    time.Duration
+	now          func() time.Time
+}
+
+// Option configures the Deliverer
+type Option func(*Deliverer)
+
+// WithClient sets the http client posting the deliveries
+func WithClient(client *http.Client) Option {
+	return func(d *Deliverer) {
+		d.client = client
+	}
+}
+
+// WithInterval sets how often due deliveries are looked for
+func WithInterval(interval time.Duration) Option {
+	return func(d *Deliverer) {
+		d.interval = interval
+	}
+}
+
+// WithMaxAttempts sets how many times a delivery is tried before it fails
+func WithMaxAttempts(attempts int) Option {
+	return func(d *Deliverer) {
+		d.maxAttempts = attempts
+	}
+}
+
+// WithDisableAfter sets how many failures in a row disable a subscription
+func WithDisableAfter(failures int) Option {
+	return func(d *Deliverer) {
+		d.disableAfter = failures
+	}
+}
+
+// WithBackoff sets the wait before the first retry, doubled on every
+// further one up to max
+func WithBackoff(backoff, max time.Duration) Option {
+	return func(d *Deliverer) {
+		d.backoff = backoff
+		d.maxBackoff = max
+	}
+}
+
+// WithRetention sets how long deliveries are kept in the log, 0 keeps them forever
+func WithRetention(retention time.Duration) Option {
+	return func(d *Deliverer) {
+		d.retention = retention
+	}
+}
+
+// WithClock replaces time.Now, for tests
+func WithClock(now func() time.Time) Option {
+	return func(d *Deliverer) {
+		d.now = now
+	}
+}
+
+func NewDeliverer(db bun.IDB, opts ...Option) *Deliverer {
+	d := &Deliverer{
+		db:           db,
+		client:       NewClient(10*time.Second, false),
+		interval:     time.Second,
+		batchSize:    100,
+		concurrency:  8,
+		maxAttempts:  10,
+		disableAfter: 20,
+		backoff:      5 * time.Second,
+		maxBackoff:   time.Hour,
+		retention:    7 * 24 * time.Hour,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Run delivers until ctx is done
+func (d *Deliverer) Run(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	var pruned time.Time
+	for {
+		wait := d.interval
+		n, err := d.Flush(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			log.Warn("delivering webhooks failed", "error", err)
+		case n == d.batchSize:
+			wait = 0
+		}
+		if d.retention > 0 && d.now().Sub(pruned) >= time.Hour {
+			if err := d.Prune(ctx); err != nil {
+				log.Warn("pruning webhook deliveries failed", "error", err)
+			}
+			pruned = d.now()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Flush tries the due deliveries of enabled subscriptions, up to a batch,
+// and returns how many were tried
+func (d *Deliverer) Flush(ctx context.Context) (int, error) {
+	due, err := d.claim(ctx)
+	if err != nil || len(due) == 0 {
+		return 0, err
+	}
+	ids := make([]uuid.UUID, 0, len(due))
+	for _, delivery := range due {
+		ids = append(ids, delivery.SubscriptionId)
+	}
+	var subs []Subscription
+	if err := d.db.NewSelect().Model(&subs).Where("subscription.id IN (?)", bun.In(ids)).Scan(ctx); err != nil {
+		return 0, fmt.Errorf("find webhook subscriptions: %w", err)
+	}
+	byId := make(map[uuid.UUID]Subscription, len(subs))
+	for _, sub := range subs {
+		byId[sub.Id] = sub
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(d.concurrency)
+	for _, delivery := range due {
+		sub, ok := byId[delivery.SubscriptionId]
+		if !ok {
+			// unsubscribed since the claim
+			continue
+		}
+		g.Go(func() error {
+			status, err := d.post(ctx, sub, delivery)
+			return d.record(ctx, sub, delivery, status, err)
+		})
+	}
+	return len(due), g.Wait()
+}
+
+// claim picks the due deliveries and postpones them by the client timeout
+// plus a margin, so that another api-server does not try them meanwhile and
+// they are tried again if this one stops before recording the outcome
+func (d *Deliverer) claim(ctx context.Context) (due []Delivery, err error) {
+	now := d.now().UTC()
+	ids := d.db.NewSelect().Model((*Delivery)(nil)).Column("delivery.id").
+		Join("JOIN webhook_subscriptions AS subscription ON subscription.id = delivery.subscription_id").
+		Where("delivery.status = ?", StatusPending).
+		Where("delivery.next_attempt_at <= ?", now).
+		Where("subscription.disabled_at IS NULL").
+		OrderExpr("delivery.id").
+		Limit(d.batchSize)
+	err = d.db.NewUpdate().Model((*Delivery)(nil)).
+		Set("next_attempt_at = ?", now.Add(d.client.Timeout+time.Minute)).
+		Where("id IN (?)", ids).
+		Where("status = ?", StatusPending).
+		Where("next_attempt_at <= ?", now).
+		Returning("*").
+		Scan(ctx, &due)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	return due, nil
+}
+
+// post sends the delivery signed with the secret of the subscription
+func (d *Deliverer) post(ctx context.Context, sub Subscription, delivery Delivery) (status int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderId, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	now := d.now()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, now, delivery.Payload))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// record stores the outcome of an attempt on the delivery and its subscription
+func (d *Deliverer) record(ctx context.Context, sub Subscription, delivery Delivery, status int, postErr error) error {
+	now := d.now().UTC()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	// finished deliveries keep the time of their last attempt
+	delivery.NextAttemptAt = now
+	if postErr == nil {
+		delivery.Status = StatusDelivered
+		delivery.DeliveredAt = now
+		delivery.LastError = ""
+		if _, err := d.db.NewUpdate().Model((*Subscription)(nil)).Set("failures = 0").Where("id = ?", sub.Id).Exec(ctx); err != nil {
+			return fmt.Errorf("reset failures of webhook subscription %s: %w", sub.Id, err)
+		}
+	} else {
+		delivery.LastError = postErr.Error()
+		if delivery.Attempts >= d.maxAttempts {
+			delivery.Status = StatusFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(min(d.backoff<<min(delivery.Attempts-1, 30), d.maxBackoff))
+		}
+		if err := d.fail(ctx, sub); err != nil {
+			return err
+		}
+	}
+	_, err := d.db.NewUpdate().Model(&delivery).
+		Column("status", "attempts", "next_attempt_at", "response_status", "last_error", "delivered_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("record webhook delivery %d: %w", delivery.Id, err)
+	}
+	return nil
+}
+
+// fail counts a failed delivery of the subscription and disables it after too many in a row
+func (d *Deliverer) fail(ctx context.Context, sub Subscription) error {
+	var failures int
+	err := d.db.NewUpdate().Model((*Subscription)(nil)).
+		Set("failures = failures + 1").
+		Where("id = ?", sub.Id).
+		Returning("failures").
+		Scan(ctx, &failures)
+	if err != nil {
+		return fmt.Errorf("count failures of webhook subscription %s: %w", sub.Id, err)
+	}
+	if failures < d.disableAfter {
+		return nil
+	}
+	r, err := d.db.NewUpdate().Model((*Subscription)(nil)).
+		Set("disabled_at = ?", d.now().UTC()).
+		Where("id = ?", sub.Id).
+		Where("disabled_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("disable webhook subscription %s: %w", sub.Id, err)
+	}
+	if rows, _ := r.RowsAffected(); rows != 0 {
+		logger.FromContext(ctx).Warn("webhook subscription disabled after failing deliveries", "subscription", sub.Id, "failures", failures)
+	}
+	return nil
+}
+
+// Prune deletes the deliveries created longer than the retention ago
+func (d *Deliverer) Prune(ctx context.Context) error {
+	_, err := d.db.NewDelete().Model((*Delivery)(nil)).
+		Where("created_at < ?", d.now().Add(-d.retention).UTC()).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("delete webhook deliveries: %w", err)
+	}
+	return nil
+}
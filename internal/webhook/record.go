@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/uptrace/bun"
+)
+
+// Events lists the event types a subscription may ask for
+var Events = []string{outbox.NewsCreated, outbox.NewsUpdated, outbox.NewsDeleted}
+
+// Subscription asks for the events of some types, optionally only those of
+// news with a tag, to be posted to a url
+type Subscription struct {
+	bun.BaseModel `bun:"table:webhook_subscriptions,alias:subscription"`
+	Id            uuid.UUID `json:"id" bun:"id,pk,type:uuid"`
+	// Owner is the subject of the principal who subscribed
+	Owner  string   `json:"owner,omitempty" bun:"owner,notnull"`
+	URL    string   `json:"url" bun:"url,nullzero,notnull"`
+	Events []string `json:"events" bun:"events,nullzero,notnull,array"`
+	Tag    string   `json:"tag,omitempty" bun:"tag,nullzero"`
+	// Secret signs the deliveries, it is never sent back
+	Secret string `json:"-" bun:"secret,nullzero,notnull"`
+	// Failures counts the deliveries failed in a row, the subscription is
+	// disabled when they reach the limit of the Deliverer
+	Failures   int       `json:"failures" bun:"failures,notnull"`
+	CreatedAt  time.Time `json:"created_at" bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	DisabledAt time.Time `json:"disabled_at" bun:"disabled_at,nullzero"`
+}
+
+// Statuses of a delivery
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Delivery is an event to post to a subscription, pending until it was
+// delivered or failed every attempt
+type Delivery struct {
+	bun.BaseModel  `bun:"table:webhook_deliveries,alias:delivery"`
+	Id             int64           `json:"id" bun:"id,pk,autoincrement"`
+	SubscriptionId uuid.UUID       `json:"subscription_id" bun:"subscription_id,type:uuid,notnull"`
+	EventId        int64           `json:"event_id" bun:"event_id,notnull"`
+	EventType      string          `json:"event_type" bun:"event_type,notnull"`
+	Payload        json.RawMessage `json:"-" bun:"payload,type:jsonb,notnull"`
+	Status         string          `json:"status" bun:"status,notnull"`
+	Attempts       int             `json:"attempts" bun:"attempts,notnull"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" bun:"next_attempt_at,notnull"`
+	ResponseStatus int             `json:"response_status,omitempty" bun:"response_status,nullzero"`
+	LastError      string          `json:"last_error,omitempty" bun:"last_error,nullzero"`
+	CreatedAt      time.Time       `json:"created_at" bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	DeliveredAt    time.Time       `json:"delivered_at" bun:"delivered_at,nullzero"`
+}
@@ -0,0 +1,53 @@
+package webhook_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	sent := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":1,"type":"news.created"}`)
+	signed := func(secret string, at time.Time) http.Header {
+		h := http.Header{}
+		h.Set(webhook.HeaderTimestamp, "1704099600")
+		h.Set(webhook.HeaderSignature, webhook.Sign(secret, at, body))
+		return h
+	}
+
+	testcases := []struct {
+		name      string
+		header    http.Header
+		body      []byte
+		now       time.Time
+		expectErr bool
+	}{
+		{name: "accept_valid_signature", header: signed("0123456789abcdef", sent), body: body, now: sent.Add(time.Minute)},
+		{name: "reject_other_secret", header: signed("fedcba9876543210", sent), body: body, now: sent, expectErr: true},
+		{name: "reject_altered_body", header: signed("0123456789abcdef", sent), body: []byte(`{"id":2}`), now: sent, expectErr: true},
+		{name: "reject_signature_of_other_time", header: signed("0123456789abcdef", sent.Add(time.Second)), body: body, now: sent, expectErr: true},
+		{name: "reject_stale_delivery", header: signed("0123456789abcdef", sent), body: body, now: sent.Add(10 * time.Minute), expectErr: true},
+		{name: "reject_missing_headers", header: http.Header{}, body: body, now: sent, expectErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := webhook.Verify("0123456789abcdef", tc.header, tc.body, 5*time.Minute, tc.now)
+			if tc.expectErr {
+				assert.ErrorIs(t, err, webhook.ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1704099600.{}' | openssl dgst -sha256 -hmac 0123456789abcdef
+	assert.Equal(t, "sha256=537e5ac90b007d2afa1ef7c98661f7ef39fd0d138c957142418c0161c9655dee",
+		webhook.Sign("0123456789abcdef", time.Unix(1704099600, 0), []byte("{}")))
+}
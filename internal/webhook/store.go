@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/uptrace/bun"
+)
+
+var ErrNotFound = errors.New("webhook subscription not found")
+
+type Store struct {
+	db bun.IDB
+}
+
+func NewStore(db bun.IDB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// owned restricts q to the subscriptions of owner, an empty owner sees them all
+func owned(q *bun.SelectQuery, owner string) *bun.SelectQuery {
+	if owner == "" {
+		return q
+	}
+	return q.Where("subscription.owner = ?", owner)
+}
+
+// subscribe, the id is generated here
+func (s Store) Subscribe(ctx context.Context, sub Subscription) (Subscription, error) {
+	sub.Id = uuid.New()
+	sub.Failures = 0
+	sub.DisabledAt = time.Time{}
+	if err := s.db.NewInsert().Model(&sub).Returning("*").Scan(ctx, &sub); err != nil {
+		return sub, fmt.Errorf("insert webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// list the subscriptions of owner in creation order, disabled ones included
+func (s Store) Subscriptions(ctx context.Context, owner string) (subs []Subscription, err error) {
+	q := s.db.NewSelect().Model(&subs).OrderExpr("subscription.created_at, subscription.id")
+	if err := owned(q, owner).Scan(ctx); err != nil {
+		return subs, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// get a subscription of owner by id
+func (s Store) Subscription(ctx context.Context, id uuid.UUID, owner string) (sub Subscription, err error) {
+	q := s.db.NewSelect().Model(&sub).Where("subscription.id = ?", id)
+	if err := owned(q, owner).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sub, ErrNotFound
+		}
+		return sub, fmt.Errorf("find webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// unsubscribe, the deliveries of the subscription are deleted with it
+func (s Store) Unsubscribe(ctx context.Context, id uuid.UUID, owner string) error {
+	q := s.db.NewDelete().Model((*Subscription)(nil)).Where("id = ?", id)
+	if owner != "" {
+		q = q.Where("owner = ?", owner)
+	}
+	r, err := q.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	rows, err := r.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// list the latest deliveries of a subscription of owner, newest first
+func (s Store) Deliveries(ctx context.Context, id uuid.UUID, owner string, limit int) (deliveries []Delivery, err error) {
+	if _, err := s.Subscription(ctx, id, owner); err != nil {
+		return nil, err
+	}
+	err = s.db.NewSelect().Model(&deliveries).
+		Where("subscription_id = ?", id).
+		OrderExpr("id DESC").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return deliveries, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Publish queues a delivery of the event for every enabled subscription
+// matching it, it is the outbox.Sink feeding the Deliverer. Publishing an
+// event again queues nothing new.
+func (s Store) Publish(ctx context.Context, event outbox.Event) error {
+	var subs []Subscription
+	if err := s.db.NewSelect().Model(&subs).Where("subscription.disabled_at IS NULL").Scan(ctx); err != nil {
+		return fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	var record struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.Unmarshal(event.Payload, &record); err != nil {
+		return fmt.Errorf("decode payload of event %d: %w", event.Id, err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event %d: %w", event.Id, err)
+	}
+
+	var deliveries []Delivery
+	for _, sub := range subs {
+		if !slices.Contains(sub.Events, event.Type) || (sub.Tag != "" && !slices.Contains(record.Tags, sub.Tag)) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			SubscriptionId: sub.Id,
+			EventId:        event.Id,
+			EventType:      event.Type,
+			Payload:        body,
+			Status:         StatusPending,
+			NextAttemptAt:  time.Now().UTC(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	_, err = s.db.NewInsert().Model(&deliveries).On("CONFLICT (subscription_id, event_id) DO NOTHING").Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("queue deliveries of event %d: %w", event.Id, err)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/logeshwarann-dev/news-api-rest/internal/db"
+	"github.com/logeshwarann-dev/news-api-rest/internal/migration"
+	"github.com/logeshwarann-dev/news-api-rest/internal/news"
+	"github.com/logeshwarann-dev/news-api-rest/internal/outbox"
+	"github.com/logeshwarann-dev/news-api-rest/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/migrate"
+)
+
+const secret = "0123456789abcdef"
+
+// setupDB opens a migrated sqlite database in memory
+func setupDB(tb testing.TB) *bun.DB {
+	tb.Helper()
+	ctx := context.Background()
+	sqlite, err := db.NewDB(&db.Config{Dialect: db.SQLite, Path: ":memory:"})
+	require.NoError(tb, err)
+	tb.Cleanup(func() { sqlite.Close() })
+
+	m := migrate.NewMigrator(sqlite, migration.New(dialect.SQLite), migrate.WithMarkAppliedOnSuccess(true))
+	require.NoError(tb, m.Init(ctx))
+	_, err = m.Migrate(ctx)
+	require.NoError(tb, err)
+	return sqlite
+}
+
+// event returns an outbox event of a news with the tags
+func event(tb testing.TB, id int64, typ string, tags ...string) outbox.Event {
+	tb.Helper()
+	record := news.Record{Id: uuid.New(), Author: "Batman", Tags: tags}
+	payload, err := json.Marshal(record)
+	require.NoError(tb, err)
+	return outbox.Event{Id: id, Type: typ, RecordId: record.Id, Payload: payload, OccurredAt: time.Now().UTC()}
+}
+
+func subscribe(tb testing.TB, s *webhook.Store, sub webhook.Subscription) webhook.Subscription {
+	tb.Helper()
+	if sub.Secret == "" {
+		sub.Secret = secret
+	}
+	if sub.URL == "" {
+		sub.URL = "https://partner.example.com/hooks"
+	}
+	created, err := s.Subscribe(context.Background(), sub)
+	require.NoError(tb, err)
+	return created
+}
+
+// receiver is an httptest server recording the deliveries and answering with status
+type receiver struct {
+	*httptest.Server
+	m          sync.Mutex
+	status     int
+	deliveries []*http.Request
+	bodies     [][]byte
+}
+
+func newReceiver(tb testing.TB) *receiver {
+	tb.Helper()
+	rc := &receiver{status: http.StatusOK}
+	rc.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.m.Lock()
+		defer rc.m.Unlock()
+		rc.deliveries = append(rc.deliveries, r)
+		rc.bodies = append(rc.bodies, body)
+		w.WriteHeader(rc.status)
+	}))
+	tb.Cleanup(rc.Close)
+	return rc
+}
+
+func TestStore_Subscriptions(t *testing.T) {
+	ctx := context.Background()
+	s := webhook.NewStore(setupDB(t))
+	alice := subscribe(t, s, webhook.Subscription{Owner: "alice", Events: []string{outbox.NewsCreated}, Tag: "dc"})
+	bob := subscribe(t, s, webhook.Subscription{Owner: "bob", Events: []string{outbox.NewsDeleted}})
+
+	assert.Equal(t, "dc", alice.Tag)
+	assert.False(t, alice.CreatedAt.IsZero())
+	subs, err := s.Subscriptions(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, alice.Id, subs[0].Id)
+	assert.Equal(t, secret, subs[0].Secret)
+	subs, err = s.Subscriptions(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, subs, 2, "an empty owner sees every subscription")
+
+	_, err = s.Subscription(ctx, bob.Id, "alice")
+	assert.ErrorIs(t, err, webhook.ErrNotFound)
+	assert.ErrorIs(t, s.Unsubscribe(ctx, bob.Id, "alice"), webhook.ErrNotFound)
+	_, err = s.Deliveries(ctx, bob.Id, "alice", 10)
+	assert.ErrorIs(t, err, webhook.ErrNotFound)
+
+	require.NoError(t, s.Publish(ctx, event(t, 1, outbox.NewsDeleted)))
+	deliveries, err := s.Deliveries(ctx, bob.Id, "bob", 10)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	require.NoError(t, s.Unsubscribe(ctx, bob.Id, "bob"))
+	_, err = s.Subscription(ctx, bob.Id, "")
+	assert.ErrorIs(t, err, webhook.ErrNotFound)
+}
+
+func TestStore_Publish(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := webhook.NewStore(sqlite)
+	all := subscribe(t, s, webhook.Subscription{Events: webhook.Events})
+	created := subscribe(t, s, webhook.Subscription{Events: []string{outbox.NewsCreated}})
+	tagged := subscribe(t, s, webhook.Subscription{Events: []string{outbox.NewsCreated}, Tag: "dc"})
+	disabled := subscribe(t, s, webhook.Subscription{Events: webhook.Events})
+	_, err := sqlite.NewUpdate().Model((*webhook.Subscription)(nil)).Set("disabled_at = ?", time.Now().UTC()).Where("id = ?", disabled.Id).Exec(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Publish(ctx, event(t, 1, outbox.NewsCreated, "dc")))
+	require.NoError(t, s.Publish(ctx, event(t, 2, outbox.NewsCreated, "marvel")))
+	require.NoError(t, s.Publish(ctx, event(t, 3, outbox.NewsUpdated, "dc")))
+	require.NoError(t, s.Publish(ctx, event(t, 1, outbox.NewsCreated, "dc")), "events published again queue nothing")
+
+	expected := map[uuid.UUID][]int64{
+		all.Id:      {3, 2, 1},
+		created.Id:  {2, 1},
+		tagged.Id:   {1},
+		disabled.Id: nil,
+	}
+	for id, events := range expected {
+		deliveries, err := s.Deliveries(ctx, id, "", 10)
+		require.NoError(t, err)
+		var got []int64
+		for _, d := range deliveries {
+			got = append(got, d.EventId)
+			assert.Equal(t, webhook.StatusPending, d.Status)
+		}
+		assert.Equal(t, events, got)
+	}
+}
+
+func TestDeliverer_Deliver(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := webhook.NewStore(sqlite)
+	rc := newReceiver(t)
+	sub := subscribe(t, s, webhook.Subscription{URL: rc.URL + "/hooks", Events: webhook.Events})
+	e := event(t, 7, outbox.NewsCreated, "dc")
+	require.NoError(t, s.Publish(ctx, e))
+	d := webhook.NewDeliverer(sqlite, webhook.WithClient(webhook.NewClient(time.Second, true)))
+
+	n, err := d.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = d.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "delivered events are not sent again")
+
+	require.Len(t, rc.deliveries, 1)
+	req := rc.deliveries[0]
+	assert.Equal(t, "/hooks", req.URL.Path)
+	assert.Equal(t, outbox.NewsCreated, req.Header.Get(webhook.HeaderEvent))
+	assert.NotEmpty(t, req.Header.Get(webhook.HeaderId))
+	assert.NoError(t, webhook.Verify(secret, req.Header, rc.bodies[0], time.Minute, time.Now()))
+	var got outbox.Event
+	require.NoError(t, json.Unmarshal(rc.bodies[0], &got))
+	assert.Equal(t, e.Id, got.Id)
+	assert.Equal(t, e.RecordId, got.RecordId)
+
+	deliveries, err := s.Deliveries(ctx, sub.Id, "", 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, webhook.StatusDelivered, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+	assert.False(t, deliveries[0].DeliveredAt.IsZero())
+}
+
+func TestDeliverer_Retry(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := webhook.NewStore(sqlite)
+	rc := newReceiver(t)
+	rc.status = http.StatusServiceUnavailable
+	sub := subscribe(t, s, webhook.Subscription{URL: rc.URL, Events: webhook.Events})
+	require.NoError(t, s.Publish(ctx, event(t, 1, outbox.NewsCreated)))
+	now := time.Now().UTC()
+	d := webhook.NewDeliverer(sqlite,
+		webhook.WithClient(webhook.NewClient(time.Second, true)),
+		webhook.WithClock(func() time.Time { return now }),
+		webhook.WithBackoff(time.Second, 3*time.Second),
+		webhook.WithMaxAttempts(4),
+		webhook.WithDisableAfter(10),
+	)
+	delivery := func() webhook.Delivery {
+		deliveries, err := s.Deliveries(ctx, sub.Id, "", 1)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		return deliveries[0]
+	}
+
+	for i, backoff := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		n, err := d.Flush(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		got := delivery()
+		assert.Equal(t, webhook.StatusPending, got.Status)
+		assert.Equal(t, i+1, got.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, got.ResponseStatus)
+		assert.Equal(t, "answered 503 Service Unavailable", got.LastError)
+		assert.WithinDuration(t, now.Add(backoff), got.NextAttemptAt, time.Millisecond)
+
+		n, err = d.Flush(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n, "nothing is tried before the backoff")
+		now = now.Add(backoff)
+	}
+
+	_, err := d.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.StatusFailed, delivery().Status, "out of attempts")
+	now = now.Add(time.Hour)
+	n, err := d.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Len(t, rc.deliveries, 4)
+
+	got, err := s.Subscription(ctx, sub.Id, "")
+	require.NoError(t, err)
+	assert.Equal(t, 4, got.Failures)
+	assert.True(t, got.DisabledAt.IsZero())
+
+	rc.status = http.StatusNoContent
+	require.NoError(t, s.Publish(ctx, event(t, 2, outbox.NewsCreated)))
+	_, err = d.Flush(ctx)
+	require.NoError(t, err)
+	got, err = s.Subscription(ctx, sub.Id, "")
+	require.NoError(t, err)
+	assert.Zero(t, got.Failures, "a delivery resets the failures")
+}
+
+func TestDeliverer_DisableAfterFailures(t *testing.T) {
+	ctx := context.Background()
+	sqlite := setupDB(t)
+	s := webhook.NewStore(sqlite)
+	rc := newReceiver(t)
+	rc.status = http.StatusGone
+	sub := subscribe(t, s, webhook.Subscription{URL: rc.URL, Events: webhook.Events})
+	other := subscribe(t, s, webhook.Subscription{URL: rc.URL, Events: []string{outbox.NewsDeleted}})
+	for i := range 3 {
+		require.NoError(t, s.Publish(ctx, event(t, int64(i+1), outbox.NewsCreated)))
+	}
+	d := webhook.NewDeliverer(sqlite,
+		webhook.WithClient(webhook.NewClient(time.Second, true)),
+		webhook.WithDisableAfter(2),
+	)
+
+	n, err := d.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	got, err := s.Subscription(ctx, sub.Id, "")
+	require.NoError(t, err)
+	assert.False(t, got.DisabledAt.IsZero())
+	assert.Equal(t, 3, got.Failures)
+	got, err = s.Subscription(ctx, other.Id, "")
+	require.NoError(t, err)
+	assert.True(t, got.DisabledAt.IsZero())
+
+	require.NoError(t, s.Publish(ctx, event(t, 4, outbox.NewsCreated)))
+	deliveries, err := s.Deliveries(ctx, sub.Id, "", 10)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 3, "disabled subscriptions get no new deliveries")
+}
+
+func TestNewClient_RefusePrivateAddresses(t *testing.T) {
+	rc := newReceiver(t)
+
+	_, err := webhook.NewClient(time.Second, false).Get(rc.URL)
+	assert.ErrorContains(t, err, "private address")
+	assert.Empty(t, rc.deliveries)
+
+	resp, err := webhook.NewClient(time.Second, true).Get(rc.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}